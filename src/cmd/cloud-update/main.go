@@ -4,33 +4,55 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
 	"github.com/kodflow/cloud-update/src/internal/application/handler"
 	"github.com/kodflow/cloud-update/src/internal/domain/service"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/acme"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/config"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/console"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/metrics"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/ratelimit"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
 	"github.com/kodflow/cloud-update/src/internal/setup"
 	"github.com/kodflow/cloud-update/src/internal/version"
 )
 
+// certWatchInterval is how often the certificate files are checked for changes.
+const certWatchInterval = 30 * time.Second
+
 func main() {
 	var (
 		showVersion  = flag.Bool("version", false, "Show version information")
 		showHelp     = flag.Bool("help", false, "Show help")
 		runSetup     = flag.Bool("setup", false, "Install service on the system")
 		runUninstall = flag.Bool("uninstall", false, "Uninstall service from the system")
+		configPath   = flag.String("config", "", "Path to the configuration file")
+		genCert      = flag.Bool("gen-cert", false, "Generate a self-signed HTTPS certificate")
+		selfSigned   = flag.Bool("self-signed", false, "With --setup, generate a self-signed certificate and enable HTTPS")
+		certHosts    = flag.String("cert-hosts", "", "Comma-separated DNS names and IPs of the certificate (default: host name and loopback)")
+		certDays     = flag.Int("cert-days", 365, "Validity of the generated certificate in days")
+		force        = flag.Bool("force", false, "With --gen-cert, replace an existing certificate")
+		verifyAudit  = flag.Bool("verify-audit", false, "Verify the audit log hash chain (FILE argument or configured log)")
 	)
 	flag.Parse()
 
@@ -44,8 +66,25 @@ func main() {
 		os.Exit(0)
 	}
 
+	hosts := config.DefaultCertHosts()
+	if *certHosts != "" {
+		hosts = strings.Split(*certHosts, ",")
+	}
+	validity := time.Duration(*certDays) * 24 * time.Hour
+
+	if *genCert {
+		if err := generateCertificate(hosts, validity, *force); err != nil {
+			fmt.Fprintf(os.Stderr, "Certificate generation failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if *runSetup {
 		installer := setup.NewServiceInstaller()
+		if *selfSigned {
+			installer.WithSelfSignedCert(hosts, validity)
+		}
 		if err := installer.Setup(); err != nil {
 			fmt.Fprintf(os.Stderr, "Setup failed: %v\n", err)
 			os.Exit(1)
@@ -62,8 +101,20 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *verifyAudit {
+		if err := verifyAuditLog(flag.Arg(0), *configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Audit log verification failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := readConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Initialize logger
 	logCfg := logger.Config{
@@ -78,46 +129,143 @@ func main() {
 	}
 	defer logger.Close()
 
+	// Initialize tracing, spans still queued are sent once everything else stopped
+	tracingCfg := cfg.Telemetry
+	tracingCfg.Version = version.Version
+	tracing, err := telemetry.Initialize(tracingCfg)
+	if err != nil {
+		logger.Fatalf("Failed to initialize telemetry: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := tracing.Shutdown(ctx); err != nil {
+			logger.Errorf("Failed to flush telemetry: %v", err)
+		}
+	}()
+
+	// Record requests, commands and job outcomes in the audit log
+	auditLog := openAuditLog(cfg)
+	if auditLog != nil {
+		defer func() {
+			audit.SetDefault(nil)
+			if err := auditLog.Close(); err != nil {
+				logger.Errorf("Failed to close audit log: %v", err)
+			}
+		}()
+	}
+
+	// Report finished jobs to their callback URL, including jobs reconciled when
+	// the job store opens
+	notifier := openCallbacks(cfg)
+
 	// Initialize components
-	authenticator, authErr := security.NewHMACAuthenticator(cfg.Secret)
+	webhookAuthenticator, authErr := newAuthenticator(cfg)
 	if authErr != nil {
 		logger.Fatalf("Failed to initialize authenticator: %v", authErr)
 	}
+	// Reloadable so SIGHUP can rotate the secret
+	authenticator := security.NewReloadableAuthenticator(webhookAuthenticator)
 	// Initialize worker pool for async processing
-	workerPool := worker.NewPool(10, 100) // 10 workers, 100 task backlog
+	workerPool := worker.NewPoolWithTimeout(cfg.Workers.Count, cfg.Workers.QueueSize, cfg.Workers.JobTimeout)
 	defer func() {
 		if err := workerPool.Shutdown(30 * time.Second); err != nil {
 			logger.Errorf("Failed to shutdown worker pool: %v", err)
 		}
 	}()
 
-	systemExecutor := system.NewSystemExecutor()
+	systemExecutor, err := system.NewExecutor(cfg.Executor)
+	if err != nil {
+		logger.Fatalf("Failed to initialize system executor: %v", err)
+	}
 	actionService := service.NewActionService(systemExecutor)
 
 	// Initialize rate limiter
-	rateLimiter := ratelimit.NewRateLimiter(ratelimit.DefaultConfig())
+	rateLimiter := ratelimit.NewRateLimiter(ratelimit.Config{
+		RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+		Burst:             cfg.RateLimit.Burst,
+		TTL:               cfg.RateLimit.TTL,
+	})
+
+	// Initialize job store (persistent unless explicitly disabled)
+	jobStore := openJobStore(cfg)
+	defer func() {
+		if err := jobStore.Close(); err != nil {
+			logger.Errorf("Failed to close job store: %v", err)
+		}
+	}()
+	// Stop delivering callbacks before the job store recording their attempts closes
+	if notifier != nil {
+		defer func() {
+			callback.SetDefault(nil)
+			notifier.Close()
+		}()
+	}
+
+	// Initialize replay protection, persisted next to the job store
+	nonces := openNonceCache(cfg)
+	defer func() {
+		if err := nonces.Close(); err != nil {
+			logger.Errorf("Failed to close nonce cache: %v", err)
+		}
+	}()
 
 	// Initialize handlers with worker pool support
 	healthHandler := handler.NewHealthHandler()
-	webhookHandler := handler.NewWebhookHandlerWithPool(actionService, authenticator, workerPool)
+	registerReadinessChecks(healthHandler, cfg, workerPool, jobStore, systemExecutor)
+	webhookHandler := handler.NewWebhookHandlerWithOptions(actionService, authenticator, workerPool,
+		handler.WebhookHandlerOptions{
+			JobStore:       jobStore,
+			JobLogs:        openJobLogs(cfg),
+			JobRetention:   cfg.JobRetention,
+			AllowedActions: cfg.AllowedActions,
+			NonceCache:     nonces,
+			RequireNonce:   cfg.RequireNonce,
+			QueueSize:      cfg.JobQueue,
+			Maintenance:    cfg.Maintenance,
+		})
 
 	// Start cleanup goroutine for old jobs
 	go webhookHandler.Cleanup()
+	// Start scheduled jobs once their run time is reached
+	go webhookHandler.RunScheduled()
+
+	// Setup HTTP routes with rate limiting on signed endpoints, all traced and counted
+	handle := func(pattern string, h http.HandlerFunc) {
+		traced := otelhttp.NewHandler(h, pattern, otelhttp.WithSpanNameFormatter(
+			func(_ string, r *http.Request) string { return r.Method + " " + pattern },
+		))
+		http.HandleFunc(pattern, metrics.Instrument(pattern, traced.ServeHTTP))
+	}
+	handle("/health", healthHandler.HandleHealth)
+	handle("/livez", healthHandler.HandleLiveness)
+	handle("/readyz", healthHandler.HandleReadiness)
+	handle("/webhook", rateLimiter.MiddlewareFunc(webhookHandler.HandleWebhook))
+	handle("/job/status", rateLimiter.MiddlewareFunc(webhookHandler.HandleJobStatus))
+	handle("/job/logs", rateLimiter.MiddlewareFunc(webhookHandler.HandleJobLogs))
+	handle("/job/scheduled", rateLimiter.MiddlewareFunc(webhookHandler.HandleScheduledJobs))
+	handle("/job/cancel", rateLimiter.MiddlewareFunc(webhookHandler.HandleJobCancel))
+	handle("/actions", webhookHandler.HandleActions)
 
-	// Setup HTTP routes with rate limiting on webhook endpoint
-	http.HandleFunc("/health", healthHandler.HandleHealth)
-	http.HandleFunc("/webhook", rateLimiter.MiddlewareFunc(webhookHandler.HandleWebhook))
-	http.HandleFunc("/job/status", webhookHandler.HandleJobStatus)
+	// Expose metrics with the webhook, or on their own listener
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		registerMetrics(workerPool, rateLimiter, jobStore)
+		if cfg.Metrics.Listen == "" {
+			http.HandleFunc("/metrics", metrics.Handler())
+		} else {
+			metricsServer = startMetricsServer(cfg.Metrics.Listen)
+		}
+	}
 
-	// Load TLS configuration
-	tlsConfig := config.LoadTLSConfig()
+	// Validate TLS configuration
+	tlsConfig := cfg.TLS
 	if err := tlsConfig.Validate(); err != nil {
-		logger.Warnf("TLS configuration error: %v", err)
-		logger.Info("Starting without TLS (HTTP only)")
+		logger.Fatalf("TLS configuration error: %v", err) // never fall back to plain HTTP
 	}
 
 	// Start server with proper timeouts
-	addr := fmt.Sprintf(":%s", cfg.Port)
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
 	protocol := "HTTP"
 	if tlsConfig.Enabled {
 		protocol = "HTTPS"
@@ -125,19 +273,61 @@ func main() {
 
 	logger.Infof("Starting Cloud Update service on %s (%s)", addr, protocol)
 	logger.Infof("Version: %s", version.GetFullVersion())
+	if cfg.File != "" {
+		logger.Infof("Configuration file: %s", cfg.File)
+	}
 	logger.Infof("Log level: %s", cfg.LogLevel)
-	logger.Infof("Log file: /var/log/cloud-update/cloud-update.log")
-	logger.Infof("Rate limiting: %d req/s, burst: %d", 10, 20)
-	logger.Infof("Worker pool: 10 workers, 100 task backlog")
+	logger.Infof("Log file: %s", cfg.LogFilePath)
+	logger.Infof("Rate limiting: %d req/s, burst: %d", cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	logger.Infof("Worker pool: %d workers, %d task backlog, %v job timeout",
+		cfg.Workers.Count, cfg.Workers.QueueSize, cfg.Workers.JobTimeout)
+	logger.Infof("Executor: %s privileges, %v command timeout, %s reboots",
+		cfg.Executor.Privilege, cfg.Executor.Timeout, cfg.Executor.Reboot)
+	logger.Infof("Allowed actions: %v", cfg.AllowedActions.List())
+	if cfg.JobQueue > 0 {
+		logger.Infof("Job queue: up to %d jobs wait for the running job", cfg.JobQueue)
+	}
+	if cfg.Metrics.Enabled && cfg.Metrics.Listen != "" {
+		logger.Infof("Metrics: http://%s/metrics", cfg.Metrics.Listen)
+	} else if cfg.Metrics.Enabled {
+		logger.Info("Metrics: /metrics")
+	}
+	if cfg.Telemetry.Enabled {
+		logger.Infof("Tracing: OTLP export to %s as %s", cfg.Telemetry.OTLPEndpoint, cfg.Telemetry.ServiceName)
+	}
+	if len(cfg.Maintenance.Windows) > 0 {
+		logger.Infof("Maintenance windows: %d (%s) for %v",
+			len(cfg.Maintenance.Windows), cfg.Maintenance.Location, cfg.Maintenance.Actions.List())
+	}
 
 	// Configure TLS if enabled
 	var serverTLSConfig *tls.Config
-	if tlsConfig.Enabled && !tlsConfig.Auto {
-		var err error
-		serverTLSConfig, err = tlsConfig.GetTLSConfig()
+	var certs *config.CertReloader
+	var acmeManager *acme.Manager
+	var certExpiry config.CertificateExpiry
+	if tlsConfig.Enabled {
+		var source config.CertificateSource
+		if tlsConfig.Auto {
+			acmeManager, err = newACMEManager(tlsConfig)
+			source, certExpiry = acmeManager, acmeManager
+		} else {
+			certs, err = config.NewCertReloader(tlsConfig.CertFile, tlsConfig.KeyFile)
+			source, certExpiry = certs, certs
+		}
 		if err != nil {
 			logger.Fatalf("Failed to configure TLS: %v", err)
 		}
+		serverTLSConfig = config.ServerTLSConfig(source)
+		if err := tlsConfig.ConfigureClientAuth(serverTLSConfig); err != nil {
+			logger.Fatalf("Failed to configure TLS client authentication: %v", err)
+		}
+		if tlsConfig.ClientAuth != config.ClientAuthNone {
+			logger.Infof("TLS client certificates: %s (CA bundle %s)", tlsConfig.ClientAuth, tlsConfig.ClientCAFile)
+		}
+		if acmeManager != nil {
+			serverTLSConfig = acmeManager.TLSConfig(serverTLSConfig)
+		}
+		healthHandler.WithCertificate(certExpiry, tlsConfig.ExpiryWarning)
 	}
 
 	server := &http.Server{
@@ -151,6 +341,35 @@ func main() {
 		TLSConfig:         serverTLSConfig,
 	}
 
+	// Reload configuration on SIGHUP
+	reloader := &configReloader{
+		configPath:    *configPath,
+		current:       cfg,
+		authenticator: authenticator,
+		rateLimiter:   rateLimiter,
+		certs:         certs,
+		notifier:      notifier,
+	}
+	go reloader.watch()
+
+	// Keep certificates current: renew ACME certificates, reload replaced files and
+	// warn before they expire
+	certCtx, stopCerts := context.WithCancel(context.Background())
+	defer stopCerts()
+	var challengeServer *http.Server
+	if acmeManager != nil {
+		go acmeManager.Run(certCtx)
+		if tlsConfig.ACME.Challenge == acme.ChallengeHTTP01 {
+			challengeServer = startChallengeServer(tlsConfig.ACME.HTTPAddr, acmeManager)
+		}
+	}
+	if certs != nil {
+		go certs.Watch(certCtx, certWatchInterval)
+	}
+	if certExpiry != nil {
+		go config.MonitorExpiry(certCtx, certExpiry, tlsConfig.ExpiryWarning, 12*time.Hour)
+	}
+
 	// Graceful shutdown
 	go func() {
 		sigChan := make(chan os.Signal, 1)
@@ -158,25 +377,29 @@ func main() {
 		<-sigChan
 
 		logger.Info("Shutting down server...")
+		stopCerts()
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		if challengeServer != nil {
+			_ = challengeServer.Shutdown(ctx)
+		}
+		if metricsServer != nil {
+			_ = metricsServer.Shutdown(ctx)
+		}
 		if err := server.Shutdown(ctx); err != nil {
 			logger.Errorf("Server shutdown error: %v", err)
 		}
 	}()
 
 	// Start server
-	var err error
 	if tlsConfig.Enabled {
 		if tlsConfig.Auto {
-			logger.Info("Automatic TLS certificate management not yet implemented")
-			logger.Info("Starting with HTTP only")
-			err = server.ListenAndServe()
+			logger.Infof("Starting HTTPS server with ACME certificates for %s", tlsConfig.Domain)
 		} else {
 			logger.Infof("Starting HTTPS server with certificates from %s", tlsConfig.CertFile)
-			err = server.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
 		}
+		err = server.ListenAndServeTLS("", "") // certificates come from serverTLSConfig
 	} else {
 		err = server.ListenAndServe()
 	}
@@ -188,6 +411,336 @@ func main() {
 	logger.Info("Cloud Update service stopped")
 }
 
+// generateCertificate writes a self-signed certificate to the default TLS paths.
+func generateCertificate(hosts []string, validity time.Duration, force bool) error {
+	certPath, keyPath := setup.GetCertPaths()
+	if !force && (setup.FileExists(certPath) || setup.FileExists(keyPath)) {
+		return fmt.Errorf("%s already exists (use --force to replace it)", certPath)
+	}
+	if validity <= 0 {
+		return fmt.Errorf("--cert-days must be positive")
+	}
+	if err := config.GenerateSelfSignedCert(certPath, keyPath, hosts, validity); err != nil {
+		return err
+	}
+
+	console.Printf("Certificate: %s\n", certPath)
+	console.Printf("Private key: %s\n", keyPath)
+	console.Printf("Hosts: %s\n", strings.Join(hosts, ", "))
+	console.Printf("Valid until: %s\n", time.Now().Add(validity).Format(time.DateOnly))
+	console.Println("Enable HTTPS with tls.enabled: true (or CLOUD_UPDATE_TLS_ENABLED=true) and restart the service.")
+	return nil
+}
+
+// newACMEManager creates the manager obtaining certificates for the configured domain.
+func newACMEManager(tlsConfig *config.TLSConfig) (*acme.Manager, error) {
+	manager, err := acme.NewManager(acme.Config{
+		DirectoryURL: tlsConfig.ACME.DirectoryURL,
+		Domain:       tlsConfig.Domain,
+		Email:        tlsConfig.ACME.Email,
+		Challenge:    tlsConfig.ACME.Challenge,
+		CacheDir:     tlsConfig.ACME.CacheDir,
+		CAFile:       tlsConfig.ACME.CAFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("ACME certificates for %s from %s (%s challenge, cache %s)",
+		tlsConfig.Domain, tlsConfig.ACME.DirectoryURL, tlsConfig.ACME.Challenge, tlsConfig.ACME.CacheDir)
+	if notAfter := manager.NotAfter(); !notAfter.IsZero() {
+		logger.Infof("Cached ACME certificate valid until %s", notAfter.Format(time.RFC3339))
+	}
+	return manager, nil
+}
+
+// startChallengeServer answers http-01 challenges on addr. It serves nothing else.
+func startChallengeServer(addr string, manager *acme.Manager) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           manager.HTTPHandler(),
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      5 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Infof("Answering ACME http-01 challenges on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatalf("Failed to start ACME challenge server: %v", err)
+		}
+	}()
+	return server
+}
+
+// startMetricsServer serves /metrics in plain HTTP on addr. It serves nothing else.
+func startMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Infof("Serving metrics on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}()
+	return server
+}
+
+// registerMetrics exposes the state of the worker pool, rate limiter and scheduled jobs.
+func registerMetrics(pool *worker.Pool, rateLimiter *ratelimit.RateLimiter, jobStore store.JobStore) {
+	err := errors.Join(
+		metrics.RegisterGaugeFunc("cloud_update_worker_pool_workers", "Workers of the pool.",
+			func() float64 { return float64(pool.Workers()) }),
+		metrics.RegisterGaugeFunc("cloud_update_worker_pool_busy_workers", "Workers running a task.",
+			func() float64 { return float64(pool.Busy()) }),
+		metrics.RegisterGaugeFunc("cloud_update_worker_pool_queue_depth", "Tasks waiting for a worker.",
+			func() float64 { return float64(pool.Size()) }),
+		metrics.RegisterGaugeFunc("cloud_update_worker_pool_queue_capacity", "Tasks that can wait for a worker.",
+			func() float64 { return float64(pool.Capacity()) }),
+		metrics.RegisterCounterFunc("cloud_update_rate_limited_total", "Requests rejected by the rate limiter.",
+			func() float64 { return float64(rateLimiter.Rejected()) }),
+		metrics.RegisterGaugeFunc("cloud_update_rate_limiter_clients", "Client addresses tracked by the rate limiter.",
+			func() float64 { return float64(rateLimiter.ActiveClients()) }),
+		metrics.RegisterGaugeFunc("cloud_update_jobs_scheduled", "Jobs waiting for their run time.",
+			func() float64 { return float64(len(jobStore.ScheduledJobs())) }),
+	)
+	if err != nil {
+		logger.WithField("error", err).Warn("Some metrics could not be registered")
+	}
+}
+
+// registerReadinessChecks makes /readyz check what jobs depend on.
+func registerReadinessChecks(h *handler.HealthHandler, cfg *config.Config, pool *worker.Pool,
+	jobStore store.JobStore, executor *system.DefaultExecutor,
+) {
+	h.WithCheck("worker_pool", pool.Check).
+		WithCheck("job_store", func() error {
+			if fileStore, ok := jobStore.(*store.FileJobStore); ok {
+				return fileStore.Check()
+			}
+			if cfg.JobStorePath != "memory" {
+				return fmt.Errorf("job store %s could not be opened, job history is only kept in memory",
+					cfg.JobStorePath)
+			}
+			return nil
+		}).
+		WithCheck("log_file", logger.CheckFile).
+		WithCheck("privilege", executor.CheckPrivilege).
+		WithCheck("package_manager", executor.CheckPackageManager)
+}
+
+// readConfig reads the configuration from path, or from the default locations when path is empty.
+func readConfig(path string) (*config.Config, error) {
+	if path == "" {
+		return config.LoadDefault()
+	}
+	return config.LoadFile(path)
+}
+
+// newAuthenticator creates the webhook authenticator for the configured method, keys and secret.
+func newAuthenticator(cfg *config.Config) (security.Authenticator, error) {
+	switch cfg.Auth {
+	case config.AuthPublicKey:
+		return newPublicKeyAuthenticator(cfg)
+	case config.AuthMTLS:
+		logger.Info("Webhook authentication: TLS client certificates")
+		return security.NewClientCertAuthenticator(), nil
+	}
+
+	keys := make([]security.HMACKey, 0, len(cfg.WebhookKeys)+1)
+	if cfg.Secret != "" {
+		keys = append(keys, security.HMACKey{ID: security.DefaultKeyID, Secret: cfg.Secret})
+	}
+
+	now := time.Now()
+	for _, key := range cfg.WebhookKeys {
+		hmacKey := security.HMACKey{ID: key.ID, Secret: key.Secret, NotAfter: key.NotAfter}
+		if hmacKey.Expired(now) {
+			logger.WithField("key_id", key.ID).
+				WithField("not_after", key.NotAfter).
+				Warn("Webhook key has expired and will be rejected")
+		}
+		keys = append(keys, hmacKey)
+	}
+
+	auth, err := security.NewHMACKeyringAuthenticator(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	logger.Infof("Webhook keys: %v", ids)
+	return auth, nil
+}
+
+// newPublicKeyAuthenticator reads the trusted public keys and creates the signature authenticator.
+func newPublicKeyAuthenticator(cfg *config.Config) (security.Authenticator, error) {
+	keys := make([]security.PublicKey, 0, len(cfg.PublicKeys))
+	ids := make([]string, 0, len(cfg.PublicKeys))
+	now := time.Now()
+
+	for _, file := range cfg.PublicKeys {
+		key, err := security.LoadPublicKey(file.File)
+		if err != nil {
+			return nil, fmt.Errorf("public key %q: %w", file.ID, err)
+		}
+
+		pubKey := security.PublicKey{ID: file.ID, Key: key, NotAfter: file.NotAfter}
+		if pubKey.Expired(now) {
+			logger.WithField("key_id", file.ID).
+				WithField("not_after", file.NotAfter).
+				Warn("Webhook key has expired and will be rejected")
+		}
+		keys = append(keys, pubKey)
+		ids = append(ids, file.ID)
+	}
+
+	auth, err := security.NewPublicKeyAuthenticator(keys)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Webhook public keys: %v", ids)
+	return auth, nil
+}
+
+// openJobStore opens the persistent job store, falling back to memory on failure.
+func openJobStore(cfg *config.Config) store.JobStore {
+	if cfg.JobStorePath == "memory" {
+		logger.Info("Job store: in-memory (history is lost on restart)")
+		return store.NewJobStore()
+	}
+
+	retention := store.DefaultRetention()
+	retention.MaxAge = cfg.JobRetention
+	fileStore, err := store.NewFileJobStore(cfg.JobStorePath, retention)
+	if err != nil {
+		logger.Errorf("Failed to open job store %s, using in-memory store: %v", cfg.JobStorePath, err)
+		return store.NewJobStore()
+	}
+
+	logger.Infof("Job store: %s (retention %s)", cfg.JobStorePath, cfg.JobRetention)
+	reconcileJobs(fileStore)
+	return fileStore
+}
+
+// openJobLogs opens the job log directory, falling back to memory on failure.
+func openJobLogs(cfg *config.Config) *store.JobLogs {
+	if cfg.JobLogDir == "memory" {
+		logger.Info("Job logs: in-memory (command output is lost on restart)")
+		return store.NewMemoryJobLogs()
+	}
+
+	jobLogs, err := store.NewJobLogs(cfg.JobLogDir)
+	if err != nil {
+		logger.Errorf("Failed to open job log directory %s, using in-memory logs: %v", cfg.JobLogDir, err)
+		return store.NewMemoryJobLogs()
+	}
+
+	logger.Infof("Job logs: %s", cfg.JobLogDir)
+	return jobLogs
+}
+
+// openNonceCache opens the replay protection cache, persisted when the job store is.
+func openNonceCache(cfg *config.Config) *security.NonceCache {
+	if cfg.JobStorePath == "memory" {
+		return security.NewNonceCache(security.DefaultNonceCacheSize)
+	}
+
+	path := filepath.Join(filepath.Dir(cfg.JobStorePath), "nonces.log")
+	nonces, err := security.NewFileNonceCache(path, security.DefaultNonceCacheSize)
+	if err != nil {
+		logger.Errorf("Failed to open nonce cache %s, using in-memory cache: %v", path, err)
+		return security.NewNonceCache(security.DefaultNonceCacheSize)
+	}
+	return nonces
+}
+
+// reconcileJobs settles jobs left running when the service last stopped.
+func reconcileJobs(jobStore store.JobStore) {
+	bootTime, err := system.BootTime()
+	if err != nil {
+		logger.Warnf("Cannot determine boot time, pending reboot jobs will be marked interrupted: %v", err)
+	}
+
+	for _, job := range jobStore.Reconcile(bootTime) {
+		logger.WithField("job_id", job.ID).
+			WithField("action", job.Action).
+			WithField("status", job.GetStatus()).
+			Info("Reconciled job from previous run")
+		metrics.ObserveJob(job.Snapshot())
+		audit.Job(job.Snapshot())
+		callback.Job(job.Snapshot(), jobStore)
+	}
+}
+
+// openCallbacks creates the completion callback notifier and makes it the default
+// one. It returns nil when callbacks are disabled, without a secret to sign them.
+func openCallbacks(cfg *config.Config) *callback.Notifier {
+	if cfg.Callbacks.Secret == "" {
+		logger.Info("Job callbacks: disabled (no signing secret)")
+		return nil
+	}
+
+	notifier, err := callback.New(cfg.Callbacks)
+	if err != nil {
+		logger.Fatalf("Failed to initialize job callbacks: %v", err)
+	}
+	callback.SetDefault(notifier)
+	if cfg.Callbacks.URL != "" {
+		logger.Infof("Job callbacks: %s, signed with key %s", cfg.Callbacks.URL, cfg.Callbacks.KeyID)
+	} else {
+		logger.Infof("Job callbacks: on request, signed with key %s", cfg.Callbacks.KeyID)
+	}
+	return notifier
+}
+
+// openAuditLog opens the audit log and makes it the default one. It returns nil
+// when auditing is disabled. The service does not start without its audit log.
+func openAuditLog(cfg *config.Config) *audit.Log {
+	if cfg.AuditLogPath == "none" {
+		logger.Warn("Audit log: disabled")
+		return nil
+	}
+
+	auditLog, err := audit.Open(cfg.AuditLogPath)
+	if err != nil {
+		logger.Fatalf("Failed to open audit log %s: %v", cfg.AuditLogPath, err)
+	}
+	audit.SetDefault(auditLog)
+	logger.Infof("Audit log: %s", cfg.AuditLogPath)
+	return auditLog
+}
+
+// verifyAuditLog checks the hash chain of the audit log at path, or of the
+// configured audit log when path is empty.
+func verifyAuditLog(path, configPath string) error {
+	if path == "" {
+		cfg, err := readConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		path = cfg.AuditLogPath
+	}
+
+	summary, err := audit.VerifyFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	console.Printf("Audit log: %s\n", path)
+	console.Printf("Records: %d, chain intact\n", summary.Records)
+	console.Printf("Last hash: %s\n", summary.LastHash)
+	return nil
+}
+
 func printHelp() {
 	console.Println("Cloud Update Service")
 	console.Println()
@@ -199,11 +752,49 @@ func printHelp() {
 	console.Println("  --help        Show this help message")
 	console.Println("  --setup       Install service on the system")
 	console.Println("  --uninstall   Uninstall service from the system")
+	console.Println("  --config PATH Configuration file (default: /etc/cloud-update/config.yaml)")
+	console.Println("  --gen-cert    Generate a self-signed certificate in /etc/cloud-update/tls")
+	console.Println("  --self-signed With --setup, also generate a self-signed certificate and enable HTTPS")
+	console.Println("  --cert-hosts  Certificate DNS names and IPs, comma-separated (default: host name, localhost, 127.0.0.1, ::1)")
+	console.Println("  --cert-days   Certificate validity in days (default: 365)")
+	console.Println("  --force       With --gen-cert, replace an existing certificate")
+	console.Println("  --verify-audit [FILE]  Verify the hash chain of the audit log (default: the configured one)")
 	console.Println()
-	console.Println("Environment Variables:")
+	console.Println("Environment variables override the configuration file:")
+	console.Println("  CLOUD_UPDATE_CONFIG_PATH  Configuration file (default: /etc/cloud-update/config.yaml)")
+	console.Println("  CLOUD_UPDATE_HOST       Address to listen on (default: all interfaces)")
 	console.Println("  CLOUD_UPDATE_PORT       Port to listen on (default: 9999)")
-	console.Println("  CLOUD_UPDATE_SECRET     HMAC secret for webhook authentication (required)")
+	console.Println("  CLOUD_UPDATE_AUTH       Webhook authentication: hmac, public_key or mtls (default: hmac)")
+	console.Println("  CLOUD_UPDATE_SECRET     HMAC secret for webhook authentication (required with hmac)")
 	console.Println("  CLOUD_UPDATE_LOG_LEVEL  Log level: debug, info, warn, error (default: info)")
+	console.Println("  CLOUD_UPDATE_LOG_FILE   Log file (default: /var/log/cloud-update/cloud-update.log)")
+	console.Println("  CLOUD_UPDATE_TLS_ENABLED, CLOUD_UPDATE_TLS_CERT, CLOUD_UPDATE_TLS_KEY  HTTPS settings")
+	console.Println("  CLOUD_UPDATE_TLS_EXPIRY_WARNING  Warn when the certificate expires within this duration (default: 336h)")
+	console.Println("  CLOUD_UPDATE_TLS_AUTO, CLOUD_UPDATE_DOMAIN  Obtain certificates for the domain with ACME (Let's Encrypt)")
+	console.Println("  CLOUD_UPDATE_ACME_DIRECTORY, CLOUD_UPDATE_ACME_EMAIL, CLOUD_UPDATE_ACME_CHALLENGE  ACME CA, contact and challenge")
+	console.Println("  CLOUD_UPDATE_ACME_HTTP_ADDR, CLOUD_UPDATE_ACME_CACHE, CLOUD_UPDATE_ACME_CA  http-01 listener, cache and CA bundle")
+	console.Println("  CLOUD_UPDATE_TLS_CLIENT_AUTH, CLOUD_UPDATE_TLS_CLIENT_CA, CLOUD_UPDATE_TLS_CLIENT_ALLOWED  Client certificates (mTLS)")
+	console.Println("  CLOUD_UPDATE_RATE_LIMIT, CLOUD_UPDATE_RATE_BURST  Webhook requests per second and burst (default: 10, 20)")
+	console.Println("  CLOUD_UPDATE_WORKERS, CLOUD_UPDATE_QUEUE_SIZE  Worker pool size and backlog (default: 10, 100)")
+	console.Println("  CLOUD_UPDATE_JOB_TIMEOUT  Maximum run time of a job (default: 5m)")
+	console.Println("  CLOUD_UPDATE_PRIVILEGE  Privilege escalation: auto, none, doas or sudo (default: auto)")
+	console.Println("  CLOUD_UPDATE_COMMAND_TIMEOUT  Maximum run time of each system command (default: 5m)")
+	console.Println("  CLOUD_UPDATE_REBOOT_STRATEGY  Reboots and shutdowns: immediate or scheduled (default: immediate)")
+	console.Println("  CLOUD_UPDATE_REQUIRE_NONCE  Reject webhook requests without a nonce (default: true)")
+	console.Println("  CLOUD_UPDATE_ALLOWED_ACTIONS  Comma-separated actions to accept, or \"all\" (default: update)")
+	console.Println("  CLOUD_UPDATE_JOB_STORE  Job history file, or \"memory\" (default: /var/lib/cloud-update/jobs.log)")
+	console.Println("  CLOUD_UPDATE_JOB_LOGS  Job command output directory, or \"memory\" (default: /var/lib/cloud-update/logs)")
+	console.Println("  CLOUD_UPDATE_JOB_QUEUE  Jobs waiting for the running job, 0 rejects them (default: 0)")
+	console.Println("  CLOUD_UPDATE_JOB_RETENTION  How long job history is kept (default: 168h)")
+	console.Println("  CLOUD_UPDATE_AUDIT_LOG  Audit log file, or \"none\" (default: /var/log/cloud-update/audit.log)")
+	console.Println("  CLOUD_UPDATE_CALLBACK_URL  Default URL finished jobs are POSTed to (default: none)")
+	console.Println("  CLOUD_UPDATE_CALLBACK_SECRET  HMAC secret signing callbacks (default: the webhook secret)")
+	console.Println("  CLOUD_UPDATE_CALLBACK_MAX_ATTEMPTS, CLOUD_UPDATE_CALLBACK_TIMEOUT  Callback attempts and timeout (default: 5, 10s)")
+	console.Println("  CLOUD_UPDATE_CALLBACK_RETRY_DELAY  Delay before retrying a callback, doubled each time (default: 10s)")
+	console.Println("  CLOUD_UPDATE_CALLBACK_ALLOWED_NETWORKS  Local networks callbacks may reach (default: none)")
+	console.Println()
+	console.Println("Send SIGHUP to reload the webhook keys, callback settings, rate limits, log level and TLS certificates.")
+	console.Println("Certificate files are also reloaded automatically when they change on disk.")
 	console.Println()
 	console.Println("Service Control:")
 	console.Println("  systemctl start cloud-update    # Start service")
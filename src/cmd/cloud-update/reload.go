@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/tls"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"syscall"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/config"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/ratelimit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+)
+
+// configReloader re-reads the configuration on SIGHUP and applies the settings that
+// can change without interrupting the listener or running jobs.
+type configReloader struct {
+	configPath    string
+	current       *config.Config
+	authenticator *security.ReloadableAuthenticator
+	rateLimiter   *ratelimit.RateLimiter
+	certs         *config.CertReloader // nil unless serving manual TLS certificates
+	notifier      *callback.Notifier   // nil unless job callbacks are enabled
+}
+
+// watch reloads the configuration each time the process receives SIGHUP.
+func (r *configReloader) watch() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	for range sigChan {
+		r.reload()
+	}
+}
+
+// reload applies the new configuration. Nothing is changed if any part of it is invalid.
+func (r *configReloader) reload() {
+	logger.Info("Reloading configuration")
+
+	cfg, err := readConfig(r.configPath)
+	if err == nil {
+		err = r.apply(cfg)
+	}
+	if err != nil {
+		logger.Errorf("Configuration reload failed, keeping current configuration: %v", err)
+		return
+	}
+
+	logger.WithField("log_level", cfg.LogLevel).
+		WithField("rate_limit", cfg.RateLimit.RequestsPerSecond).
+		WithField("rate_burst", cfg.RateLimit.Burst).
+		Info("Configuration reloaded")
+}
+
+// apply switches to cfg once every part of it has been loaded and validated.
+func (r *configReloader) apply(cfg *config.Config) error {
+	auth, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if r.certs != nil && cfg.TLS.Enabled && !cfg.TLS.Auto {
+		if cert, err = config.LoadCertificate(cfg.TLS.CertFile, cfg.TLS.KeyFile); err != nil {
+			return err
+		}
+	}
+
+	// Callbacks signed with webhook_secret follow its rotation. Update changes
+	// nothing when it fails, so it is the last check and the first change.
+	if r.notifier != nil && cfg.Callbacks.Secret != "" {
+		if err := r.notifier.Update(cfg.Callbacks); err != nil {
+			return err
+		}
+	}
+
+	if cert != nil {
+		r.certs.Set(cfg.TLS.CertFile, cfg.TLS.KeyFile, cert)
+		logger.Infof("TLS certificates reloaded from %s", cfg.TLS.CertFile)
+	}
+	r.authenticator.Swap(auth)
+	r.rateLimiter.Update(ratelimit.Config{
+		RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+		Burst:             cfg.RateLimit.Burst,
+		TTL:               cfg.RateLimit.TTL,
+	})
+	if err := logger.SetLevel(cfg.LogLevel); err != nil {
+		logger.Warnf("Keeping current log level: %v", err)
+	}
+
+	r.warnRestartRequired(cfg)
+	r.current = cfg
+	return nil
+}
+
+// warnRestartRequired logs the changed settings that only take effect after a restart.
+func (r *configReloader) warnRestartRequired(cfg *config.Config) {
+	old := r.current
+	clientAuthChanged := cfg.TLS.ClientAuth != old.TLS.ClientAuth || cfg.TLS.ClientCAFile != old.TLS.ClientCAFile ||
+		!slices.Equal(cfg.TLS.ClientAllowed, old.TLS.ClientAllowed)
+	jobsChanged := cfg.JobStorePath != old.JobStorePath || cfg.JobLogDir != old.JobLogDir ||
+		cfg.JobQueue != old.JobQueue || cfg.JobRetention != old.JobRetention
+	maintenanceChanged := !slices.Equal(cfg.Maintenance.Windows, old.Maintenance.Windows) ||
+		cfg.Maintenance.Location.String() != old.Maintenance.Location.String() ||
+		!slices.Equal(cfg.Maintenance.Actions.List(), old.Maintenance.Actions.List())
+	// Callbacks are reloaded, but enabling or disabling them takes a restart
+	callbacksToggled := (cfg.Callbacks.Secret == "") != (old.Callbacks.Secret == "")
+	changed := []struct {
+		key     string
+		differs bool
+	}{
+		{"actions.allowed", !slices.Equal(cfg.AllowedActions.List(), old.AllowedActions.List())},
+		{"audit.file", cfg.AuditLogPath != old.AuditLogPath},
+		{"callbacks", callbacksToggled},
+		{"executor", !reflect.DeepEqual(cfg.Executor, old.Executor)},
+		{"jobs", jobsChanged},
+		{"logging.file", cfg.LogFilePath != old.LogFilePath},
+		{"maintenance", maintenanceChanged},
+		{"metrics", cfg.Metrics != old.Metrics},
+		{"server.host", cfg.Host != old.Host},
+		{"server.port", cfg.Port != old.Port},
+		{"telemetry", cfg.Telemetry != old.Telemetry},
+		{"tls.acme", cfg.TLS.Domain != old.TLS.Domain || cfg.TLS.ACME != old.TLS.ACME},
+		{"tls.client_*", clientAuthChanged},
+		{"tls.enabled", cfg.TLS.Enabled != old.TLS.Enabled || cfg.TLS.Auto != old.TLS.Auto},
+		{"tls.expiry_warning", cfg.TLS.ExpiryWarning != old.TLS.ExpiryWarning},
+		{"workers", cfg.Workers != old.Workers},
+	}
+	for _, c := range changed {
+		if c.differs {
+			logger.WithField("key", c.key).Warn("Configuration change requires a restart to take effect")
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/config"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/ratelimit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+)
+
+const testSecret = "test-webhook-secret-with-32-chars!!"
+
+// newTestConfig returns a configuration serving the certificate generated in dir.
+func newTestConfig(t *testing.T, dir string) *config.Config {
+	t.Helper()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := config.GenerateSelfSignedCert(certFile, keyFile, []string{"localhost"}, time.Hour); err != nil {
+		t.Fatalf("GenerateSelfSignedCert() error = %v", err)
+	}
+	return &config.Config{
+		Secret:    testSecret,
+		LogLevel:  "info",
+		TLS:       &config.TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile},
+		RateLimit: config.RateLimitConfig{RequestsPerSecond: 10, Burst: 20, TTL: time.Minute},
+		Callbacks: callback.Config{KeyID: "default", Secret: testSecret},
+	}
+}
+
+func newTestReloader(t *testing.T, cfg *config.Config) *configReloader {
+	t.Helper()
+	certs, err := config.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	notifier, err := callback.New(cfg.Callbacks)
+	if err != nil {
+		t.Fatalf("callback.New() error = %v", err)
+	}
+	t.Cleanup(notifier.Close)
+	auth, err := newAuthenticator(cfg)
+	if err != nil {
+		t.Fatalf("newAuthenticator() error = %v", err)
+	}
+	return &configReloader{
+		current:       cfg,
+		authenticator: security.NewReloadableAuthenticator(auth),
+		rateLimiter:   ratelimit.NewRateLimiter(ratelimit.Config{RequestsPerSecond: 10, Burst: 20, TTL: time.Minute}),
+		certs:         certs,
+		notifier:      notifier,
+	}
+}
+
+func TestConfigReloader_apply(t *testing.T) {
+	r := newTestReloader(t, newTestConfig(t, t.TempDir()))
+	first, _ := r.certs.GetCertificate(nil)
+
+	cfg := newTestConfig(t, t.TempDir())
+	if err := r.apply(cfg); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if current, _ := r.certs.GetCertificate(nil); current == first {
+		t.Error("apply() should serve the new certificate")
+	}
+	if r.current != cfg {
+		t.Error("apply() should make the new configuration current")
+	}
+}
+
+func TestConfigReloader_apply_InvalidCallbacks(t *testing.T) {
+	r := newTestReloader(t, newTestConfig(t, t.TempDir()))
+	first, _ := r.certs.GetCertificate(nil)
+	current := r.current
+
+	// A new certificate and callbacks the notifier refuses
+	cfg := newTestConfig(t, t.TempDir())
+	cfg.Callbacks.URL = "ftp://ci.example.com/done"
+	if err := r.apply(cfg); err == nil {
+		t.Fatal("apply() with invalid callbacks should fail")
+	}
+	if cert, _ := r.certs.GetCertificate(nil); cert != first {
+		t.Error("a failed apply() should keep the current certificate")
+	}
+	if r.current != current {
+		t.Error("a failed apply() should keep the current configuration")
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/httputil"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/metrics"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
+)
+
+// auditRequest records a signed request, who sent it and the response it got.
+func auditRequest(event string, r *http.Request, w *httputil.StatusRecorder, keyID string, action entity.ActionType,
+	module, jobID string,
+) {
+	rec := audit.Record{
+		Event:          event,
+		SourceIP:       r.RemoteAddr,
+		ForwardedFor:   r.Header.Get("X-Forwarded-For"),
+		KeyID:          keyID,
+		ClientIdentity: security.ClientIdentity(r),
+		Action:         string(action),
+		Module:         module,
+		JobID:          jobID,
+		HTTPStatus:     w.Status(),
+		Outcome:        "accepted",
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		rec.SourceIP = host
+	}
+	if w.Status() >= http.StatusBadRequest {
+		rec.Outcome = "rejected"
+	}
+	audit.Write(rec)
+}
+
+// recordOutcome records a finished job in the metrics and the audit log, and
+// reports it to its callback URL, recording the delivery attempts in jobStore.
+func recordOutcome(jobStore store.JobStore, job entity.Job) {
+	metrics.ObserveJob(job)
+	audit.Job(job)
+	callback.Job(job, jobStore)
+}
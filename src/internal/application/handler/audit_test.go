@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+// auditRecords returns the records of the audit log at path.
+func auditRecords(t *testing.T, path string) []audit.Record {
+	t.Helper()
+	f, err := os.Open(path) //nolint:gosec // Test file
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var recs []audit.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec audit.Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid audit record: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestWebhookHandlerWithPool_Audit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	audit.SetDefault(auditLog)
+	defer func() {
+		audit.SetDefault(nil)
+		_ = auditLog.Close()
+	}()
+
+	auth := &mockAuthenticatorPool{shouldValidate: false}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{}, auth, mockPool)
+
+	body := fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix())
+	postWebhook(handler, body)
+	auth.shouldValidate = true
+	rr, response := postWebhook(handler, body)
+	if rr.Code != 202 {
+		t.Fatalf("Expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	jobID, _ := response["job_id"].(string)
+
+	// The job outcome is recorded once it finished
+	deadline := time.Now().Add(5 * time.Second)
+	for len(auditRecords(t, path)) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	recs := auditRecords(t, path)
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 audit records, got %+v", recs)
+	}
+	// The job may finish before the accepted request is recorded
+	rejected, accepted, job := recs[0], recs[1], recs[2]
+	if accepted.Event == audit.EventJob {
+		accepted, job = job, accepted
+	}
+	if rejected.Event != audit.EventRequest || rejected.Outcome != "rejected" || rejected.HTTPStatus != 401 ||
+		rejected.SourceIP != "192.0.2.1" || rejected.Action != "update" || rejected.JobID != "" {
+		t.Errorf("rejected request record = %+v", rejected)
+	}
+	if accepted.Outcome != "accepted" || accepted.HTTPStatus != 202 || accepted.JobID != jobID {
+		t.Errorf("accepted request record = %+v", accepted)
+	}
+	if job.Event != audit.EventJob || job.JobID != jobID || job.Outcome != string(entity.JobStatusCompleted) {
+		t.Errorf("job record = %+v", job)
+	}
+
+	if summary, err := audit.VerifyFile(path); err != nil || summary.Records != 3 {
+		t.Errorf("VerifyFile() = %+v, %v", summary, err)
+	}
+}
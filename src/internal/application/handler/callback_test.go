@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+func TestWebhookHandlerWithPool_Callback(t *testing.T) {
+	delivered := make(chan callback.Payload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload callback.Payload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		delivered <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	auth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{}, auth, mockPool)
+
+	body := fmt.Sprintf(`{"action":"update","timestamp":%d,"callback_url":%q}`, time.Now().Unix(), receiver.URL)
+	if rr, _ := postWebhook(handler, body); rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 while callbacks are disabled, got %d", rr.Code)
+	}
+
+	notifier, err := callback.New(callback.Config{
+		KeyID: "callback", Secret: "test-callback-secret-with-32-chars!",
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	callback.SetDefault(notifier)
+	defer func() {
+		callback.SetDefault(nil)
+		notifier.Close()
+	}()
+
+	invalid := fmt.Sprintf(`{"action":"update","timestamp":%d,"callback_url":"file:///etc/passwd"}`, time.Now().Unix())
+	if rr, _ := postWebhook(handler, invalid); rr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for an invalid callback URL, got %d", rr.Code)
+	}
+
+	rr, response := postWebhook(handler, body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	jobID, _ := response["job_id"].(string)
+
+	select {
+	case payload := <-delivered:
+		if payload.JobID != jobID || payload.Status != entity.JobStatusCompleted || payload.Attempt != 1 {
+			t.Errorf("callback payload = %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the job callback")
+	}
+
+	// The delivery is recorded on the job
+	job := handler.jobStore.GetJob(jobID)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(job.Snapshot().Callbacks) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := httptest.NewRecorder()
+	handler.HandleJobStatus(status, httptest.NewRequest(http.MethodGet, withTimestamp("/job/status?job_id="+jobID), nil))
+	var got struct {
+		CallbackURL string                   `json:"callback_url"`
+		Callbacks   []entity.CallbackAttempt `json:"callbacks"`
+	}
+	_ = json.Unmarshal(status.Body.Bytes(), &got)
+	if got.CallbackURL != receiver.URL || len(got.Callbacks) != 1 || !got.Callbacks[0].Delivered() ||
+		got.Callbacks[0].StatusCode != http.StatusOK {
+		t.Errorf("job status callbacks = %+v", got)
+	}
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
 )
 
@@ -19,8 +21,11 @@ func (m *MockAuthenticator) ValidateSignature(r *http.Request, body []byte) bool
 // MockActionServiceSimple for testing.
 type MockActionServiceSimple struct{}
 
-func (m *MockActionServiceSimple) ProcessAction(req entity.WebhookRequest, jobID string) {
+func (m *MockActionServiceSimple) ProcessAction(
+	_ context.Context, req entity.WebhookRequest, jobID string, _ system.OutputSink,
+) *entity.ActionResult {
 	// Mock implementation
+	return entity.NewActionResult(req.Action)
 }
 
 // TestWebhookHandlerWithPoolCleanup tests the Cleanup method.
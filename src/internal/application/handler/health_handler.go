@@ -3,20 +3,65 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/config"
 )
 
+// Check reports whether a dependency of the service works, or why it does not.
+type Check func() error
+
+// namedCheck is a readiness check and the name it is reported under.
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// checkResult is the outcome of a readiness check.
+type checkResult struct {
+	Status string `json:"status"` // "ok" or "failed"
+	Error  string `json:"error,omitempty"`
+}
+
+// readinessResponse is the body of a readiness response.
+type readinessResponse struct {
+	Status    string                 `json:"status"` // "ready" or "degraded"
+	Service   string                 `json:"service"`
+	Timestamp string                 `json:"timestamp"`
+	Checks    map[string]checkResult `json:"checks"`
+}
+
 // HealthHandler handles health check requests.
-type HealthHandler struct{}
+type HealthHandler struct {
+	cert       config.CertificateExpiry // Served TLS certificate, nil without TLS
+	warnBefore time.Duration
+	checks     []namedCheck
+}
 
 // NewHealthHandler creates a new health handler instance.
 func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
+// WithCertificate reports the expiry of the served TLS certificate, which is
+// flagged as expiring once less than warnBefore remains.
+func (h *HealthHandler) WithCertificate(cert config.CertificateExpiry, warnBefore time.Duration) *HealthHandler {
+	h.cert = cert
+	h.warnBefore = warnBefore
+	return h
+}
+
+// WithCheck adds a readiness check reported under name.
+func (h *HealthHandler) WithCheck(name string, check Check) *HealthHandler {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+	return h
+}
+
 // HandleHealth responds to health check requests with service status.
 func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -29,8 +74,86 @@ func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 		"service":   "cloud-update",
 		"timestamp": fmt.Sprintf("%d", time.Now().Unix()),
 	}
+	if h.cert != nil {
+		cert := config.CheckExpiry(h.cert, h.warnBefore, time.Now())
+		response["tls_certificate"] = cert.State
+		if !cert.NotAfter.IsZero() {
+			response["tls_certificate_expires"] = cert.NotAfter.UTC().Format(time.RFC3339)
+			response["tls_certificate_days_left"] = strconv.Itoa(cert.DaysLeft)
+		}
+		if cert.State == "expired" {
+			response["status"] = "degraded"
+		}
+	}
+
+	writeHealth(w, http.StatusOK, response)
+}
+
+// HandleLiveness responds as long as the service serves requests, whatever the
+// state of its dependencies.
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeHealth(w, http.StatusOK, map[string]string{
+		"status":    "alive",
+		"service":   "cloud-update",
+		"timestamp": fmt.Sprintf("%d", time.Now().Unix()),
+	})
+}
+
+// HandleReadiness runs the readiness checks and reports each of them, responding
+// with 503 Service Unavailable when any fails.
+func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := readinessResponse{
+		Status:    "ready",
+		Service:   "cloud-update",
+		Timestamp: fmt.Sprintf("%d", time.Now().Unix()),
+		Checks:    make(map[string]checkResult, len(h.checks)+1),
+	}
+	checks := h.checks
+	if h.cert != nil {
+		checks = append(checks[:len(checks):len(checks)], namedCheck{"tls_certificate", h.checkCertificate})
+	}
+	for _, c := range checks {
+		result := checkResult{Status: "ok"}
+		if err := c.check(); err != nil {
+			result = checkResult{Status: "failed", Error: err.Error()}
+			response.Status = "degraded"
+		}
+		response.Checks[c.name] = result
+	}
+
+	status := http.StatusOK
+	if response.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, status, response)
+}
+
+// checkCertificate fails once the served TLS certificate expired, or while there is none.
+func (h *HealthHandler) checkCertificate() error {
+	cert := config.CheckExpiry(h.cert, h.warnBefore, time.Now())
+	switch cert.State {
+	case "missing":
+		return errors.New("no TLS certificate obtained yet")
+	case "expired":
+		return fmt.Errorf("TLS certificate expired on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
 
+// writeHealth writes a health response as JSON.
+func writeHealth(w http.ResponseWriter, status int, response any) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Failed to encode health response: %v", err)
 		// Try to send error response if headers not sent yet
@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHealthHandler_HandleHealth(t *testing.T) {
@@ -193,3 +197,154 @@ func TestHealthHandler_JSONEncodingError(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rr.status)
 	}
 }
+
+type certExpiry time.Time
+
+func (c certExpiry) NotAfter() time.Time { return time.Time(c) }
+
+func TestHealthHandler_Certificate(t *testing.T) {
+	tests := []struct {
+		name       string
+		notAfter   time.Time
+		wantStatus string
+		wantCert   string
+	}{
+		{"valid", time.Now().Add(90 * 24 * time.Hour), "healthy", "valid"},
+		{"expiring", time.Now().Add(3 * 24 * time.Hour), "healthy", "expiring"},
+		{"expired", time.Now().Add(-time.Hour), "degraded", "expired"},
+		{"not obtained yet", time.Time{}, "healthy", "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler().WithCertificate(certExpiry(tt.notAfter), 14*24*time.Hour)
+			rr := httptest.NewRecorder()
+			handler.HandleHealth(rr, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+			var response map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response["status"] != tt.wantStatus || response["tls_certificate"] != tt.wantCert {
+				t.Errorf("status = %s, tls_certificate = %s, want %s, %s",
+					response["status"], response["tls_certificate"], tt.wantStatus, tt.wantCert)
+			}
+			if !tt.notAfter.IsZero() && response["tls_certificate_days_left"] == "" {
+				t.Error("tls_certificate_days_left should be reported")
+			}
+		})
+	}
+}
+
+func TestHealthHandler_HandleLiveness(t *testing.T) {
+	handler := NewHealthHandler().WithCheck("job_store", func() error { return errors.New("read-only file system") })
+
+	rr := httptest.NewRecorder()
+	handler.HandleLiveness(rr, httptest.NewRequest(http.MethodGet, "/livez", http.NoBody))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 whatever the checks, got %d", rr.Code)
+	}
+	var response map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response["status"] != "alive" || response["service"] != "cloud-update" {
+		t.Errorf("Unexpected response: %v", response)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleLiveness(rr, httptest.NewRequest(http.MethodPost, "/livez", http.NoBody))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", rr.Code)
+	}
+}
+
+func TestHealthHandler_HandleReadiness(t *testing.T) {
+	ok := func() error { return nil }
+	failing := func() error { return errors.New("worker pool is full") }
+
+	tests := []struct {
+		name       string
+		handler    *HealthHandler
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]checkResult
+	}{
+		{
+			name:       "no checks",
+			handler:    NewHealthHandler(),
+			wantCode:   http.StatusOK,
+			wantStatus: "ready",
+			wantChecks: map[string]checkResult{},
+		},
+		{
+			name:       "all checks pass",
+			handler:    NewHealthHandler().WithCheck("worker_pool", ok).WithCheck("job_store", ok),
+			wantCode:   http.StatusOK,
+			wantStatus: "ready",
+			wantChecks: map[string]checkResult{"worker_pool": {Status: "ok"}, "job_store": {Status: "ok"}},
+		},
+		{
+			name:       "failing check",
+			handler:    NewHealthHandler().WithCheck("worker_pool", failing).WithCheck("job_store", ok),
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "degraded",
+			wantChecks: map[string]checkResult{
+				"worker_pool": {Status: "failed", Error: "worker pool is full"},
+				"job_store":   {Status: "ok"},
+			},
+		},
+		{
+			name: "expiring certificate",
+			handler: NewHealthHandler().WithCheck("job_store", ok).
+				WithCertificate(certExpiry(time.Now().Add(3*24*time.Hour)), 14*24*time.Hour),
+			wantCode:   http.StatusOK,
+			wantStatus: "ready",
+			wantChecks: map[string]checkResult{"job_store": {Status: "ok"}, "tls_certificate": {Status: "ok"}},
+		},
+		{
+			name:       "missing certificate",
+			handler:    NewHealthHandler().WithCertificate(certExpiry(time.Time{}), 14*24*time.Hour),
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "degraded",
+			wantChecks: map[string]checkResult{
+				"tls_certificate": {Status: "failed", Error: "no TLS certificate obtained yet"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler.HandleReadiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("Expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+			var response readinessResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.wantStatus || !reflect.DeepEqual(response.Checks, tt.wantChecks) {
+				t.Errorf("status = %s, checks = %+v, want %s, %+v",
+					response.Status, response.Checks, tt.wantStatus, tt.wantChecks)
+			}
+		})
+	}
+}
+
+func TestHealthHandler_ReadinessExpiredCertificate(t *testing.T) {
+	handler := NewHealthHandler().WithCertificate(certExpiry(time.Now().Add(-time.Hour)), 14*24*time.Hour)
+
+	rr := httptest.NewRecorder()
+	handler.HandleReadiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+
+	var response readinessResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if rr.Code != http.StatusServiceUnavailable || response.Checks["tls_certificate"].Status != "failed" ||
+		!strings.Contains(response.Checks["tls_certificate"].Error, "expired") {
+		t.Errorf("code = %d, checks = %+v, want an expired certificate", rr.Code, response.Checks)
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/httputil"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/metrics"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
+)
+
+// jobRun is an accepted job that can be cancelled.
+type jobRun struct {
+	cancel    context.CancelCauseFunc // Set once a worker runs the job
+	cancelled bool
+}
+
+// jobRuns tracks the accepted jobs, from submission to the end of their action.
+type jobRuns struct {
+	mu   sync.Mutex
+	runs map[string]*jobRun
+}
+
+func newJobRuns() *jobRuns {
+	return &jobRuns{runs: make(map[string]*jobRun)}
+}
+
+// add registers an accepted job, before a worker picks it up.
+func (r *jobRuns) add(jobID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.runs[jobID] == nil {
+		r.runs[jobID] = &jobRun{}
+	}
+}
+
+// start derives the context of a job from the worker context. The context is
+// cancelled with store.ErrJobCancelled by cancel, including a cancel received
+// before the job started. The returned function must be called once the job is done.
+func (r *jobRuns) start(ctx context.Context, jobID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	run := r.runs[jobID]
+	if run == nil {
+		run = &jobRun{}
+		r.runs[jobID] = run
+	}
+	run.cancel = cancel
+	if run.cancelled {
+		cancel(store.ErrJobCancelled)
+	}
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.remove(jobID)
+		cancel(nil)
+	}
+}
+
+// remove forgets a job, cancelled or not.
+func (r *jobRuns) remove(jobID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.runs, jobID)
+}
+
+// cancel requests a job to stop. It returns false when the job is not tracked,
+// because its action already returned.
+func (r *jobRuns) cancel(jobID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run := r.runs[jobID]
+	if run == nil {
+		return false
+	}
+	run.cancelled = true
+	if run.cancel != nil {
+		run.cancel(store.ErrJobCancelled)
+	}
+	return true
+}
+
+// HandleJobCancel stops a running job or removes a queued or scheduled one. The request is
+// signed like a webhook; the running command and the processes it started are
+// killed and the job is recorded as cancelled once its action returns.
+func (h *WebhookHandlerWithPool) HandleJobCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Record the request, the key that signed it and the response in the audit log
+	var req entity.CancelRequest
+	var keyID string
+	aw := httputil.NewStatusRecorder(w)
+	w = aw
+	defer func() {
+		var action entity.ActionType
+		if req.JobID != "" {
+			if job := h.jobStore.GetJob(req.JobID); job != nil {
+				action = job.Action
+			}
+		}
+		auditRequest(audit.EventCancel, r, aw, keyID, action, "", req.JobID)
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1024*1024)) // 1MB limit
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to read request body")
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		logger.WithField("error", err).Error("Failed to parse request")
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	var ok bool
+	if keyID, ok = h.authenticate(w, r, body, req.Timestamp, req.Nonce); !ok {
+		return
+	}
+
+	if req.JobID == "" {
+		http.Error(w, "Job ID required", http.StatusBadRequest)
+		return
+	}
+
+	job := h.jobStore.GetJob(req.JobID)
+	if job == nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", "Job not found")
+		return
+	}
+	status := job.GetStatus()
+	if (status == entity.JobStatusPending && h.cancelQueued(job.ID)) ||
+		(status == entity.JobStatusScheduled && h.jobStore.CancelScheduledJob(job.ID)) {
+		logger.WithField("job_id", job.ID).
+			WithField("client_identity", security.ClientIdentity(r)).
+			Warn("Waiting job cancelled")
+		metrics.Jobs.WithLabelValues(string(job.Action), string(entity.JobStatusCancelled)).Inc()
+		audit.Job(job.Snapshot())
+		callback.Job(job.Snapshot(), h.jobStore)
+		w.Header().Set("Content-Type", "application/json")
+		response := map[string]interface{}{
+			"status": entity.JobStatusCancelled,
+			"job_id": job.ID,
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logger.WithField("error", err).Error("Failed to encode response")
+		}
+		return
+	}
+	if !job.IsRunning() || !h.runs.cancel(job.ID) {
+		// Finished jobs, and reboot jobs waiting for the system to restart
+		writeJSONError(w, http.StatusConflict, "not_cancellable", "Job is not running a command")
+		return
+	}
+
+	logger.WithField("job_id", job.ID).
+		WithField("client_identity", security.ClientIdentity(r)).
+		Warn("Job cancellation requested")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	response := map[string]interface{}{
+		"status": "cancelling",
+		"job_id": job.ID,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
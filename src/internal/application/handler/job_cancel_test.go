@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+// blockingActionService runs until its context is cancelled, like a hung command.
+type blockingActionService struct {
+	started chan struct{}
+}
+
+func (m *blockingActionService) ProcessAction(
+	ctx context.Context, req entity.WebhookRequest, _ string, _ system.OutputSink,
+) *entity.ActionResult {
+	close(m.started)
+	<-ctx.Done()
+	result := entity.NewActionResult(req.Action)
+	result.Fail(ctx.Err())
+	return result
+}
+
+func cancelRequest(jobID string) *http.Request {
+	body := fmt.Sprintf(`{"job_id":%q,"timestamp":%d}`, jobID, time.Now().Unix())
+	return httptest.NewRequest(http.MethodPost, "/job/cancel", bytes.NewBufferString(body))
+}
+
+func TestWebhookHandlerWithPool_HandleJobCancel(t *testing.T) {
+	action := &blockingActionService{started: make(chan struct{})}
+	pool := worker.NewPool(1, 1)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(action, &mockAuthenticatorPool{shouldValidate: true}, pool)
+
+	rr := httptest.NewRecorder()
+	handler.HandleWebhook(rr, httptest.NewRequest(http.MethodPost, "/webhook",
+		bytes.NewBufferString(fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix()))))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("webhook status = %d, want 202", rr.Code)
+	}
+	jobID := rr.Header().Get("X-Job-ID")
+
+	select {
+	case <-action.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not start")
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleJobCancel(rr, cancelRequest(jobID))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("cancel status = %d, want 202: %s", rr.Code, rr.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	job := handler.jobStore.GetJob(jobID)
+	for job.GetStatus() == entity.JobStatusRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("job was not cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := job.GetStatus(); status != entity.JobStatusCancelled {
+		t.Errorf("status = %s, want cancelled", status)
+	}
+	if !errors.Is(job.Snapshot().Error, store.ErrJobCancelled) {
+		t.Errorf("error = %v, want ErrJobCancelled", job.Snapshot().Error)
+	}
+	if handler.jobStore.GetCurrentJob() != nil {
+		t.Error("expected a new job to be accepted after the cancellation")
+	}
+
+	// The job is over, cancelling it again is a conflict
+	rr = httptest.NewRecorder()
+	handler.HandleJobCancel(rr, cancelRequest(jobID))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("second cancel status = %d, want 409", rr.Code)
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobCancel_BeforeStart(t *testing.T) {
+	mockAction := &mockActionServicePool{}
+	pool := worker.NewPool(1, 1)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(mockAction, &mockAuthenticatorPool{shouldValidate: true}, pool)
+
+	job := entity.NewJob("queued-job", entity.ActionUpdate)
+	handler.jobStore.TryStartJob(job)
+	handler.runs.add(job.ID)
+
+	rr := httptest.NewRecorder()
+	handler.HandleJobCancel(rr, cancelRequest(job.ID))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("cancel status = %d, want 202", rr.Code)
+	}
+
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionUpdate}, job)
+	if mockAction.wasProcessActionCalled() {
+		t.Error("a job cancelled before it started should not run")
+	}
+	if status := job.GetStatus(); status != entity.JobStatusCancelled {
+		t.Errorf("status = %s, want cancelled", status)
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobCancel_Errors(t *testing.T) {
+	pool := worker.NewPool(1, 1)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	auth := &mockAuthenticatorPool{shouldValidate: true}
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{}, auth, pool)
+
+	// A job running without a command, like a reboot waiting for the system to restart
+	handler.jobStore.TryStartJob(entity.NewJob("reboot-job", entity.ActionReboot))
+
+	tests := []struct {
+		name          string
+		req           *http.Request
+		authenticated bool
+		want          int
+		wantStatus    string
+	}{
+		{"invalid method", httptest.NewRequest(http.MethodGet, "/job/cancel", nil), true,
+			http.StatusMethodNotAllowed, ""},
+		{"invalid json", httptest.NewRequest(http.MethodPost, "/job/cancel", bytes.NewBufferString("{")), true,
+			http.StatusBadRequest, ""},
+		{"unauthorized", cancelRequest("reboot-job"), false, http.StatusUnauthorized, ""},
+		{"missing job ID", cancelRequest(""), true, http.StatusBadRequest, ""},
+		{"unknown job", cancelRequest("unknown"), true, http.StatusNotFound, "not_found"},
+		{"no running command", cancelRequest("reboot-job"), true, http.StatusConflict, "not_cancellable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth.shouldValidate = tt.authenticated
+			rr := httptest.NewRecorder()
+			handler.HandleJobCancel(rr, tt.req)
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.want)
+			}
+			if tt.wantStatus == "" {
+				return
+			}
+			var response map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response["status"] != tt.wantStatus {
+				t.Errorf("status = %q, want %q", response["status"], tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
+)
+
+// jobLogPageSize bounds the lines returned by a /job/logs request.
+const jobLogPageSize = store.JobLogLines
+
+// sseKeepAlive is how often an idle event stream receives a comment, so proxies
+// keep it open. This is a variable so it can be modified in tests.
+var sseKeepAlive = 15 * time.Second
+
+// sseStartPoll is how often a stream checks whether its queued or scheduled job
+// has started. This is a variable so it can be modified in tests.
+var sseStartPoll = time.Second
+
+// HandleJobLogs returns the command output of a job from the offset query parameter.
+// The query is signed like a webhook. Clients accepting text/event-stream receive
+// the lines as Server-Sent Events, followed until the job is over.
+func (h *WebhookHandlerWithPool) HandleJobLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := h.authenticateQuery(w, r); !ok {
+		return
+	}
+
+	offset := 0
+	if value := r.URL.Query().Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_offset", "Offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	// Default to the current job, like HandleJobStatus
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		if current := h.jobStore.GetCurrentJob(); current != nil {
+			jobID = current.ID
+		}
+	}
+	job := h.jobStore.GetJob(jobID)
+	if jobID == "" || job == nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", "Job not found")
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.streamJobLog(w, r, job, offset)
+		return
+	}
+
+	status := job.GetStatus()
+	lines, next := []store.LogLine{}, offset
+	if jobLog := h.jobLogs.Get(job.ID); jobLog != nil {
+		var read []store.LogLine
+		if read, next = jobLog.Read(offset, jobLogPageSize); read != nil {
+			lines = read
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"job_id":      job.ID,
+		"status":      status,
+		"offset":      offset,
+		"next_offset": next,
+		"lines":       lines,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
+
+// streamJobLog sends the log lines of a job as Server-Sent Events until the job
+// is over and every line was sent, then sends an end event. Queued and scheduled
+// jobs are waited for. A Last-Event-ID header resumes the stream after that line.
+func (h *WebhookHandlerWithPool) streamJobLog(
+	w http.ResponseWriter, r *http.Request, job *entity.JobWithMutex, offset int,
+) {
+	if id, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && id >= 0 {
+		offset = id + 1
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream lasts as long as the job, beyond the server write timeout
+	_ = rc.SetWriteDeadline(time.Time{}) //nolint:errcheck // Not supported by every ResponseWriter
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// Send the headers now, a waiting job may not write anything for a while
+	if rc.Flush() != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		// Check for completion before reading, so no line appended meanwhile is missed
+		status := job.GetStatus()
+		jobLog := h.jobLogs.Get(job.ID)
+		done := status.IsFinal()
+		var changed <-chan struct{}
+		var started <-chan time.Time
+		if jobLog == nil {
+			// The log is created when the job starts
+			started = time.After(sseStartPoll)
+		} else {
+			done = done || jobLog.Closed()
+			changed = jobLog.Changed()
+
+			lines, next := jobLog.Read(offset, jobLogPageSize)
+			for _, line := range lines {
+				data, _ := json.Marshal(line) //nolint:errcheck // LogLine always encodes
+				_, _ = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", line.Offset, data)
+			}
+			offset = next
+			if len(lines) > 0 {
+				if rc.Flush() != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		if done {
+			data, _ := json.Marshal(map[string]interface{}{ //nolint:errcheck // Plain values always encode
+				"job_id":      job.ID,
+				"status":      job.GetStatus(),
+				"next_offset": offset,
+			})
+			_, _ = fmt.Fprintf(w, "event: end\ndata: %s\n\n", data)
+			_ = rc.Flush() //nolint:errcheck // The stream ends either way
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-started:
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			if rc.Flush() != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeJSONError writes a JSON error response with the given status code.
+func writeJSONError(w http.ResponseWriter, code int, status, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":  status,
+		"message": message,
+	}) //nolint:errcheck // Error already sent to client, ignore JSON encode errors
+}
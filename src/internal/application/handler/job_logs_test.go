@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+// outputActionService writes lines to the job output, like a running command.
+type outputActionService struct {
+	lines []string
+}
+
+func (m *outputActionService) ProcessAction(
+	_ context.Context, req entity.WebhookRequest, _ string, out system.OutputSink,
+) *entity.ActionResult {
+	for _, line := range m.lines {
+		out(system.Stdout, line)
+	}
+	return entity.NewActionResult(req.Action)
+}
+
+func newJobLogsHandler(t *testing.T) *WebhookHandlerWithPool {
+	t.Helper()
+	pool := worker.NewPool(1, 1)
+	t.Cleanup(func() { _ = pool.Shutdown(time.Second) })
+	return NewWebhookHandlerWithPool(&outputActionService{lines: []string{"one", "two", "three"}},
+		&mockAuthenticatorPool{shouldValidate: true}, pool)
+}
+
+// withTimestamp adds a current timestamp to the query of url, as signed requests need.
+func withTimestamp(url string) string {
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%stimestamp=%d", url, separator, time.Now().Unix())
+}
+
+type jobLogsResponse struct {
+	JobID      string `json:"job_id"`
+	Status     string `json:"status"`
+	NextOffset int    `json:"next_offset"`
+	Lines      []struct {
+		Offset int    `json:"offset"`
+		Stream string `json:"stream"`
+		Line   string `json:"line"`
+	} `json:"lines"`
+}
+
+func TestWebhookHandlerWithPool_HandleJobLogs(t *testing.T) {
+	handler := newJobLogsHandler(t)
+	job := entity.NewJob("job-logs", entity.ActionUpdate)
+	handler.jobStore.TryStartJob(job)
+	handler.jobLogs.Create(job.ID)
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionUpdate}, job)
+
+	rr := httptest.NewRecorder()
+	handler.HandleJobLogs(rr, httptest.NewRequest(http.MethodGet, withTimestamp("/job/logs?job_id=job-logs&offset=1"), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+
+	var response jobLogsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Status != string(entity.JobStatusCompleted) || response.NextOffset != 3 {
+		t.Errorf("status = %s, next_offset = %d", response.Status, response.NextOffset)
+	}
+	if len(response.Lines) != 2 || response.Lines[0].Line != "two" || response.Lines[0].Stream != "stdout" {
+		t.Errorf("lines = %+v, want the lines from offset 1", response.Lines)
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobLogs_Errors(t *testing.T) {
+	handler := newJobLogsHandler(t)
+	handler.jobStore.TryStartJob(entity.NewJob("job-logs", entity.ActionUpdate))
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"invalid method", http.MethodPost, "/job/logs", http.StatusMethodNotAllowed},
+		{"invalid offset", http.MethodGet, "/job/logs?offset=-1", http.StatusBadRequest},
+		{"unknown job", http.MethodGet, "/job/logs?job_id=unknown", http.StatusNotFound},
+		{"expired request", http.MethodGet, "/job/logs?timestamp=1", http.StatusBadRequest},
+		{"current job without output", http.MethodGet, "/job/logs", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.HandleJobLogs(rr, httptest.NewRequest(tt.method, withTimestamp(tt.url), nil))
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d", rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobLogs_Stream(t *testing.T) {
+	handler := newJobLogsHandler(t)
+	job := entity.NewJob("job-stream", entity.ActionUpdate)
+	handler.jobStore.TryStartJob(job)
+	jobLog := handler.jobLogs.Create(job.ID)
+	jobLog.Append("stdout", "before")
+
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleJobLogs))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, withTimestamp(server.URL+"?job_id=job-stream"), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	events := make(chan string, 10)
+	go func() {
+		defer close(events)
+		var event strings.Builder
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				events <- event.String()
+				event.Reset()
+				continue
+			}
+			event.WriteString(scanner.Text() + "\n")
+		}
+	}()
+	next := func() string {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return ""
+		}
+	}
+
+	if event := next(); !strings.HasPrefix(event, "id: 0\n") || !strings.Contains(event, `"line":"before"`) {
+		t.Errorf("first event = %q", event)
+	}
+
+	// Lines written while the job runs are streamed, then the end of the job
+	jobLog.Append("stderr", "after")
+	if event := next(); !strings.HasPrefix(event, "id: 1\n") || !strings.Contains(event, `"stream":"stderr"`) {
+		t.Errorf("second event = %q", event)
+	}
+
+	handler.jobStore.CompleteCurrentJob()
+	_ = jobLog.Close()
+	if event := next(); !strings.HasPrefix(event, "event: end\n") || !strings.Contains(event, `"status":"completed"`) {
+		t.Errorf("end event = %q", event)
+	}
+	if _, open := <-events; open {
+		t.Error("expected the stream to end after the end event")
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobLogs_Signed(t *testing.T) {
+	key := security.HMACKey{ID: security.DefaultKeyID, Secret: "test-secret-with-at-least-32-characters"}
+	auth, err := security.NewHMACAuthenticator(key.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := worker.NewPool(1, 1)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{}, auth, pool)
+	handler.jobStore.TryStartJob(entity.NewJob("job-logs", entity.ActionUpdate))
+
+	query := fmt.Sprintf("job_id=job-logs&timestamp=%d", time.Now().Unix())
+	tests := []struct {
+		name      string
+		query     string
+		signature string
+		want      int
+	}{
+		{"unsigned", query, "", http.StatusUnauthorized},
+		{"signed", query, key.Sign([]byte(query)), http.StatusOK},
+		{"other job", strings.Replace(query, "job-logs", "job-other", 1), key.Sign([]byte(query)),
+			http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/job/logs?"+tt.query, nil)
+			if tt.signature != "" {
+				req.Header.Set(security.SignatureHeader, tt.signature)
+			}
+			rr := httptest.NewRecorder()
+			handler.HandleJobLogs(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d", rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerWithPool_HandleJobLogs_StreamWaitsForStart(t *testing.T) {
+	originalPoll := sseStartPoll
+	sseStartPoll = 10 * time.Millisecond
+	defer func() { sseStartPoll = originalPoll }()
+
+	handler := newJobLogsHandler(t)
+	job := entity.NewJob("job-scheduled", entity.ActionUpdate)
+	handler.jobStore.ScheduleJob(job, time.Now().Add(time.Hour), entity.WebhookRequest{Action: entity.ActionUpdate})
+
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleJobLogs))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, withTimestamp(server.URL+"?job_id=job-scheduled"), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// The stream stays open until the job starts and writes its output
+	body := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case data := <-body:
+		t.Fatalf("stream ended before the job started: %q", data)
+	default:
+	}
+
+	if !handler.jobStore.StartScheduledJob(job.ID) {
+		t.Fatal("Failed to start the scheduled job")
+	}
+	jobLog := handler.jobLogs.Create(job.ID)
+	jobLog.Append("stdout", "started")
+	handler.jobStore.CompleteCurrentJob()
+	_ = jobLog.Close()
+
+	select {
+	case data := <-body:
+		if !strings.Contains(data, `"line":"started"`) || !strings.Contains(data, "event: end\n") {
+			t.Errorf("stream = %q, want the job output and its end", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the end of the stream")
+	}
+}
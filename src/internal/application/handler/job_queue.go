@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// queueJob queues a job behind the running one. A request doing the same work as
+// an already queued job, down to its callback, joins that job instead.
+func (h *WebhookHandlerWithPool) queueJob(w http.ResponseWriter, job *entity.JobWithMutex, req entity.WebhookRequest) {
+	h.queueMu.Lock()
+	queued := h.jobStore.EnqueueJob(job, queueKey(req), h.queueSize)
+	if queued == job {
+		h.queued[job.ID] = req
+	}
+	h.queueMu.Unlock()
+
+	if queued == nil {
+		logger.WithField("action", req.Action).Warn("Job queue is full")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status":  "queue_full",
+			"message": "Too many jobs are waiting, try again later",
+		}) //nolint:errcheck // Error already sent to client, ignore JSON encode errors
+		return
+	}
+
+	coalesced := queued != job
+	if coalesced && job.ClientIdentity != queued.ClientIdentity {
+		// The job keeps the identity of the client that queued it
+		logger.WithField("job_id", queued.ID).
+			WithField("client_identity", job.ClientIdentity).
+			WithField("job_client_identity", queued.ClientIdentity).
+			Warn("Request joined a job queued by another client")
+	}
+	logger.WithField("job_id", queued.ID).
+		WithField("action", req.Action).
+		WithField("client_identity", job.ClientIdentity).
+		WithField("coalesced", coalesced).
+		Info("Queued webhook action")
+
+	// The running job may have finished meanwhile
+	h.startNext()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Job-ID", queued.ID)
+	w.WriteHeader(http.StatusAccepted)
+
+	response := map[string]interface{}{
+		"status":    queued.GetStatus(),
+		"job_id":    queued.ID,
+		"action":    req.Action,
+		"coalesced": coalesced,
+	}
+	if position := h.jobStore.QueuePosition(queued.ID); position > 0 {
+		response["queue_position"] = position
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
+
+// startNext runs the oldest queued job when no job is running. Once the worker
+// pool is shut down, queued jobs stay pending for the next start to reconcile.
+func (h *WebhookHandlerWithPool) startNext() {
+	for !h.workerPool.IsShutdown() {
+		h.queueMu.Lock()
+		job := h.jobStore.StartNextJob()
+		var req entity.WebhookRequest
+		var ok bool
+		if job != nil {
+			req, ok = h.queued[job.ID]
+			delete(h.queued, job.ID)
+		}
+		h.queueMu.Unlock()
+
+		switch {
+		case job == nil:
+			return
+		case !ok:
+			h.jobStore.FailCurrentJob(errors.New("queued request not found"))
+			recordOutcome(h.jobStore, job.Snapshot())
+		default:
+			logger.WithField("job_id", job.ID).WithField("action", req.Action).Info("Starting queued webhook action")
+			if h.submit(job, req) == nil {
+				return
+			}
+		}
+	}
+}
+
+// cancelQueued removes a job from the queue. It returns false when the job is not queued.
+func (h *WebhookHandlerWithPool) cancelQueued(jobID string) bool {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+
+	if !h.jobStore.CancelPendingJob(jobID) {
+		return false
+	}
+	delete(h.queued, jobID)
+	return true
+}
+
+// queueKey identifies the queued requests doing the same work and reporting it
+// to the same callback.
+func queueKey(req entity.WebhookRequest) string {
+	config, _ := json.Marshal(req.Config) //nolint:errcheck // String maps always encode, with sorted keys
+	return strings.Join([]string{string(req.Action), req.Module, string(config), req.CallbackURL}, "\x00")
+}
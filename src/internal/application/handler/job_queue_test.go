@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+// gatedActionService runs each action until it is released, recording the order.
+type gatedActionService struct {
+	mu      sync.Mutex
+	ran     []string
+	release chan struct{}
+}
+
+func (m *gatedActionService) ProcessAction(
+	_ context.Context, req entity.WebhookRequest, jobID string, _ system.OutputSink,
+) *entity.ActionResult {
+	m.mu.Lock()
+	m.ran = append(m.ran, jobID)
+	m.mu.Unlock()
+	<-m.release
+	return entity.NewActionResult(req.Action)
+}
+
+func (m *gatedActionService) order() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.ran...)
+}
+
+func postWebhook(handler *WebhookHandlerWithPool, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	rr := httptest.NewRecorder()
+	handler.HandleWebhook(rr, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+	var response map[string]interface{}
+	_ = json.Unmarshal(rr.Body.Bytes(), &response)
+	return rr, response
+}
+
+func waitForStatus(t *testing.T, job *entity.JobWithMutex, status entity.JobStatus) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for job.GetStatus() != status {
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", job.ID, job.GetStatus(), status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookHandlerWithPool_Queue(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	pool := worker.NewPool(2, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithOptions(action, &mockAuthenticatorPool{shouldValidate: true}, pool,
+		WebhookHandlerOptions{QueueSize: 2, AllowedActions: entity.NewActionSet(entity.AllActions()...)})
+
+	now := time.Now().Unix()
+	update := fmt.Sprintf(`{"action":"update","timestamp":%d}`, now)
+	restart := func(module string) string {
+		return fmt.Sprintf(`{"action":"restart","module":%q,"timestamp":%d}`, module, now)
+	}
+
+	rr, running := postWebhook(handler, update)
+	if rr.Code != http.StatusAccepted || running["status"] != "accepted" {
+		t.Fatalf("first webhook = %d %v", rr.Code, running)
+	}
+
+	rr, queued := postWebhook(handler, update)
+	if rr.Code != http.StatusAccepted || queued["status"] != "pending" || queued["queue_position"] != 1.0 {
+		t.Fatalf("second webhook = %d %v, want pending at position 1", rr.Code, queued)
+	}
+
+	// The same action joins the queued job, another module is a new job
+	_, coalesced := postWebhook(handler, update)
+	if coalesced["job_id"] != queued["job_id"] || coalesced["coalesced"] != true {
+		t.Errorf("duplicate webhook = %v, want coalesced into %v", coalesced, queued["job_id"])
+	}
+	rr, nginx := postWebhook(handler, restart("nginx"))
+	if rr.Code != http.StatusAccepted || nginx["queue_position"] != 2.0 {
+		t.Errorf("restart webhook = %d %v, want position 2", rr.Code, nginx)
+	}
+	if rr, _ := postWebhook(handler, restart("redis")); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("webhook on a full queue = %d, want 503", rr.Code)
+	}
+
+	// The status reports the queue position
+	rr = httptest.NewRecorder()
+	handler.HandleJobStatus(rr, httptest.NewRequest(http.MethodGet, withTimestamp("/job/status?job_id="+nginx["job_id"].(string)), nil))
+	var status map[string]interface{}
+	_ = json.Unmarshal(rr.Body.Bytes(), &status)
+	if status["status"] != "pending" || status["queue_position"] != 2.0 {
+		t.Errorf("queued job status = %v", status)
+	}
+
+	// Jobs run one after the other, in order
+	close(action.release)
+	waitForStatus(t, handler.jobStore.GetJob(nginx["job_id"].(string)), entity.JobStatusCompleted)
+	want := []string{running["job_id"].(string), queued["job_id"].(string), nginx["job_id"].(string)}
+	if got := action.order(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("jobs ran in order %v, want %v", got, want)
+	}
+}
+
+func TestWebhookHandlerWithPool_QueueCallbacks(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	pool := worker.NewPool(2, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithOptions(action, &mockAuthenticatorPool{shouldValidate: true}, pool,
+		WebhookHandlerOptions{QueueSize: 5})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer receiver.Close()
+	notifier, err := callback.New(callback.Config{
+		KeyID: "callback", Secret: "test-callback-secret-with-32-chars!",
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	callback.SetDefault(notifier)
+	defer func() {
+		callback.SetDefault(nil)
+		notifier.Close()
+	}()
+	defer close(action.release)
+
+	now := time.Now().Unix()
+	update := func(callbackURL string) string {
+		return fmt.Sprintf(`{"action":"update","timestamp":%d,"callback_url":%q}`, now, callbackURL)
+	}
+
+	if rr, _ := postWebhook(handler, update("")); rr.Code != http.StatusAccepted {
+		t.Fatalf("first webhook = %d", rr.Code)
+	}
+	_, first := postWebhook(handler, update(receiver.URL+"/a"))
+	_, second := postWebhook(handler, update(receiver.URL+"/b"))
+	_, again := postWebhook(handler, update(receiver.URL+"/a"))
+
+	// Each callback receives the record of its own job
+	if second["job_id"] == first["job_id"] || second["coalesced"] != false {
+		t.Errorf("webhook with another callback = %v, want its own job", second)
+	}
+	if again["job_id"] != first["job_id"] || again["coalesced"] != true {
+		t.Errorf("webhook with the same callback = %v, want coalesced into %v", again, first["job_id"])
+	}
+	for jobID, want := range map[string]string{
+		first["job_id"].(string):  receiver.URL + "/a",
+		second["job_id"].(string): receiver.URL + "/b",
+	} {
+		if got := handler.jobStore.GetJob(jobID).Snapshot().CallbackURL; got != want {
+			t.Errorf("job %s callback = %q, want %q", jobID, got, want)
+		}
+	}
+}
+
+func TestQueueKey(t *testing.T) {
+	base := entity.WebhookRequest{Action: entity.ActionRestart, Module: "nginx", Config: map[string]string{"a": "1", "b": "2"}}
+	same := base
+	same.Config = map[string]string{"b": "2", "a": "1"}
+	same.Timestamp = 42
+	if queueKey(base) != queueKey(same) {
+		t.Error("requests differing only by their signature fields should share a key")
+	}
+
+	for name, modify := range map[string]func(*entity.WebhookRequest){
+		"module":   func(r *entity.WebhookRequest) { r.Module = "redis" },
+		"config":   func(r *entity.WebhookRequest) { r.Config = map[string]string{"a": "1"} },
+		"callback": func(r *entity.WebhookRequest) { r.CallbackURL = "https://ci.example.com/hooks" },
+	} {
+		other := base
+		modify(&other)
+		if queueKey(base) == queueKey(other) {
+			t.Errorf("requests with another %s share a key", name)
+		}
+	}
+}
+
+func TestWebhookHandlerWithPool_QueueCancel(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	pool := worker.NewPool(2, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithOptions(action, &mockAuthenticatorPool{shouldValidate: true}, pool,
+		WebhookHandlerOptions{QueueSize: 1})
+
+	body := fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix())
+	_, running := postWebhook(handler, body)
+	_, queued := postWebhook(handler, body)
+	jobID := queued["job_id"].(string)
+
+	rr := httptest.NewRecorder()
+	handler.HandleJobCancel(rr, cancelRequest(jobID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, want 200", rr.Code)
+	}
+	if status := handler.jobStore.GetJob(jobID).GetStatus(); status != entity.JobStatusCancelled {
+		t.Errorf("queued job is %s, want cancelled", status)
+	}
+
+	close(action.release)
+	waitForStatus(t, handler.jobStore.GetJob(running["job_id"].(string)), entity.JobStatusCompleted)
+	if got := action.order(); len(got) != 1 {
+		t.Errorf("jobs ran = %v, the cancelled job should not run", got)
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// scheduleInterval is how often scheduled jobs are checked. This is a variable
+// so it can be modified in tests.
+var scheduleInterval = 15 * time.Second
+
+// maxScheduleAhead bounds how far in the future run_at may be.
+const maxScheduleAhead = 90 * 24 * time.Hour
+
+// runAt returns when a request must run: its run_at, delayed to the next
+// maintenance window for restricted actions. It returns the zero time to run now.
+func (h *WebhookHandlerWithPool) runAt(req entity.WebhookRequest, now time.Time) time.Time {
+	at := now
+	if req.RunAt != nil && req.RunAt.After(now) {
+		at = *req.RunAt
+	}
+	if h.maintenance.Restricts(req.Action) {
+		at = h.maintenance.Next(at)
+	}
+	if !at.After(now) {
+		return time.Time{}
+	}
+	return at
+}
+
+// scheduleJob keeps a job until its run time.
+func (h *WebhookHandlerWithPool) scheduleJob(
+	w http.ResponseWriter, job *entity.JobWithMutex, req entity.WebhookRequest, runAt time.Time,
+) {
+	h.jobStore.ScheduleJob(job, runAt, req)
+
+	logger.WithField("job_id", job.ID).
+		WithField("action", req.Action).
+		WithField("client_identity", job.ClientIdentity).
+		WithField("run_at", runAt).
+		Info("Scheduled webhook action")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Job-ID", job.ID)
+	w.WriteHeader(http.StatusAccepted)
+
+	response := map[string]interface{}{
+		"status": entity.JobStatusScheduled,
+		"job_id": job.ID,
+		"action": req.Action,
+		"run_at": runAt,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
+
+// RunScheduled starts the scheduled jobs once their run time is reached.
+func (h *WebhookHandlerWithPool) RunScheduled() {
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		h.runDueJobs(now)
+	}
+}
+
+// runDueJobs starts the next scheduled job due at now. A job waits for the
+// running and queued jobs, and for a maintenance window when its action is restricted.
+func (h *WebhookHandlerWithPool) runDueJobs(now time.Time) {
+	for _, job := range h.jobStore.ScheduledJobs() {
+		snapshot := job.Snapshot()
+		if snapshot.RunAt.After(now) {
+			return
+		}
+
+		// The window may have closed while the job waited for another one
+		if h.maintenance.Restricts(job.Action) {
+			if next := h.maintenance.Next(now); !next.Equal(now) {
+				logger.WithField("job_id", job.ID).WithField("run_at", next).
+					Info("Maintenance window closed, job postponed")
+				h.jobStore.RescheduleJob(job.ID, next)
+				continue
+			}
+		}
+
+		if !h.jobStore.StartScheduledJob(job.ID) {
+			return
+		}
+		logger.WithField("job_id", job.ID).WithField("action", job.Action).Info("Starting scheduled webhook action")
+		if h.submit(job, *snapshot.Request) == nil {
+			return
+		}
+	}
+}
+
+// HandleScheduledJobs lists the jobs waiting for their run time, the next to run first.
+// The query is signed like a webhook, as the list names modules and clients.
+func (h *WebhookHandlerWithPool) HandleScheduledJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := h.authenticateQuery(w, r); !ok {
+		return
+	}
+
+	jobs := make([]map[string]interface{}, 0)
+	for _, job := range h.jobStore.ScheduledJobs() {
+		snapshot := job.Snapshot()
+		entry := map[string]interface{}{
+			"job_id": snapshot.ID,
+			"action": snapshot.Action,
+			"run_at": snapshot.RunAt,
+		}
+		if snapshot.Request != nil && snapshot.Request.Module != "" {
+			entry["module"] = snapshot.Request.Module
+		}
+		if snapshot.ClientIdentity != "" {
+			entry["client_identity"] = snapshot.ClientIdentity
+		}
+		jobs = append(jobs, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"jobs": jobs}); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
+)
+
+func TestWebhookHandlerWithPool_RunAt(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	close(action.release)
+	pool := worker.NewPool(1, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	auth := &mockAuthenticatorPool{shouldValidate: true}
+	handler := NewWebhookHandlerWithOptions(action, auth, pool, WebhookHandlerOptions{
+		AllowedActions: entity.NewActionSet(entity.AllActions()...),
+	})
+
+	runAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	rr, response := postWebhook(handler, fmt.Sprintf(
+		`{"action":"restart","module":"nginx","timestamp":%d,"run_at":%q}`, time.Now().Unix(), runAt.Format(time.RFC3339)))
+	if rr.Code != http.StatusAccepted || response["status"] != "scheduled" ||
+		response["run_at"] != runAt.Format(time.RFC3339) {
+		t.Fatalf("scheduled webhook = %d %v", rr.Code, response)
+	}
+	jobID := rr.Header().Get("X-Job-ID")
+
+	// Scheduled jobs do not block other webhooks
+	rr, _ = postWebhook(handler, fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix()))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("immediate webhook = %d, want 202", rr.Code)
+	}
+	waitForStatus(t, handler.jobStore.GetCurrentJob(), entity.JobStatusCompleted)
+
+	rr = httptest.NewRecorder()
+	handler.HandleScheduledJobs(rr, httptest.NewRequest(http.MethodGet, withTimestamp("/job/scheduled"), nil))
+	var list struct {
+		Jobs []map[string]interface{} `json:"jobs"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Jobs) != 1 ||
+		list.Jobs[0]["job_id"] != jobID || list.Jobs[0]["module"] != "nginx" {
+		t.Fatalf("scheduled jobs = %s", rr.Body.String())
+	}
+
+	// Listing scheduled jobs requires a signed request
+	auth.shouldValidate = false
+	rr = httptest.NewRecorder()
+	handler.HandleScheduledJobs(rr, httptest.NewRequest(http.MethodGet, withTimestamp("/job/scheduled"), nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned scheduled jobs request = %d, want 401", rr.Code)
+	}
+	auth.shouldValidate = true
+
+	handler.runDueJobs(runAt.Add(-time.Second))
+	job := handler.jobStore.GetJob(jobID)
+	if job.GetStatus() != entity.JobStatusScheduled {
+		t.Fatalf("job started before its run time: %s", job.GetStatus())
+	}
+	handler.runDueJobs(runAt)
+	waitForStatus(t, job, entity.JobStatusCompleted)
+	if len(handler.jobStore.ScheduledJobs()) != 0 {
+		t.Error("completed job is still scheduled")
+	}
+
+	// Past run times run now, distant ones are rejected
+	rr, _ = postWebhook(handler, fmt.Sprintf(`{"action":"update","timestamp":%d,"run_at":%q}`,
+		time.Now().Unix(), time.Now().Add(-time.Hour).Format(time.RFC3339)))
+	if rr.Code != http.StatusAccepted ||
+		handler.jobStore.GetJob(rr.Header().Get("X-Job-ID")).GetStatus() == entity.JobStatusScheduled {
+		t.Errorf("past run_at = %d, want the job to start now", rr.Code)
+	}
+	rr, _ = postWebhook(handler, fmt.Sprintf(`{"action":"update","timestamp":%d,"run_at":%q}`,
+		time.Now().Unix(), time.Now().Add(2*maxScheduleAhead).Format(time.RFC3339)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("distant run_at = %d, want 400", rr.Code)
+	}
+}
+
+func TestWebhookHandlerWithPool_MaintenanceWindow(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	close(action.release)
+	pool := worker.NewPool(1, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+
+	// A daily one hour window opening in two hours
+	now := time.Now().UTC()
+	midnight := now.Truncate(24 * time.Hour)
+	start := now.Add(2*time.Hour).Sub(midnight).Truncate(time.Minute) % (24 * time.Hour)
+	window, err := entity.ParseMaintenanceWindow(fmt.Sprintf("%02d:%02d-%02d:%02d",
+		start/time.Hour, start%time.Hour/time.Minute, (start/time.Hour+1)%24, start%time.Hour/time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := entity.MaintenancePolicy{
+		Windows: []entity.MaintenanceWindow{window},
+		Actions: entity.NewActionSet(entity.ActionReboot),
+	}
+	handler := NewWebhookHandlerWithOptions(action, &mockAuthenticatorPool{shouldValidate: true}, pool,
+		WebhookHandlerOptions{Maintenance: policy, AllowedActions: entity.NewActionSet(entity.AllActions()...)})
+
+	rr, response := postWebhook(handler, fmt.Sprintf(`{"action":"reboot","timestamp":%d}`, time.Now().Unix()))
+	if rr.Code != http.StatusAccepted || response["status"] != "scheduled" {
+		t.Fatalf("reboot webhook = %d %v", rr.Code, response)
+	}
+	job := handler.jobStore.GetJob(rr.Header().Get("X-Job-ID"))
+	opens := *job.Snapshot().RunAt
+	if !opens.Equal(policy.Next(now)) {
+		t.Errorf("run_at = %v, want the window opening at %v", opens, policy.Next(now))
+	}
+
+	// Unrestricted actions run now
+	rr, response = postWebhook(handler, fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix()))
+	if rr.Code != http.StatusAccepted || response["status"] != "accepted" {
+		t.Fatalf("update webhook = %d %v", rr.Code, response)
+	}
+	waitForStatus(t, handler.jobStore.GetCurrentJob(), entity.JobStatusCompleted)
+
+	// A job that missed its window waits for the next one
+	handler.runDueJobs(opens.Add(90 * time.Minute))
+	if job.GetStatus() != entity.JobStatusScheduled || !job.Snapshot().RunAt.Equal(opens.Add(24*time.Hour)) {
+		t.Fatalf("job after the window = %s, run_at %v", job.GetStatus(), job.Snapshot().RunAt)
+	}
+
+	handler.runDueJobs(opens.Add(24*time.Hour + time.Minute))
+	waitForStatus(t, job, entity.JobStatusCompleted)
+}
+
+func TestWebhookHandlerWithPool_CancelScheduledJob(t *testing.T) {
+	action := &gatedActionService{release: make(chan struct{})}
+	pool := worker.NewPool(1, 10)
+	defer func() { _ = pool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithOptions(action, &mockAuthenticatorPool{shouldValidate: true}, pool,
+		WebhookHandlerOptions{AllowedActions: entity.NewActionSet(entity.AllActions()...)})
+
+	runAt := time.Now().Add(time.Hour)
+	rr, _ := postWebhook(handler, fmt.Sprintf(`{"action":"upgrade","timestamp":%d,"run_at":%q}`,
+		time.Now().Unix(), runAt.Format(time.RFC3339)))
+	jobID := rr.Header().Get("X-Job-ID")
+
+	rr = httptest.NewRecorder()
+	handler.HandleJobCancel(rr, cancelRequest(jobID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	if job := handler.jobStore.GetJob(jobID); job.GetStatus() != entity.JobStatusCancelled {
+		t.Errorf("job status = %s, want cancelled", job.GetStatus())
+	}
+
+	handler.runDueJobs(runAt.Add(time.Minute))
+	if len(action.order()) != 0 {
+		t.Errorf("cancelled job ran: %v", action.order())
+	}
+}
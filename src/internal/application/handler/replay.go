@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+)
+
+// checkFreshness rejects expired and future-dated requests. It returns the error
+// message for the client, or "" when the request timestamp is acceptable.
+func checkFreshness(timestamp int64, now time.Time) string {
+	requestTime := time.Unix(timestamp, 0)
+	switch {
+	case now.Sub(requestTime) > security.MaxRequestAge:
+		return "Request expired"
+	case requestTime.Sub(now) > security.MaxClockSkew:
+		return "Request timestamp is in the future"
+	default:
+		return ""
+	}
+}
+
+// checkNonce records the request nonce and rejects replays. It must only be called
+// for authenticated requests. It returns the error message for the client, or "".
+func checkNonce(nonces *security.NonceCache, requireNonce bool, nonce string) string {
+	switch {
+	case nonce == "" && requireNonce:
+		return "Nonce required"
+	case nonce == "":
+		return ""
+	case len(nonce) > security.MaxNonceLength:
+		return "Invalid nonce"
+	case !nonces.Add(nonce):
+		return "Duplicate request"
+	default:
+		return ""
+	}
+}
+
+// releaseNonce forgets the nonce of a request that was not accepted, so that a
+// request rejected for a passing reason, such as a running job, can be sent again.
+func releaseNonce(nonces *security.NonceCache, nonce string, status int) {
+	if nonce != "" && status != http.StatusAccepted {
+		nonces.Remove(nonce)
+	}
+}
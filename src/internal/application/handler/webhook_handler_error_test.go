@@ -164,7 +164,7 @@ func TestWebhookHandlerWithPool_HandleJobStatus_ErrorWriting(t *testing.T) {
 	handler := NewWebhookHandlerWithPool(mockAction, mockAuth, pool)
 
 	// Test with a writer that fails
-	req := httptest.NewRequest(http.MethodGet, "/job/status", nil)
+	req := httptest.NewRequest(http.MethodGet, withTimestamp("/job/status"), nil)
 
 	// Custom ResponseWriter that fails on Write
 	rr := &failingResponseWriter{
@@ -4,38 +4,120 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
 	"github.com/kodflow/cloud-update/src/internal/domain/service"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/httputil"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/metrics"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
 )
 
+// rebootTimeout bounds how long a reboot job may stay running before the reboot
+// is considered to have failed. This is a variable so it can be modified in tests.
+var rebootTimeout = 10 * time.Minute
+
 // WebhookHandlerWithPool handles webhook requests with worker pool and job status tracking.
 type WebhookHandlerWithPool struct {
-	actionService service.ActionService
-	authenticator security.Authenticator
-	jobStore      *store.JobStore
-	workerPool    *worker.Pool
+	actionService  service.ActionService
+	authenticator  security.Authenticator
+	jobStore       store.JobStore
+	jobLogs        *store.JobLogs
+	runs           *jobRuns
+	queueSize      int
+	queueMu        sync.Mutex                       // Serializes queueing with starting queued jobs
+	queued         map[string]entity.WebhookRequest // Requests of the queued jobs
+	workerPool     *worker.Pool
+	jobRetention   time.Duration
+	allowedActions entity.ActionSet
+	nonces         *security.NonceCache
+	requireNonce   bool
+	maintenance    entity.MaintenancePolicy
 }
 
-// NewWebhookHandlerWithPool creates a new webhook handler with worker pool support.
+// WebhookHandlerOptions holds the optional settings of a WebhookHandlerWithPool.
+type WebhookHandlerOptions struct {
+	JobStore       store.JobStore   // Job storage (default: in-memory store)
+	JobRetention   time.Duration    // How long finished jobs are kept (default: 30 minutes)
+	JobLogs        *store.JobLogs   // Command output of jobs (default: in memory)
+	AllowedActions entity.ActionSet // Actions accepted by the webhook (default: update only)
+	// NonceCache remembers request nonces to reject replays (default: in-memory cache)
+	NonceCache *security.NonceCache
+	// RequireNonce rejects requests without a nonce
+	RequireNonce bool
+	// QueueSize is how many jobs may wait for the running job (default: 0, new jobs
+	// are rejected with 409 Conflict while a job runs)
+	QueueSize int
+	// Maintenance delays restricted actions to maintenance windows (default: no restriction)
+	Maintenance entity.MaintenancePolicy
+}
+
+// NewWebhookHandlerWithPool creates a new webhook handler with worker pool support
+// and default options.
 func NewWebhookHandlerWithPool(
 	actionService service.ActionService,
 	authenticator security.Authenticator,
 	workerPool *worker.Pool,
 ) *WebhookHandlerWithPool {
+	return NewWebhookHandlerWithOptions(actionService, authenticator, workerPool, WebhookHandlerOptions{})
+}
+
+// NewWebhookHandlerWithOptions creates a new webhook handler with worker pool support
+// configured by opts. Zero-valued options take their defaults.
+func NewWebhookHandlerWithOptions(
+	actionService service.ActionService,
+	authenticator security.Authenticator,
+	workerPool *worker.Pool,
+	opts WebhookHandlerOptions,
+) *WebhookHandlerWithPool {
+	if opts.JobStore == nil {
+		opts.JobStore = store.NewJobStore()
+	}
+	if opts.JobLogs == nil {
+		opts.JobLogs = store.NewMemoryJobLogs()
+	}
+	if opts.JobRetention <= 0 {
+		opts.JobRetention = 30 * time.Minute
+	}
+	if opts.AllowedActions == nil {
+		opts.AllowedActions = entity.NewActionSet(entity.ActionUpdate)
+	}
+	if opts.NonceCache == nil {
+		opts.NonceCache = security.NewNonceCache(security.DefaultNonceCacheSize)
+	}
+
 	return &WebhookHandlerWithPool{
-		actionService: actionService,
-		authenticator: authenticator,
-		jobStore:      store.NewJobStore(),
-		workerPool:    workerPool,
+		actionService:  actionService,
+		authenticator:  authenticator,
+		jobStore:       opts.JobStore,
+		jobLogs:        opts.JobLogs,
+		runs:           newJobRuns(),
+		queueSize:      opts.QueueSize,
+		queued:         make(map[string]entity.WebhookRequest),
+		workerPool:     workerPool,
+		jobRetention:   opts.JobRetention,
+		allowedActions: opts.AllowedActions,
+		nonces:         opts.NonceCache,
+		requireNonce:   opts.RequireNonce,
+		maintenance:    opts.Maintenance,
 	}
 }
 
@@ -48,6 +130,19 @@ func (h *WebhookHandlerWithPool) HandleWebhook(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Record the request, the key that signed it and the response in the audit log
+	var req entity.WebhookRequest
+	var keyID string
+	aw := httputil.NewStatusRecorder(w)
+	w = aw
+	defer func() {
+		var jobID string
+		if aw.Status() == http.StatusAccepted {
+			jobID = aw.Header().Get("X-Job-ID")
+		}
+		auditRequest(audit.EventRequest, r, aw, keyID, req.Action, req.Module, jobID)
+	}()
+
 	// Read request body
 	body, err := io.ReadAll(io.LimitReader(r.Body, 1024*1024)) // 1MB limit
 	if err != nil {
@@ -57,41 +152,61 @@ func (h *WebhookHandlerWithPool) HandleWebhook(w http.ResponseWriter, r *http.Re
 	}
 
 	// Parse webhook request
-	var req entity.WebhookRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		logger.WithField("error", err).Error("Failed to parse request")
 		http.Error(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
 
-	// Validate request timestamp (prevent replay attacks)
-	requestTime := time.Unix(req.Timestamp, 0)
-	if time.Since(requestTime) > 5*time.Minute {
-		logger.Warn("Request timestamp too old")
-		http.Error(w, "Request expired", http.StatusBadRequest)
+	// Check the timestamp, signature and nonce
+	var ok bool
+	if keyID, ok = h.authenticate(w, r, body, req.Timestamp, req.Nonce); !ok {
 		return
 	}
+	// Only an accepted request uses up its nonce
+	defer func() { releaseNonce(h.nonces, req.Nonce, aw.Status()) }()
 
-	// Authenticate request
-	if !h.authenticator.ValidateSignature(r, body) {
-		logger.Warn("Invalid webhook signature")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	// Validate action type against the configured allow-list
+	if !req.Action.IsValid() {
+		logger.WithField("action", req.Action).Warn("Invalid action type")
+		http.Error(w, "Invalid action", http.StatusBadRequest)
 		return
 	}
-
-	// Validate action type
-	validActions := map[entity.ActionType]bool{
-		entity.ActionUpdate: true,
+	if !h.allowedActions.Allows(req.Action) {
+		logger.WithField("action", req.Action).Warn("Action not allowed on this server")
+		http.Error(w, "Action not allowed", http.StatusForbidden)
+		return
 	}
-	if !validActions[req.Action] {
-		logger.WithField("action", req.Action).Warn("Invalid action type")
-		http.Error(w, "Invalid action", http.StatusBadRequest)
+	if req.Action.RequiresModule() && req.Module == "" {
+		logger.WithField("action", req.Action).Warn("Missing module for action")
+		http.Error(w, "Module required for action", http.StatusBadRequest)
 		return
 	}
+	if req.CallbackURL != "" {
+		if !callback.Enabled() {
+			logger.Warn("Callback requested but callbacks are not enabled")
+			http.Error(w, "Callbacks are not enabled", http.StatusBadRequest)
+			return
+		}
+		if err := callback.ValidateURL(req.CallbackURL); err != nil {
+			logger.WithField("error", err).Warn("Invalid callback URL")
+			http.Error(w, "Invalid callback URL", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Run later when requested, or when the action waits for a maintenance window
+	now := time.Now()
+	if req.RunAt != nil && req.RunAt.After(now.Add(maxScheduleAhead)) {
+		logger.WithField("run_at", req.RunAt).Warn("Run time too far in the future")
+		http.Error(w, "Run time too far in the future", http.StatusBadRequest)
+		return
+	}
+	runAt := h.runAt(req, now)
 
-	// Check if there's already a job running
+	// Check if there's already a job running, unless new jobs wait in the queue
 	currentJob := h.jobStore.GetCurrentJob()
-	if currentJob != nil && currentJob.IsRunning() {
+	if runAt.IsZero() && h.queueSize == 0 && currentJob != nil && currentJob.IsRunning() {
 		// Return 409 Conflict with job info
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Job-ID", currentJob.ID)
@@ -118,11 +233,27 @@ func (h *WebhookHandlerWithPool) HandleWebhook(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Create new job
+	// Create new job, recording the client certificate that requested it, the
+	// trace its spans join and where to report its outcome
 	job := entity.NewJob(jobID, req.Action)
+	job.ClientIdentity = security.ClientIdentity(r)
+	job.CallbackURL = req.CallbackURL
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(r.Context(), carrier)
+	job.TraceParent = carrier.Get("traceparent")
+
+	if !runAt.IsZero() {
+		h.scheduleJob(w, job, req, runAt)
+		return
+	}
 
-	// Try to start the job
-	if !h.jobStore.TryStartJob(job) {
+	// Try to start the job, or queue it behind the running one
+	started := h.jobStore.TryStartJob(job)
+	if !started && h.queueSize > 0 {
+		h.queueJob(w, job, req)
+		return
+	}
+	if !started {
 		w.WriteHeader(http.StatusConflict)
 		if err := json.NewEncoder(w).Encode(map[string]string{
 			"status":  "job_already_running",
@@ -136,16 +267,10 @@ func (h *WebhookHandlerWithPool) HandleWebhook(w http.ResponseWriter, r *http.Re
 	// Log the request
 	logger.WithField("job_id", jobID).
 		WithField("action", req.Action).
+		WithField("client_identity", job.ClientIdentity).
 		Info("Starting webhook action with worker pool")
 
-	// Submit job to worker pool instead of using goroutine
-	err = h.workerPool.Submit(func(ctx context.Context) {
-		h.processActionWithContext(ctx, req, job)
-	})
-
-	if err != nil {
-		logger.WithField("error", err).Error("Failed to submit job to worker pool")
-		h.jobStore.FailCurrentJob(err)
+	if err := h.submit(job, req); err != nil {
 		http.Error(w, "Server at capacity", http.StatusServiceUnavailable)
 		return
 	}
@@ -166,10 +291,109 @@ func (h *WebhookHandlerWithPool) HandleWebhook(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// submit runs a started job in the worker pool. The job fails if the pool rejects it.
+func (h *WebhookHandlerWithPool) submit(job *entity.JobWithMutex, req entity.WebhookRequest) error {
+	// Start the job log now, so its output can be followed before a worker picks it up,
+	// and accept cancel requests from now on
+	jobLog := h.jobLogs.Create(job.ID)
+	h.runs.add(job.ID)
+
+	// Submit job to worker pool instead of using goroutine
+	err := h.workerPool.Submit(func(ctx context.Context) {
+		h.processActionWithContext(ctx, req, job)
+	})
+	if err != nil {
+		logger.WithField("job_id", job.ID).WithField("error", err).Error("Failed to submit job to worker pool")
+		h.jobStore.FailCurrentJob(err)
+		recordOutcome(h.jobStore, job.Snapshot())
+		_ = jobLog.Close() //nolint:errcheck // The job never ran
+		h.runs.remove(job.ID)
+	}
+	return err
+}
+
+// authenticate checks the timestamp, signature and nonce of a signed request and
+// returns the ID of the key that signed it, when known. On failure it writes the
+// error response and returns false.
+func (h *WebhookHandlerWithPool) authenticate(
+	w http.ResponseWriter, r *http.Request, body []byte, timestamp int64, nonce string,
+) (string, bool) {
+	_, span := telemetry.Start(r.Context(), "authenticate")
+	defer span.End()
+
+	// Validate request timestamp (prevent replay attacks)
+	if msg := checkFreshness(timestamp, time.Now()); msg != "" {
+		logger.WithField("timestamp", timestamp).Warn("Request timestamp outside the accepted window")
+		metrics.AuthFailures.WithLabelValues(metrics.AuthReasonTimestamp).Inc()
+		span.SetStatus(codes.Error, metrics.AuthReasonTimestamp)
+		http.Error(w, msg, http.StatusBadRequest)
+		return "", false
+	}
+
+	// Authenticate request
+	keyID, ok := security.Authenticate(h.authenticator, r, body)
+	if !ok {
+		logger.Warn("Invalid webhook signature")
+		metrics.AuthFailures.WithLabelValues(metrics.AuthReasonSignature).Inc()
+		span.SetStatus(codes.Error, metrics.AuthReasonSignature)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+
+	// Reject replayed requests
+	if msg := checkNonce(h.nonces, h.requireNonce, nonce); msg != "" {
+		logger.WithField("nonce", nonce).Warn("Rejected webhook request: " + msg)
+		metrics.AuthFailures.WithLabelValues(metrics.AuthReasonNonce).Inc()
+		span.SetStatus(codes.Error, metrics.AuthReasonNonce)
+		http.Error(w, msg, http.StatusBadRequest)
+		return "", false
+	}
+	span.SetStatus(codes.Ok, "")
+	return keyID, true
+}
+
+// authenticateQuery authenticates a GET request like authenticate. The signature
+// covers the raw query string, which carries the timestamp and nonce parameters.
+func (h *WebhookHandlerWithPool) authenticateQuery(w http.ResponseWriter, r *http.Request) (string, bool) {
+	query := r.URL.Query()
+	timestamp, _ := strconv.ParseInt(query.Get("timestamp"), 10, 64) //nolint:errcheck // Rejected as expired
+	return h.authenticate(w, r, []byte(r.URL.RawQuery), timestamp, query.Get("nonce"))
+}
+
 // processActionWithContext processes an action with context support.
 func (h *WebhookHandlerWithPool) processActionWithContext(
 	ctx context.Context, req entity.WebhookRequest, job *entity.JobWithMutex,
 ) {
+	// Run the next queued job once this one is done
+	defer h.startNext()
+
+	ctx, done := h.runs.start(ctx, job.ID)
+	defer done()
+
+	jobLog := h.jobLog(job.ID)
+	defer func() {
+		if err := jobLog.Close(); err != nil {
+			logger.WithField("job_id", job.ID).WithField("error", err).Error("Failed to close job log")
+		}
+	}()
+
+	// Trace the job in the trace of the request that created it
+	ctx, span := telemetry.Start(
+		propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": job.TraceParent}),
+		"job "+string(req.Action),
+		attribute.String("job.id", job.ID),
+		attribute.String("job.action", string(req.Action)),
+	)
+	// Audit the commands of the job under its ID
+	ctx = audit.WithJobID(ctx, job.ID)
+
+	// Record the outcome once the job is marked complete or failed
+	defer func() {
+		snapshot := job.Snapshot()
+		recordOutcome(h.jobStore, snapshot)
+		endJobSpan(span, snapshot)
+	}()
+
 	// Ensure we mark the job as complete or failed when done
 	defer func() {
 		if r := recover(); r != nil {
@@ -184,6 +408,11 @@ func (h *WebhookHandlerWithPool) processActionWithContext(
 	// Check context cancellation
 	select {
 	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), store.ErrJobCancelled) {
+			logger.WithField("job_id", job.ID).Warn("Job cancelled before it started")
+			h.jobStore.CancelCurrentJob(nil)
+			return
+		}
 		err := fmt.Errorf("job canceled: %w", ctx.Err())
 		logger.WithField("job_id", job.ID).Error("Job canceled by context")
 		h.jobStore.FailCurrentJob(err)
@@ -191,24 +420,86 @@ func (h *WebhookHandlerWithPool) processActionWithContext(
 	default:
 	}
 
-	// Process the action
-	h.actionService.ProcessAction(req, job.ID)
+	// Process the action and record its outcome
+	result := h.actionService.ProcessAction(ctx, req, job.ID, jobOutput(job, jobLog))
+	if (result == nil || result.Err != nil) && errors.Is(context.Cause(ctx), store.ErrJobCancelled) {
+		logger.WithField("job_id", job.ID).WithField("action", req.Action).Warn("Job cancelled")
+		h.jobStore.CancelCurrentJob(result)
+		return
+	}
+	h.jobStore.FinishCurrentJob(result)
+
+	if result != nil && result.Err != nil {
+		logger.WithField("job_id", job.ID).
+			WithField("action", req.Action).
+			WithField("exit_code", result.ExitCode).
+			WithField("error", result.Err).
+			Error("Webhook action failed")
+		return
+	}
 
-	// Mark job as complete
-	h.jobStore.CompleteCurrentJob()
+	if result != nil && result.RebootPending {
+		logger.WithField("job_id", job.ID).Info("Reboot scheduled, job completes once the system is back up")
+		watchPendingReboot(h.jobStore, job, h.startNext)
+		return
+	}
 
 	logger.WithField("job_id", job.ID).
 		WithField("action", req.Action).
 		Info("Webhook action completed successfully")
 }
 
-// HandleJobStatus returns the status of a job.
+// endJobSpan records the outcome of a job on its span and ends it.
+func endJobSpan(span trace.Span, job entity.Job) {
+	span.SetAttributes(attribute.String("job.status", string(job.Status)))
+	if job.Result != nil {
+		span.SetAttributes(attribute.Int("job.exit_code", job.Result.ExitCode))
+	}
+	switch job.Status {
+	case entity.JobStatusFailed:
+		message := "job failed"
+		if job.Error != nil {
+			message = job.Error.Error()
+		}
+		span.SetStatus(codes.Error, message)
+	case entity.JobStatusCompleted:
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
+
+// HandleActions reports the actions this server accepts.
+func (h *WebhookHandlerWithPool) HandleActions(w http.ResponseWriter, r *http.Request) {
+	writeAllowedActions(w, r, h.allowedActions)
+}
+
+// writeAllowedActions writes the allow-list of actions as JSON.
+func writeAllowedActions(w http.ResponseWriter, r *http.Request, actions entity.ActionSet) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"allowed_actions": actions.List(),
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.WithField("error", err).Error("Failed to encode response")
+	}
+}
+
+// HandleJobStatus returns the status of a job to a signed request.
 func (h *WebhookHandlerWithPool) HandleJobStatus(w http.ResponseWriter, r *http.Request) {
 	// Only accept GET requests
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	// The status holds the output of the job steps and where it is reported
+	if _, ok := h.authenticateQuery(w, r); !ok {
+		return
+	}
 
 	// Get job ID from query parameter
 	jobID := r.URL.Query().Get("job_id")
@@ -239,20 +530,51 @@ func (h *WebhookHandlerWithPool) HandleJobStatus(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Return job status
+	// Return job status, read from one consistent copy of the job as workers update it
+	snapshot := job.Snapshot()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	response := map[string]interface{}{
-		"job_id":    job.ID,
-		"action":    job.Action,
-		"status":    job.Status,
-		"started":   job.StartTime,
-		"completed": job.EndTime,
+		"job_id":    snapshot.ID,
+		"action":    snapshot.Action,
+		"status":    snapshot.Status,
+		"started":   snapshot.StartTime,
+		"completed": snapshot.EndTime,
+	}
+
+	if snapshot.ClientIdentity != "" {
+		response["client_identity"] = snapshot.ClientIdentity
+	}
+
+	if snapshot.CallbackURL != "" {
+		response["callback_url"] = snapshot.CallbackURL
+	}
+	if len(snapshot.Callbacks) > 0 {
+		response["callbacks"] = snapshot.Callbacks
+	}
+
+	if position := h.jobStore.QueuePosition(snapshot.ID); position > 0 {
+		response["queue_position"] = position
+	}
+
+	if snapshot.RunAt != nil {
+		response["run_at"] = snapshot.RunAt
 	}
 
-	if job.Status == entity.JobStatusFailed && job.Error != nil {
-		response["error"] = job.Error.Error()
+	switch snapshot.Status {
+	case entity.JobStatusFailed, entity.JobStatusInterrupted, entity.JobStatusCancelled:
+		if snapshot.Error != nil {
+			response["error"] = snapshot.Error.Error()
+		}
+	}
+
+	if result := snapshot.Result; result != nil {
+		response["exit_code"] = result.ExitCode
+		response["steps"] = result.Steps
+		if result.RebootPending {
+			response["reboot_pending"] = true
+		}
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -260,12 +582,51 @@ func (h *WebhookHandlerWithPool) HandleJobStatus(w http.ResponseWriter, r *http.
 	}
 }
 
+// watchPendingReboot fails a job still waiting for its reboot after rebootTimeout,
+// then calls next when not nil. When the reboot happens the service stops first
+// and the job is completed by store reconciliation on the next start.
+func watchPendingReboot(jobStore store.JobStore, job *entity.JobWithMutex, next func()) {
+	timeout := rebootTimeout
+	time.AfterFunc(timeout, func() {
+		current := jobStore.GetCurrentJob()
+		if current == nil || current.ID != job.ID {
+			return
+		}
+		logger.WithField("job_id", job.ID).Error("System did not reboot within the expected time")
+		jobStore.FailCurrentJob(fmt.Errorf("system did not reboot within %v", timeout))
+		recordOutcome(jobStore, job.Snapshot())
+		if next != nil {
+			next()
+		}
+	})
+}
+
+// jobLog returns the open log of a job, starting it when needed.
+func (h *WebhookHandlerWithPool) jobLog(jobID string) *store.JobLog {
+	if jobLog := h.jobLogs.Get(jobID); jobLog != nil && !jobLog.Closed() {
+		return jobLog
+	}
+	return h.jobLogs.Create(jobID)
+}
+
+// jobOutput returns the sink receiving the command output of a job, appended to
+// jobLog when not nil.
+func jobOutput(job *entity.JobWithMutex, jobLog *store.JobLog) system.OutputSink {
+	return func(stream system.Stream, line string) {
+		if jobLog != nil {
+			jobLog.Append(string(stream), line)
+		}
+		logger.WithField("job_id", job.ID).WithField("stream", string(stream)).Debug(line)
+	}
+}
+
 // Cleanup removes old completed jobs periodically.
 func (h *WebhookHandlerWithPool) Cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		h.jobStore.CleanupOldJobs(30 * time.Minute)
+		h.jobStore.CleanupOldJobs(h.jobRetention)
+		h.jobLogs.CleanupOldLogs(h.jobRetention)
 	}
 }
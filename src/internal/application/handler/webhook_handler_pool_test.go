@@ -3,6 +3,9 @@ package handler
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,7 +15,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/metrics"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/worker"
 )
 
@@ -25,9 +39,12 @@ type mockActionServicePool struct {
 	lastRequest         entity.WebhookRequest
 	lastJobID           string
 	shouldPanic         bool
+	result              *entity.ActionResult
 }
 
-func (m *mockActionServicePool) ProcessAction(req entity.WebhookRequest, jobID string) {
+func (m *mockActionServicePool) ProcessAction(
+	_ context.Context, req entity.WebhookRequest, jobID string, _ system.OutputSink,
+) *entity.ActionResult {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.processActionCalled = true
@@ -36,6 +53,10 @@ func (m *mockActionServicePool) ProcessAction(req entity.WebhookRequest, jobID s
 	if m.shouldPanic {
 		panic("mock panic for testing")
 	}
+	if m.result != nil {
+		return m.result
+	}
+	return entity.NewActionResult(req.Action)
 }
 
 func (m *mockActionServicePool) wasProcessActionCalled() bool {
@@ -153,6 +174,27 @@ func TestWebhookHandlerWithPool_HandleWebhook_BasicTests(t *testing.T) {
 			authenticated:  true,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "restart without module",
+			method:         http.MethodPost,
+			body:           fmt.Sprintf(`{"action":"restart","timestamp":%d}`, currentTime),
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "restart with module",
+			method:         http.MethodPost,
+			body:           fmt.Sprintf(`{"action":"restart","module":"nginx","timestamp":%d}`, currentTime),
+			authenticated:  true,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "upgrade",
+			method:         http.MethodPost,
+			body:           fmt.Sprintf(`{"action":"upgrade","timestamp":%d}`, currentTime),
+			authenticated:  true,
+			expectedStatus: http.StatusAccepted,
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,7 +204,9 @@ func TestWebhookHandlerWithPool_HandleWebhook_BasicTests(t *testing.T) {
 			mockPool := worker.NewPool(2, 10)
 			defer func() { _ = mockPool.Shutdown(time.Second) }()
 
-			handler := NewWebhookHandlerWithPool(mockAction, mockAuth, mockPool)
+			handler := NewWebhookHandlerWithOptions(mockAction, mockAuth, mockPool, WebhookHandlerOptions{
+				AllowedActions: entity.NewActionSet(entity.AllActions()...),
+			})
 
 			var req *http.Request
 			if tt.body == "" {
@@ -191,6 +235,148 @@ func TestWebhookHandlerWithPool_HandleWebhook_BasicTests(t *testing.T) {
 	}
 }
 
+func TestWebhookHandlerWithPool_AllowedActions(t *testing.T) {
+	mockAction := &mockActionServicePool{}
+	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+
+	handler := NewWebhookHandlerWithOptions(mockAction, mockAuth, mockPool, WebhookHandlerOptions{
+		AllowedActions: entity.NewActionSet(entity.ActionUpdate, entity.ActionReinit),
+	})
+
+	body := fmt.Sprintf(`{"action":"reboot","timestamp":%d}`, time.Now().Unix())
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for disallowed action, got %d", http.StatusForbidden, rr.Code)
+	}
+	if mockAction.wasProcessActionCalled() {
+		t.Error("ProcessAction should not be called for a disallowed action")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/actions", http.NoBody)
+	rr = httptest.NewRecorder()
+	handler.HandleActions(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var response map[string][]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if got := strings.Join(response["allowed_actions"], ","); got != "reinit,update" {
+		t.Errorf("Expected allowed actions reinit,update, got %s", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/actions", http.NoBody)
+	rr = httptest.NewRecorder()
+	handler.HandleActions(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
+
+func TestWebhookHandlerWithPool_DefaultAllowedActions(t *testing.T) {
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{}, &mockAuthenticatorPool{shouldValidate: true}, mockPool)
+
+	if got := handler.allowedActions.List(); len(got) != 1 || got[0] != entity.ActionUpdate {
+		t.Errorf("allowed actions = %v, want update only", got)
+	}
+	body := fmt.Sprintf(`{"action":"reboot","timestamp":%d}`, time.Now().Unix())
+	rr := httptest.NewRecorder()
+	handler.HandleWebhook(rr, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for an action not allowed by default, got %d", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestWebhookHandlerWithPool_ReplayProtection(t *testing.T) {
+	mockAction := &mockActionServicePool{}
+	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+
+	// Reboot is not allowed so authenticated reboot requests stop at 403
+	handler := NewWebhookHandlerWithOptions(mockAction, mockAuth, mockPool, WebhookHandlerOptions{
+		AllowedActions: entity.NewActionSet(entity.ActionUpdate),
+		RequireNonce:   true,
+	})
+
+	now := time.Now().Unix()
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"first use of nonce", fmt.Sprintf(`{"action":"update","timestamp":%d,"nonce":"n1"}`, now), http.StatusAccepted, ""},
+		{"replayed nonce", fmt.Sprintf(`{"action":"update","timestamp":%d,"nonce":"n1"}`, now), http.StatusBadRequest, "Duplicate request"},
+		{"rejected request", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":"n5"}`, now), http.StatusForbidden, ""},
+		{"rejected request sent again", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":"n5"}`, now), http.StatusForbidden, ""},
+		{"missing nonce", fmt.Sprintf(`{"action":"reboot","timestamp":%d}`, now), http.StatusBadRequest, "Nonce required"},
+		{"oversized nonce", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":%q}`, now, strings.Repeat("n", 129)), http.StatusBadRequest, "Invalid nonce"},
+		{"future timestamp", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":"n2"}`, now+600), http.StatusBadRequest, "in the future"},
+		{"small clock skew", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":"n3"}`, now+10), http.StatusForbidden, ""},
+		{"expired timestamp", fmt.Sprintf(`{"action":"reboot","timestamp":%d,"nonce":"n4"}`, now-600), http.StatusBadRequest, "Request expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			handler.HandleWebhook(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want %q", rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerWithPool_ClientIdentity(t *testing.T) {
+	mockAction := &mockActionServicePool{}
+	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(mockAction, mockAuth, mockPool)
+
+	body := fmt.Sprintf(`{"action":"update","timestamp":%d}`, time.Now().Unix())
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body))
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+		{Subject: pkix.Name{CommonName: "deploy-eu1"}},
+	}}}
+	rr := httptest.NewRecorder()
+	handler.HandleWebhook(rr, req)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d (%s)", rr.Code, http.StatusAccepted, rr.Body.String())
+	}
+
+	jobID := rr.Header().Get("X-Job-ID")
+	if job := handler.jobStore.GetJob(jobID); job == nil || job.ClientIdentity != "CN=deploy-eu1" {
+		t.Fatalf("job %s should record the client certificate identity", jobID)
+	}
+
+	statusReq := httptest.NewRequest(http.MethodGet, withTimestamp("/job/status?job_id="+jobID), nil)
+	statusRR := httptest.NewRecorder()
+	handler.HandleJobStatus(statusRR, statusReq)
+	var status map[string]interface{}
+	if err := json.NewDecoder(statusRR.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status["client_identity"] != "CN=deploy-eu1" {
+		t.Errorf("client_identity = %v, want CN=deploy-eu1", status["client_identity"])
+	}
+}
+
 func TestWebhookHandlerWithPool_HandleWebhook_JobConflict(t *testing.T) {
 	currentTime := time.Now().Unix()
 
@@ -320,6 +506,7 @@ func TestWebhookHandlerWithPool_HandleJobStatus(t *testing.T) {
 		name           string
 		method         string
 		jobID          string
+		unsigned       bool
 		setupJob       func(handler *WebhookHandlerWithPool) string
 		expectedStatus int
 		checkResponse  func(t *testing.T, rr *httptest.ResponseRecorder, jobID string)
@@ -329,6 +516,17 @@ func TestWebhookHandlerWithPool_HandleJobStatus(t *testing.T) {
 			method:         http.MethodPost,
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
+		{
+			name:   "unsigned request",
+			method: http.MethodGet,
+			jobID:  "test-job",
+			setupJob: func(handler *WebhookHandlerWithPool) string {
+				handler.jobStore.TryStartJob(entity.NewJob("test-job", entity.ActionUpdate))
+				return "test-job"
+			},
+			unsigned:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
 		{
 			name:   "no job ID and no current job",
 			method: http.MethodGet,
@@ -411,7 +609,7 @@ func TestWebhookHandlerWithPool_HandleJobStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create fresh handler for each test
 			mockAction := &mockActionServicePool{}
-			mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+			mockAuth := &mockAuthenticatorPool{shouldValidate: !tt.unsigned}
 			mockPool := worker.NewPool(2, 10)
 			defer func() { _ = mockPool.Shutdown(time.Second) }()
 			handler := NewWebhookHandlerWithPool(mockAction, mockAuth, mockPool)
@@ -426,7 +624,7 @@ func TestWebhookHandlerWithPool_HandleJobStatus(t *testing.T) {
 				url += "?job_id=" + tt.jobID
 			}
 
-			req := httptest.NewRequest(tt.method, url, http.NoBody)
+			req := httptest.NewRequest(tt.method, withTimestamp(url), http.NoBody)
 			rr := httptest.NewRecorder()
 
 			handler.HandleJobStatus(rr, req)
@@ -456,7 +654,7 @@ func TestWebhookHandlerWithPool_HandleJobStatus_FailedJob(t *testing.T) {
 	handler.jobStore.TryStartJob(job)
 	handler.jobStore.FailCurrentJob(fmt.Errorf("test error"))
 
-	req := httptest.NewRequest(http.MethodGet, "/job/status?job_id=failed-job", http.NoBody)
+	req := httptest.NewRequest(http.MethodGet, withTimestamp("/job/status?job_id=failed-job"), http.NoBody)
 	rr := httptest.NewRecorder()
 
 	handler.HandleJobStatus(rr, req)
@@ -480,6 +678,165 @@ func TestWebhookHandlerWithPool_HandleJobStatus_FailedJob(t *testing.T) {
 	}
 }
 
+func TestWebhookHandlerWithPool_processActionWithContext_FailedResult(t *testing.T) {
+	result := entity.NewActionResult(entity.ActionUpdate)
+	result.AddStep(entity.StepResult{
+		Name:     "update_system",
+		Status:   entity.StepStatusFailed,
+		ExitCode: 100,
+		Error:    "apt-get failed",
+	})
+	result.Fail(fmt.Errorf("update_system failed: apt-get failed"))
+
+	mockAction := &mockActionServicePool{result: result}
+	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+
+	handler := NewWebhookHandlerWithPool(mockAction, mockAuth, mockPool)
+
+	job := entity.NewJob("failing-job", entity.ActionUpdate)
+	handler.jobStore.TryStartJob(job)
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionUpdate}, job)
+
+	if job.GetStatus() != entity.JobStatusFailed {
+		t.Fatalf("Expected job status %s, got %s", entity.JobStatusFailed, job.GetStatus())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, withTimestamp("/job/status?job_id=failing-job"), http.NoBody)
+	rr := httptest.NewRecorder()
+	handler.HandleJobStatus(rr, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["status"] != string(entity.JobStatusFailed) {
+		t.Errorf("Expected status %s, got %v", entity.JobStatusFailed, response["status"])
+	}
+	if response["exit_code"] != float64(100) {
+		t.Errorf("Expected exit_code 100, got %v", response["exit_code"])
+	}
+	steps, ok := response["steps"].([]interface{})
+	if !ok || len(steps) != 1 {
+		t.Fatalf("Expected one step in response, got %v", response["steps"])
+	}
+}
+
+func TestWebhookHandlerWithPool_Metrics(t *testing.T) {
+	result := entity.NewActionResult(entity.ActionUpgrade)
+	result.Fail(fmt.Errorf("upgrade_system failed"))
+	mockAuth := &mockAuthenticatorPool{shouldValidate: false}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{result: result}, mockAuth, mockPool)
+
+	signature := metrics.AuthFailures.WithLabelValues(metrics.AuthReasonSignature)
+	timestamp := metrics.AuthFailures.WithLabelValues(metrics.AuthReasonTimestamp)
+	signatures, timestamps := testutil.ToFloat64(signature), testutil.ToFloat64(timestamp)
+	postWebhook(handler, fmt.Sprintf(`{"action":"upgrade","timestamp":%d}`, time.Now().Unix()))
+	postWebhook(handler, `{"action":"upgrade","timestamp":1}`)
+	if testutil.ToFloat64(signature) != signatures+1 || testutil.ToFloat64(timestamp) != timestamps+1 {
+		t.Error("Expected one signature and one timestamp failure to be counted")
+	}
+
+	failed := metrics.Jobs.WithLabelValues("upgrade", "failed")
+	failures, durations := testutil.ToFloat64(failed), histogramCount(t, metrics.JobDuration, "upgrade")
+	job := entity.NewJob("metrics-job", entity.ActionUpgrade)
+	handler.jobStore.TryStartJob(job)
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionUpgrade}, job)
+	if testutil.ToFloat64(failed) != failures+1 || histogramCount(t, metrics.JobDuration, "upgrade") != durations+1 {
+		t.Error("Expected the failed job to be counted")
+	}
+}
+
+// histogramCount returns how many values a histogram observed for the label values.
+func histogramCount(t *testing.T, h *prometheus.HistogramVec, values ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.WithLabelValues(values...).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestWebhookHandlerWithPool_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	result := entity.NewActionResult(entity.ActionUpgrade)
+	result.Fail(fmt.Errorf("upgrade_system failed"))
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+	handler := NewWebhookHandlerWithPool(&mockActionServicePool{result: result},
+		&mockAuthenticatorPool{shouldValidate: false}, mockPool)
+
+	postWebhook(handler, fmt.Sprintf(`{"action":"upgrade","timestamp":%d}`, time.Now().Unix()))
+
+	// The job joins the trace of the request that created it
+	job := entity.NewJob("traced-job", entity.ActionUpgrade)
+	job.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	handler.jobStore.TryStartJob(job)
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionUpgrade}, job)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	auth := spans["authenticate"]
+	if auth == nil || auth.Status().Code != codes.Error || auth.Status().Description != "signature" {
+		t.Errorf("Expected failed authenticate span, got %v", auth)
+	}
+	jobSpan := spans["job upgrade"]
+	if jobSpan == nil {
+		t.Fatal("Expected a job span")
+	}
+	if jobSpan.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		jobSpan.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected job span in the request trace, got %v", jobSpan.SpanContext())
+	}
+	if jobSpan.Status().Code != codes.Error || jobSpan.Status().Description != "upgrade_system failed" {
+		t.Errorf("Expected failed job span, got %+v", jobSpan.Status())
+	}
+}
+
+func TestWebhookHandlerWithPool_processActionWithContext_RebootPending(t *testing.T) {
+	originalTimeout := rebootTimeout
+	rebootTimeout = 50 * time.Millisecond
+	defer func() { rebootTimeout = originalTimeout }()
+
+	result := entity.NewActionResult(entity.ActionReboot)
+	result.RebootPending = true
+
+	mockAction := &mockActionServicePool{result: result}
+	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
+	mockPool := worker.NewPool(2, 10)
+	defer func() { _ = mockPool.Shutdown(time.Second) }()
+
+	handler := NewWebhookHandlerWithPool(mockAction, mockAuth, mockPool)
+
+	job := entity.NewJob("reboot-job", entity.ActionReboot)
+	handler.jobStore.TryStartJob(job)
+	handler.processActionWithContext(context.Background(), entity.WebhookRequest{Action: entity.ActionReboot}, job)
+
+	// The job waits for the reboot
+	if !job.IsRunning() {
+		t.Fatalf("Expected reboot job to keep running, got %s", job.GetStatus())
+	}
+
+	// No reboot happened in time: the job fails
+	deadline := time.Now().Add(2 * time.Second)
+	for job.GetStatus() == entity.JobStatusRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.GetStatus() != entity.JobStatusFailed {
+		t.Errorf("Expected reboot job to fail after timeout, got %s", job.GetStatus())
+	}
+}
+
 func TestWebhookHandlerWithPool_Cleanup(t *testing.T) {
 	mockAction := &mockActionServicePool{}
 	mockAuth := &mockAuthenticatorPool{shouldValidate: true}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 )
 
 // Mock action service.
@@ -23,9 +25,12 @@ type mockActionService struct {
 	lastRequest         entity.WebhookRequest
 	lastJobID           string
 	shouldPanic         bool
+	result              *entity.ActionResult
 }
 
-func (m *mockActionService) ProcessAction(req entity.WebhookRequest, jobID string) {
+func (m *mockActionService) ProcessAction(
+	_ context.Context, req entity.WebhookRequest, jobID string, _ system.OutputSink,
+) *entity.ActionResult {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.processActionCalled = true
@@ -34,6 +39,10 @@ func (m *mockActionService) ProcessAction(req entity.WebhookRequest, jobID strin
 	if m.shouldPanic {
 		panic("mock panic for testing")
 	}
+	if m.result != nil {
+		return m.result
+	}
+	return entity.NewActionResult(req.Action)
 }
 
 func (m *mockActionService) wasProcessActionCalled() bool {
@@ -305,6 +314,7 @@ func TestWebhookHandler_IntegrationWithValidSignature(t *testing.T) {
 	mockAction := &mockActionService{}
 	mockAuth := &mockAuthenticator{shouldValidate: true}
 	handler := NewWebhookHandlerWithStatus(mockAction, mockAuth)
+	handler.allowedActions = entity.NewActionSet(entity.AllActions()...)
 
 	secret := "test-secret"
 	reqBody := entity.WebhookRequest{
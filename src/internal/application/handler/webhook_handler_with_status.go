@@ -2,6 +2,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
 	"github.com/kodflow/cloud-update/src/internal/domain/service"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/httputil"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/store"
@@ -17,20 +19,26 @@ import (
 
 // WebhookHandlerWithStatus handles webhook requests with job status tracking.
 type WebhookHandlerWithStatus struct {
-	actionService service.ActionService
-	authenticator security.Authenticator
-	jobStore      *store.JobStore
+	actionService  service.ActionService
+	authenticator  security.Authenticator
+	jobStore       store.JobStore
+	allowedActions entity.ActionSet
+	nonces         *security.NonceCache
+	requireNonce   bool
 }
 
-// NewWebhookHandlerWithStatus creates a new webhook handler with job status tracking.
+// NewWebhookHandlerWithStatus creates a new webhook handler with job status tracking,
+// accepting the update action only.
 func NewWebhookHandlerWithStatus(
 	actionService service.ActionService,
 	authenticator security.Authenticator,
 ) *WebhookHandlerWithStatus {
 	return &WebhookHandlerWithStatus{
-		actionService: actionService,
-		authenticator: authenticator,
-		jobStore:      store.NewJobStore(),
+		actionService:  actionService,
+		authenticator:  authenticator,
+		jobStore:       store.NewJobStore(),
+		allowedActions: entity.NewActionSet(entity.ActionUpdate),
+		nonces:         security.NewNonceCache(security.DefaultNonceCacheSize),
 	}
 }
 
@@ -59,10 +67,9 @@ func (h *WebhookHandlerWithStatus) HandleWebhook(w http.ResponseWriter, r *http.
 	}
 
 	// Validate request timestamp (prevent replay attacks)
-	requestTime := time.Unix(req.Timestamp, 0)
-	if time.Since(requestTime) > 5*time.Minute {
-		logger.Warn("Request timestamp too old")
-		http.Error(w, "Request expired", http.StatusBadRequest)
+	if msg := checkFreshness(req.Timestamp, time.Now()); msg != "" {
+		logger.WithField("timestamp", req.Timestamp).Warn("Request timestamp outside the accepted window")
+		http.Error(w, msg, http.StatusBadRequest)
 		return
 	}
 
@@ -73,17 +80,33 @@ func (h *WebhookHandlerWithStatus) HandleWebhook(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Validate action type
-	validActions := map[entity.ActionType]bool{
-		entity.ActionReinit: true,
-		entity.ActionReboot: true,
-		entity.ActionUpdate: true,
+	// Reject replayed requests
+	if msg := checkNonce(h.nonces, h.requireNonce, req.Nonce); msg != "" {
+		logger.WithField("nonce", req.Nonce).Warn("Rejected webhook request: " + msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
 	}
-	if !validActions[req.Action] {
+	// Only an accepted request uses up its nonce
+	sw := httputil.NewStatusRecorder(w)
+	w = sw
+	defer func() { releaseNonce(h.nonces, req.Nonce, sw.Status()) }()
+
+	// Validate action type against the configured allow-list
+	if !req.Action.IsValid() {
 		logger.WithField("action", req.Action).Warn("Invalid action type")
 		http.Error(w, "Invalid action", http.StatusBadRequest)
 		return
 	}
+	if !h.allowedActions.Allows(req.Action) {
+		logger.WithField("action", req.Action).Warn("Action not allowed on this server")
+		http.Error(w, "Action not allowed", http.StatusForbidden)
+		return
+	}
+	if req.Action.RequiresModule() && req.Module == "" {
+		logger.WithField("action", req.Action).Warn("Missing module for action")
+		http.Error(w, "Module required for action", http.StatusBadRequest)
+		return
+	}
 
 	// Check if there's already a job running
 	currentJob := h.jobStore.GetCurrentJob()
@@ -114,8 +137,9 @@ func (h *WebhookHandlerWithStatus) HandleWebhook(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Create new job
+	// Create new job, recording the client certificate that requested it
 	job := entity.NewJob(jobID, req.Action)
+	job.ClientIdentity = security.ClientIdentity(r)
 
 	// Try to start the job
 	if !h.jobStore.TryStartJob(job) {
@@ -133,6 +157,7 @@ func (h *WebhookHandlerWithStatus) HandleWebhook(w http.ResponseWriter, r *http.
 	// Log the request
 	logger.WithField("job_id", jobID).
 		WithField("action", req.Action).
+		WithField("client_identity", job.ClientIdentity).
 		Info("Starting webhook action")
 
 	// Process action asynchronously
@@ -167,13 +192,24 @@ func (h *WebhookHandlerWithStatus) processActionWithStatus(req entity.WebhookReq
 		}
 	}()
 
-	// Process the action
-	// Note: We need to modify ActionService to return an error
-	h.actionService.ProcessAction(req, job.ID)
+	// Process the action and record its outcome
+	result := h.actionService.ProcessAction(context.Background(), req, job.ID, jobOutput(job, nil))
+	h.jobStore.FinishCurrentJob(result)
+
+	if result != nil && result.Err != nil {
+		logger.WithField("job_id", job.ID).
+			WithField("action", req.Action).
+			WithField("exit_code", result.ExitCode).
+			WithField("error", result.Err).
+			Error("Job failed")
+		return
+	}
 
-	// For now, assume success if no panic occurred
-	// In a real implementation, ProcessAction should return an error
-	h.jobStore.CompleteCurrentJob()
+	if result != nil && result.RebootPending {
+		logger.WithField("job_id", job.ID).Info("Reboot scheduled, job completes once the system is back up")
+		watchPendingReboot(h.jobStore, job, nil)
+		return
+	}
 
 	logger.WithField("job_id", job.ID).
 		WithField("action", req.Action).
@@ -206,35 +242,58 @@ func (h *WebhookHandlerWithStatus) HandleJobStatus(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Prepare response based on job status
+	// Prepare response based on job status, read from one consistent copy of the job
+	snapshot := job.Snapshot()
 	w.Header().Set("Content-Type", "application/json")
 
 	response := map[string]interface{}{
-		"job_id":  job.ID,
-		"action":  job.Action,
-		"status":  job.GetStatus(),
-		"started": job.StartTime,
+		"job_id":  snapshot.ID,
+		"action":  snapshot.Action,
+		"status":  snapshot.Status,
+		"started": snapshot.StartTime,
+	}
+
+	if snapshot.ClientIdentity != "" {
+		response["client_identity"] = snapshot.ClientIdentity
 	}
 
 	// Add end time if job is complete
-	if job.EndTime != nil {
-		response["ended"] = *job.EndTime
-		response["duration"] = job.EndTime.Sub(job.StartTime).Seconds()
+	if snapshot.EndTime != nil {
+		response["ended"] = *snapshot.EndTime
+		response["duration"] = snapshot.EndTime.Sub(snapshot.StartTime).Seconds()
+	}
+
+	// Add per-step outcomes once the action has been processed
+	if result := snapshot.Result; result != nil {
+		response["exit_code"] = result.ExitCode
+		response["steps"] = result.Steps
+		if result.RebootPending {
+			response["reboot_pending"] = true
+		}
 	}
 
 	// Set appropriate HTTP status code based on job status
-	switch job.GetStatus() {
+	switch snapshot.Status {
 	case entity.JobStatusRunning:
 		w.WriteHeader(http.StatusAccepted) // 202 - Still processing
 		response["message"] = "Job is still running"
+		if result := snapshot.Result; result != nil && result.RebootPending {
+			response["message"] = "Waiting for the system to reboot"
+		}
 	case entity.JobStatusCompleted:
 		w.WriteHeader(http.StatusOK) // 200 - Success
 		response["message"] = "Job completed successfully"
 	case entity.JobStatusFailed:
 		w.WriteHeader(http.StatusInternalServerError) // 500 - Failed
 		response["message"] = "Job failed"
-		if job.Error != nil {
-			response["error"] = job.Error.Error()
+		if snapshot.Error != nil {
+			response["error"] = snapshot.Error.Error()
+		}
+	case entity.JobStatusInterrupted:
+		w.WriteHeader(http.StatusInternalServerError) // 500 - Interrupted
+		response["message"] = "Job was interrupted by a service restart"
+		if snapshot.Error != nil {
+			response["error"] = snapshot.Error.Error()
 		}
 	default:
 		w.WriteHeader(http.StatusAccepted) // 202 - Pending
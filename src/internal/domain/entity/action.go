@@ -1,7 +1,12 @@
 // Package entity defines the core business entities for the Cloud Update service.
 package entity
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // ActionType represents the type of action to be performed.
 type ActionType string
@@ -17,12 +22,98 @@ const (
 	ActionRestart       ActionType = "restart"        // Restart specific services
 )
 
+// AllActions returns every action type supported by the service.
+func AllActions() []ActionType {
+	return []ActionType{
+		ActionReinit, ActionReboot, ActionUpdate, ActionShutdown,
+		ActionExecuteScript, ActionUpgrade, ActionRestart,
+	}
+}
+
+// ActionSet is a set of action types, used as the allow-list of accepted actions.
+type ActionSet map[ActionType]bool
+
+// NewActionSet creates a set containing the given actions.
+func NewActionSet(actions ...ActionType) ActionSet {
+	set := make(ActionSet, len(actions))
+	for _, action := range actions {
+		set[action] = true
+	}
+	return set
+}
+
+// ParseActionSet parses a comma-separated list of action names.
+// The special value "all" enables every supported action.
+func ParseActionSet(list string) (ActionSet, error) {
+	set := make(ActionSet)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return NewActionSet(AllActions()...), nil
+		}
+
+		action := ActionType(name)
+		if !action.IsValid() {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		set[action] = true
+	}
+	return set, nil
+}
+
+// Allows reports whether the action is in the set.
+func (s ActionSet) Allows(action ActionType) bool {
+	return s[action]
+}
+
+// List returns the actions in the set in alphabetical order.
+func (s ActionSet) List() []ActionType {
+	actions := make([]ActionType, 0, len(s))
+	for action, enabled := range s {
+		if enabled {
+			actions = append(actions, action)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
+
+// IsValid reports whether the action is a supported action type.
+func (a ActionType) IsValid() bool {
+	for _, action := range AllActions() {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresModule reports whether the action needs WebhookRequest.Module to name its target
+// (the service to restart or the script to execute).
+func (a ActionType) RequiresModule() bool {
+	return a == ActionRestart || a == ActionExecuteScript
+}
+
 // WebhookRequest represents an incoming webhook request from GitHub.
 type WebhookRequest struct {
 	Action    ActionType        `json:"action"`
 	Module    string            `json:"module,omitempty"`
 	Config    map[string]string `json:"config,omitempty"`
 	Timestamp int64             `json:"timestamp"`
+	Nonce     string            `json:"nonce,omitempty"`  // Unique per request, rejects replays
+	RunAt     *time.Time        `json:"run_at,omitempty"` // Runs the action later instead of now
+	// CallbackURL receives the final job record once the job finished
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// CancelRequest asks to stop a running job. It is signed like a WebhookRequest.
+type CancelRequest struct {
+	JobID     string `json:"job_id"`
+	Timestamp int64  `json:"timestamp"`
+	Nonce     string `json:"nonce,omitempty"`
 }
 
 // WebhookResponse represents the response to a webhook request.
@@ -39,7 +130,32 @@ type Job struct {
 	Status    JobStatus
 	StartTime time.Time
 	EndTime   *time.Time
-	Error     error `json:"error,omitempty"`
+	Error     error         `json:"error,omitempty"`
+	Result    *ActionResult `json:"result,omitempty"`
+	// ClientIdentity is the verified TLS client certificate that requested the job
+	ClientIdentity string `json:"client_identity,omitempty"`
+	// TraceParent is the W3C trace context of the request, job spans join its trace
+	TraceParent string `json:"trace_parent,omitempty"`
+	// RunAt is when a scheduled job starts, and Request the webhook it runs
+	RunAt   *time.Time      `json:"run_at,omitempty"`
+	Request *WebhookRequest `json:"request,omitempty"`
+	// CallbackURL receives the final job record, Callbacks are its delivery attempts
+	CallbackURL string            `json:"callback_url,omitempty"`
+	Callbacks   []CallbackAttempt `json:"callbacks,omitempty"`
+}
+
+// CallbackAttempt is an attempt to deliver the completion callback of a job.
+type CallbackAttempt struct {
+	Attempt    int       `json:"attempt"`
+	Time       time.Time `json:"time"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"` // Response status, 0 without response
+	Error      string    `json:"error,omitempty"`       // Empty once the callback was delivered
+}
+
+// Delivered reports whether the attempt delivered the callback.
+func (a CallbackAttempt) Delivered() bool {
+	return a.Error == ""
 }
 
 // JobStatus represents the current status of a job.
@@ -47,8 +163,21 @@ type JobStatus string
 
 // Job status values.
 const (
-	JobStatusPending   JobStatus = "pending"
-	JobStatusRunning   JobStatus = "running"
-	JobStatusCompleted JobStatus = "completed"
-	JobStatusFailed    JobStatus = "failed"
+	JobStatusPending     JobStatus = "pending"
+	JobStatusRunning     JobStatus = "running"
+	JobStatusCompleted   JobStatus = "completed"
+	JobStatusFailed      JobStatus = "failed"
+	JobStatusInterrupted JobStatus = "interrupted" // Service stopped while the job was running
+	JobStatusCancelled   JobStatus = "cancelled"   // Stopped by a cancel request
+	JobStatusScheduled   JobStatus = "scheduled"   // Waiting for its run time
 )
+
+// IsFinal reports whether a job with this status is over. Pending, scheduled and
+// running jobs, including reboots waiting for the system to restart, are not.
+func (s JobStatus) IsFinal() bool {
+	switch s {
+	case JobStatusPending, JobStatusRunning, JobStatusScheduled:
+		return false
+	}
+	return true
+}
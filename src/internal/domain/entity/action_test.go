@@ -2,6 +2,7 @@ package entity
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -149,6 +150,24 @@ func TestJobStatusConstants(t *testing.T) {
 	}
 }
 
+func TestJobStatus_IsFinal(t *testing.T) {
+	tests := map[JobStatus]bool{
+		JobStatusPending:     false,
+		JobStatusScheduled:   false,
+		JobStatusRunning:     false,
+		JobStatusCompleted:   true,
+		JobStatusFailed:      true,
+		JobStatusInterrupted: true,
+		JobStatusCancelled:   true,
+	}
+
+	for status, want := range tests {
+		if got := status.IsFinal(); got != want {
+			t.Errorf("%s.IsFinal() = %v, want %v", status, got, want)
+		}
+	}
+}
+
 func TestWebhookRequestValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -193,3 +212,70 @@ func TestWebhookRequestValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestActionResult(t *testing.T) {
+	result := NewActionResult(ActionUpdate)
+	assert.True(t, result.Succeeded())
+	assert.Empty(t, result.Steps)
+
+	result.AddStep(StepResult{Name: "refresh", Status: StepStatusSucceeded})
+	assert.True(t, result.Succeeded())
+	assert.Equal(t, 0, result.ExitCode)
+
+	result.AddStep(StepResult{Name: "upgrade", Status: StepStatusFailed, ExitCode: 100, Output: "E: lock"})
+	result.Fail(errors.New("upgrade failed"))
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, 100, result.ExitCode)
+	assert.Equal(t, "E: lock", result.Output)
+
+	// Subsequent failures do not overwrite the first one.
+	result.AddStep(StepResult{Name: "cleanup", Status: StepStatusFailed, ExitCode: 1})
+	result.Fail(errors.New("cleanup failed"))
+	assert.Equal(t, 100, result.ExitCode)
+	assert.EqualError(t, result.Err, "upgrade failed")
+	assert.Len(t, result.Steps, 3)
+}
+
+func TestActionResult_FailWithoutStep(t *testing.T) {
+	result := NewActionResult(ActionExecuteScript)
+	result.Fail(errors.New("script not found"))
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, ExitCodeUnknown, result.ExitCode)
+
+	// A failed step without exit code, such as a command that could not start
+	result = NewActionResult(ActionUpdate)
+	result.AddStep(StepResult{Name: "update_system", Status: StepStatusFailed})
+	result.Fail(errors.New("update_system failed"))
+	assert.Equal(t, ExitCodeUnknown, result.ExitCode)
+}
+
+func TestParseActionSet(t *testing.T) {
+	set, err := ParseActionSet(" update, Reboot ,,restart")
+	assert.NoError(t, err)
+	assert.True(t, set.Allows(ActionUpdate))
+	assert.True(t, set.Allows(ActionReboot))
+	assert.True(t, set.Allows(ActionRestart))
+	assert.False(t, set.Allows(ActionShutdown))
+	assert.Equal(t, []ActionType{ActionReboot, ActionRestart, ActionUpdate}, set.List())
+
+	all, err := ParseActionSet("all")
+	assert.NoError(t, err)
+	assert.Len(t, all.List(), len(AllActions()))
+
+	empty, err := ParseActionSet("")
+	assert.NoError(t, err)
+	assert.Empty(t, empty.List())
+
+	_, err = ParseActionSet("update,format-disk")
+	assert.EqualError(t, err, `unknown action "format-disk"`)
+}
+
+func TestActionType_IsValid(t *testing.T) {
+	for _, action := range AllActions() {
+		assert.True(t, action.IsValid(), string(action))
+	}
+	assert.False(t, ActionType("invalid").IsValid())
+	assert.True(t, ActionRestart.RequiresModule())
+	assert.True(t, ActionExecuteScript.RequiresModule())
+	assert.False(t, ActionUpdate.RequiresModule())
+}
@@ -31,6 +31,30 @@ func (j *JobWithMutex) SetRunning() {
 	j.Status = JobStatusRunning
 }
 
+// SetStarted sets the status of a queued job to running, starting it now.
+func (j *JobWithMutex) SetStarted() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Status = JobStatusRunning
+	j.StartTime = time.Now()
+}
+
+// SetScheduled schedules the job to run the request at runAt.
+func (j *JobWithMutex) SetScheduled(runAt time.Time, req WebhookRequest) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Status = JobStatusScheduled
+	j.RunAt = &runAt
+	j.Request = &req
+}
+
+// Reschedule moves the run time of a scheduled job.
+func (j *JobWithMutex) Reschedule(runAt time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.RunAt = &runAt
+}
+
 // SetCompleted sets the job status to completed.
 func (j *JobWithMutex) SetCompleted() {
 	j.mu.Lock()
@@ -50,6 +74,49 @@ func (j *JobWithMutex) SetFailed(err error) {
 	j.EndTime = &now
 }
 
+// SetInterrupted marks the job as interrupted by a service restart.
+func (j *JobWithMutex) SetInterrupted(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Status = JobStatusInterrupted
+	j.Error = err
+	now := time.Now()
+	j.EndTime = &now
+}
+
+// SetCancelled marks the job as stopped by a cancel request.
+func (j *JobWithMutex) SetCancelled(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Status = JobStatusCancelled
+	j.Error = err
+	now := time.Now()
+	j.EndTime = &now
+}
+
+// SetResult records the structured outcome of the job's action.
+func (j *JobWithMutex) SetResult(result *ActionResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Result = result
+}
+
+// GetResult returns the structured outcome of the job's action, if any.
+func (j *JobWithMutex) GetResult() *ActionResult {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.Result
+}
+
+// AddCallbackAttempt records an attempt to deliver the completion callback.
+func (j *JobWithMutex) AddCallbackAttempt(attempt CallbackAttempt) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Callbacks = append(j.Callbacks, attempt)
+}
+
 // GetStatus returns the current status of the job.
 func (j *JobWithMutex) GetStatus() JobStatus {
 	j.mu.RLock()
@@ -57,6 +124,13 @@ func (j *JobWithMutex) GetStatus() JobStatus {
 	return j.Status
 }
 
+// Snapshot returns a consistent copy of the job's fields.
+func (j *JobWithMutex) Snapshot() Job {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.Job
+}
+
 // IsRunning checks if the job is currently running.
 func (j *JobWithMutex) IsRunning() bool {
 	j.mu.RLock()
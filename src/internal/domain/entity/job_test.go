@@ -390,3 +390,35 @@ func BenchmarkJobOperations(b *testing.B) {
 		})
 	})
 }
+
+func TestJobWithMutex_SetInterrupted(t *testing.T) {
+	job := NewJob("test-interrupted", ActionUpdate)
+	job.SetRunning()
+
+	job.SetInterrupted(errors.New("service restarted"))
+
+	if job.GetStatus() != JobStatusInterrupted {
+		t.Errorf("Status after SetInterrupted() = %v, want %v", job.GetStatus(), JobStatusInterrupted)
+	}
+	if job.IsRunning() {
+		t.Error("IsRunning() = true for interrupted job, want false")
+	}
+	if job.EndTime == nil || job.Error == nil {
+		t.Error("Interrupted job should have an end time and an error")
+	}
+}
+
+func TestJobWithMutex_AddCallbackAttempt(t *testing.T) {
+	job := NewJob("test-callback", ActionUpdate)
+	job.SetCompleted()
+
+	failed := CallbackAttempt{Attempt: 1, Time: time.Now(), URL: "https://ci.example.com/hook", StatusCode: 502,
+		Error: "unexpected status 502"}
+	job.AddCallbackAttempt(failed)
+	job.AddCallbackAttempt(CallbackAttempt{Attempt: 2, Time: time.Now(), URL: failed.URL, StatusCode: 200})
+
+	callbacks := job.Snapshot().Callbacks
+	if len(callbacks) != 2 || callbacks[0].Delivered() || !callbacks[1].Delivered() {
+		t.Errorf("Callbacks = %+v, want a failed then a delivered attempt", callbacks)
+	}
+}
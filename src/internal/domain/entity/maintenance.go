@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a weekly time range, such as Saturday from 02:00 to 05:00.
+type MaintenanceWindow struct {
+	Days  [7]bool       // Days the window starts on, indexed by time.Weekday
+	Start time.Duration // Start of the window, from midnight
+	End   time.Duration // End of the window, from midnight; at or before Start it ends the next day
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseMaintenanceWindow parses a window written as "[days] HH:MM-HH:MM", where days
+// is a comma-separated list of weekdays or ranges such as "mon-fri,sun". Without
+// days, or with "*", the window opens every day.
+func ParseMaintenanceWindow(spec string) (MaintenanceWindow, error) {
+	var w MaintenanceWindow
+	fields := strings.Fields(strings.ToLower(spec))
+	days, hours := "*", ""
+	switch len(fields) {
+	case 1:
+		hours = fields[0]
+	case 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return w, fmt.Errorf("invalid maintenance window %q, expected \"[days] HH:MM-HH:MM\"", spec)
+	}
+
+	start, end, ok := strings.Cut(hours, "-")
+	if !ok {
+		return w, fmt.Errorf("invalid time range %q, expected HH:MM-HH:MM", hours)
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(start); err != nil {
+		return w, err
+	}
+	if w.End, err = parseTimeOfDay(end); err != nil {
+		return w, err
+	}
+
+	if days == "*" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return w, nil
+	}
+	for _, part := range strings.Split(days, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := weekdays[shortDay(first)]
+		if !ok {
+			return w, fmt.Errorf("unknown weekday %q", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[shortDay(last)]; !ok {
+				return w, fmt.Errorf("unknown weekday %q", last)
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			w.Days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return w, nil
+}
+
+// shortDay accepts full weekday names as well as their first three letters.
+func shortDay(name string) string {
+	if len(name) > 3 {
+		return name[:3]
+	}
+	return name
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// bounds returns the window starting on the day of midnight, in the location of midnight.
+func (w MaintenanceWindow) bounds(midnight time.Time) (time.Time, time.Time) {
+	y, m, d := midnight.Date()
+	loc := midnight.Location()
+	at := func(day int, offset time.Duration) time.Time {
+		return time.Date(y, m, day, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, loc)
+	}
+	start, end := at(d, w.Start), at(d, w.End)
+	if w.End <= w.Start {
+		end = at(d+1, w.End)
+	}
+	return start, end
+}
+
+// MaintenancePolicy restricts actions to maintenance windows.
+type MaintenancePolicy struct {
+	Windows  []MaintenanceWindow
+	Location *time.Location // Time zone of the windows (default: UTC)
+	Actions  ActionSet      // Actions that only run within a window
+}
+
+// Restricts reports whether the action must wait for a maintenance window.
+func (p MaintenancePolicy) Restricts(action ActionType) bool {
+	return len(p.Windows) > 0 && p.Actions.Allows(action)
+}
+
+// Next returns t when a window is open at t, otherwise the start of the next
+// window. It returns the zero time when there is no window.
+func (p MaintenancePolicy) Next(t time.Time) time.Time {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	y, m, d := local.Date()
+
+	var next time.Time
+	// Windows opened yesterday may still be open, and the next one opens within a week
+	for day := -1; day <= 7; day++ {
+		midnight := time.Date(y, m, d+day, 0, 0, 0, 0, loc)
+		for _, w := range p.Windows {
+			if !w.Days[midnight.Weekday()] {
+				continue
+			}
+			start, end := w.bounds(midnight)
+			if !t.Before(start) && t.Before(end) {
+				return t
+			}
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next
+}
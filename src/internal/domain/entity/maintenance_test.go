@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	w, err := ParseMaintenanceWindow("Sat,Sunday 02:00-05:30")
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, false, false, false, false, false, true}, w.Days)
+	assert.Equal(t, 2*time.Hour, w.Start)
+	assert.Equal(t, 5*time.Hour+30*time.Minute, w.End)
+
+	w, err = ParseMaintenanceWindow("fri-mon 23:00-01:00")
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, w.Days)
+
+	w, err = ParseMaintenanceWindow("03:00-04:00")
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, true, true, true, true, true, true}, w.Days)
+
+	for _, spec := range []string{"", "sat", "sat 02:00", "sat 2am-3am", "someday 02:00-03:00", "sat sun 02:00-03:00"} {
+		_, err := ParseMaintenanceWindow(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMaintenancePolicy_Next(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	weekend, _ := ParseMaintenanceWindow("sat,sun 02:00-05:00")
+	night, _ := ParseMaintenanceWindow("fri 23:00-01:00")
+	policy := MaintenancePolicy{
+		Windows:  []MaintenanceWindow{weekend, night},
+		Location: paris,
+		Actions:  NewActionSet(ActionReboot),
+	}
+
+	assert.True(t, policy.Restricts(ActionReboot))
+	assert.False(t, policy.Restricts(ActionUpdate))
+	assert.False(t, MaintenancePolicy{Actions: NewActionSet(ActionReboot)}.Restricts(ActionReboot))
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 0, 0, paris)
+	}
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"wednesday waits for friday night", at(5, 12, 0), at(7, 23, 0)},
+		{"inside the window runs now", at(8, 3, 0), at(8, 3, 0)},
+		{"window started the day before", at(8, 0, 30), at(8, 0, 30)},
+		{"window end is exclusive", at(9, 5, 0), at(14, 23, 0)},
+		// Clocks move forward at 02:00 on March 30, 2025 in Paris
+		{"daylight saving time", at(29, 6, 0), at(30, 3, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(policy.Next(tt.now)), "Next() = %v, want %v", policy.Next(tt.now), tt.want)
+		})
+	}
+
+	assert.True(t, MaintenancePolicy{}.Next(time.Now()).IsZero())
+}
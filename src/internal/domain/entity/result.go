@@ -0,0 +1,74 @@
+// Package entity contains the domain entities for the Cloud Update service.
+package entity
+
+import "time"
+
+// StepStatus represents the outcome of a single step of an action.
+type StepStatus string
+
+// Step status values.
+const (
+	StepStatusSucceeded StepStatus = "succeeded"
+	StepStatusFailed    StepStatus = "failed"
+	StepStatusSkipped   StepStatus = "skipped"
+)
+
+// StepResult records the outcome of one step performed while processing an action.
+type StepResult struct {
+	Name      string     `json:"name"`
+	Status    StepStatus `json:"status"`
+	ExitCode  int        `json:"exit_code"`
+	Output    string     `json:"output,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	StartTime time.Time  `json:"started"`
+	EndTime   time.Time  `json:"ended"`
+}
+
+// ExitCodeUnknown is the exit code of a failed action when no command reported one,
+// e.g. an unknown action or a script that could not be resolved.
+const ExitCodeUnknown = -1
+
+// ActionResult is the structured outcome of processing a webhook action.
+type ActionResult struct {
+	Action        ActionType   `json:"action"`
+	ExitCode      int          `json:"exit_code"`
+	Steps         []StepResult `json:"steps"`
+	Output        string       `json:"output,omitempty"`
+	RebootPending bool         `json:"reboot_pending,omitempty"` // Action completes once the system has rebooted
+	Err           error        `json:"-"`
+}
+
+// NewActionResult creates an empty result for the given action.
+func NewActionResult(action ActionType) *ActionResult {
+	return &ActionResult{
+		Action: action,
+		Steps:  make([]StepResult, 0),
+	}
+}
+
+// AddStep appends a step to the result. A failed step marks the whole result as failed,
+// recording its exit code and output on the result.
+func (r *ActionResult) AddStep(step StepResult) {
+	r.Steps = append(r.Steps, step)
+
+	if step.Status == StepStatusFailed && r.Err == nil {
+		r.ExitCode = step.ExitCode
+		r.Output = step.Output
+	}
+}
+
+// Fail marks the result as failed with the given error. The exit code is set to
+// ExitCodeUnknown when no failed step recorded one, so a failure never reports 0.
+func (r *ActionResult) Fail(err error) {
+	if r.Err == nil {
+		r.Err = err
+		if r.ExitCode == 0 {
+			r.ExitCode = ExitCodeUnknown
+		}
+	}
+}
+
+// Succeeded reports whether the action completed without error.
+func (r *ActionResult) Succeeded() bool {
+	return r.Err == nil
+}
@@ -2,6 +2,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -11,7 +12,7 @@ import (
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 )
 
-// rebootDelay is the delay before executing a reboot.
+// rebootDelay is the delay before executing a reboot or shutdown.
 // This is a variable so it can be modified in tests.
 var (
 	rebootDelay   = 10 * time.Second
@@ -32,9 +33,13 @@ func setRebootDelay(d time.Duration) {
 	rebootDelay = d
 }
 
-// ActionService defines the interface for action processing.
+// ActionService defines the interface for action processing. The commands of an
+// action are killed when ctx is done and their output is streamed to out, which
+// may be nil.
 type ActionService interface {
-	ProcessAction(req entity.WebhookRequest, jobID string)
+	ProcessAction(
+		ctx context.Context, req entity.WebhookRequest, jobID string, out system.OutputSink,
+	) *entity.ActionResult
 }
 
 type actionService struct {
@@ -48,25 +53,70 @@ func NewActionService(executor system.Executor) ActionService {
 	}
 }
 
-func (s *actionService) ProcessAction(req entity.WebhookRequest, jobID string) {
+func (s *actionService) ProcessAction(
+	ctx context.Context, req entity.WebhookRequest, jobID string, out system.OutputSink,
+) *entity.ActionResult {
 	log.Printf("Starting job %s: action=%s", jobID, req.Action)
 
+	result := entity.NewActionResult(req.Action)
+
 	switch req.Action {
 	case entity.ActionReinit:
-		s.executeCloudInit(jobID)
+		s.executeCloudInit(ctx, out, jobID, result)
 	case entity.ActionReboot:
-		s.executeReboot(jobID)
+		s.executeReboot(jobID, result)
 	case entity.ActionUpdate:
-		s.executeUpdate(jobID)
+		s.executeUpdate(ctx, out, jobID, result)
+	case entity.ActionShutdown:
+		s.executeShutdown(jobID, result)
+	case entity.ActionUpgrade:
+		s.executeUpgrade(ctx, out, jobID, result)
+	case entity.ActionRestart:
+		s.executeRestart(ctx, out, jobID, req.Module, result)
+	case entity.ActionExecuteScript:
+		s.executeScript(ctx, out, jobID, req.Module, result)
 	default:
 		log.Printf("Job %s: Unknown action '%s'", jobID, req.Action)
+		result.Fail(fmt.Errorf("unknown action: %s", req.Action))
+	}
+
+	return result
+}
+
+// runStep executes a single step and records its outcome on the result.
+func (s *actionService) runStep(result *entity.ActionResult, name string, fn func() error) error {
+	step := entity.StepResult{
+		Name:      name,
+		Status:    entity.StepStatusSucceeded,
+		StartTime: time.Now(),
 	}
+
+	err := fn()
+	step.EndTime = time.Now()
+	if err != nil {
+		step.Status = entity.StepStatusFailed
+		step.ExitCode = system.ExitCode(err)
+		step.Output = system.CommandOutput(err)
+		step.Error = err.Error()
+	}
+
+	result.AddStep(step)
+	if err != nil {
+		result.Fail(fmt.Errorf("%s failed: %w", name, err))
+	}
+
+	return err
 }
 
-func (s *actionService) executeCloudInit(jobID string) {
+func (s *actionService) executeCloudInit(
+	ctx context.Context, out system.OutputSink, jobID string, result *entity.ActionResult,
+) {
 	log.Printf("Job %s: Executing cloud-init", jobID)
 
-	if err := s.systemExecutor.RunCloudInit(); err != nil {
+	err := s.runStep(result, "cloud_init", func() error {
+		return s.systemExecutor.RunCloudInit(ctx, out)
+	})
+	if err != nil {
 		log.Printf("Job %s: cloud-init failed: %v", jobID, err)
 		return
 	}
@@ -74,24 +124,41 @@ func (s *actionService) executeCloudInit(jobID string) {
 	log.Printf("Job %s: cloud-init completed successfully", jobID)
 }
 
-func (s *actionService) executeReboot(jobID string) {
+func (s *actionService) executeReboot(jobID string, result *entity.ActionResult) {
 	log.Printf("Job %s: Scheduling system reboot in 10 seconds", jobID)
 
+	// The reboot itself happens after the job has finished, so only
+	// the scheduling step can be reported on the result, and it no
+	// longer depends on the job context.
+	now := time.Now()
+	result.AddStep(entity.StepResult{
+		Name:      "schedule_reboot",
+		Status:    entity.StepStatusSucceeded,
+		StartTime: now,
+		EndTime:   now,
+	})
+	result.RebootPending = true
+
 	go func() {
 		time.Sleep(getRebootDelay())
-		if err := s.systemExecutor.Reboot(); err != nil {
+		if err := s.systemExecutor.Reboot(context.Background(), nil); err != nil {
 			log.Printf("Job %s: reboot failed: %v", jobID, err)
 		}
 	}()
 }
 
-func (s *actionService) executeUpdate(jobID string) {
+func (s *actionService) executeUpdate(
+	ctx context.Context, out system.OutputSink, jobID string, result *entity.ActionResult,
+) {
 	log.Printf("Job %s: Executing system update", jobID)
 
 	distro := s.systemExecutor.DetectDistribution()
 	log.Printf("Job %s: Detected distribution: %s", jobID, distro)
 
-	if err := s.systemExecutor.UpdateSystem(); err != nil {
+	err := s.runStep(result, "update_system", func() error {
+		return s.systemExecutor.UpdateSystem(ctx, out)
+	})
+	if err != nil {
 		log.Printf("Job %s: system update failed: %v", jobID, err)
 		return
 	}
@@ -99,6 +166,74 @@ func (s *actionService) executeUpdate(jobID string) {
 	log.Printf("Job %s: system update completed successfully", jobID)
 }
 
+func (s *actionService) executeShutdown(jobID string, result *entity.ActionResult) {
+	log.Printf("Job %s: Scheduling system shutdown in 10 seconds", jobID)
+
+	// As for reboots, the shutdown happens after the job has finished.
+	now := time.Now()
+	result.AddStep(entity.StepResult{
+		Name:      "schedule_shutdown",
+		Status:    entity.StepStatusSucceeded,
+		StartTime: now,
+		EndTime:   now,
+	})
+
+	go func() {
+		time.Sleep(getRebootDelay())
+		if err := s.systemExecutor.Shutdown(context.Background(), nil); err != nil {
+			log.Printf("Job %s: shutdown failed: %v", jobID, err)
+		}
+	}()
+}
+
+func (s *actionService) executeUpgrade(
+	ctx context.Context, out system.OutputSink, jobID string, result *entity.ActionResult,
+) {
+	log.Printf("Job %s: Executing distribution upgrade", jobID)
+
+	distro := s.systemExecutor.DetectDistribution()
+	log.Printf("Job %s: Detected distribution: %s", jobID, distro)
+
+	err := s.runStep(result, "upgrade_system", func() error {
+		return s.systemExecutor.UpgradeSystem(ctx, out)
+	})
+	if err != nil {
+		log.Printf("Job %s: distribution upgrade failed: %v", jobID, err)
+		return
+	}
+	log.Printf("Job %s: distribution upgrade completed successfully", jobID)
+}
+
+func (s *actionService) executeRestart(
+	ctx context.Context, out system.OutputSink, jobID, serviceName string, result *entity.ActionResult,
+) {
+	log.Printf("Job %s: Restarting service %q", jobID, serviceName)
+
+	err := s.runStep(result, "restart_service", func() error {
+		return s.systemExecutor.RestartService(ctx, out, serviceName)
+	})
+	if err != nil {
+		log.Printf("Job %s: service restart failed: %v", jobID, err)
+		return
+	}
+	log.Printf("Job %s: service %s restarted successfully", jobID, serviceName)
+}
+
+func (s *actionService) executeScript(
+	ctx context.Context, out system.OutputSink, jobID, scriptName string, result *entity.ActionResult,
+) {
+	log.Printf("Job %s: Executing script %q", jobID, scriptName)
+
+	err := s.runStep(result, "execute_script", func() error {
+		return s.systemExecutor.ExecuteScript(ctx, out, scriptName)
+	})
+	if err != nil {
+		log.Printf("Job %s: script failed: %v", jobID, err)
+		return
+	}
+	log.Printf("Job %s: script %s completed successfully", jobID, scriptName)
+}
+
 // GenerateJobID generates a unique job identifier.
 // Deprecated: Use security.GenerateJobID() for secure job ID generation.
 func GenerateJobID() string {
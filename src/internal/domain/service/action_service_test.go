@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -17,11 +18,16 @@ type mockSystemExecutor struct {
 	cloudInitCalled bool
 	rebootCalled    bool
 	updateCalled    bool
+	shutdownCalled  bool
+	upgradeCalled   bool
 	distribution    system.Distribution
 	shouldError     bool
+
+	restartedService string
+	executedScript   string
 }
 
-func (m *mockSystemExecutor) RunCloudInit() error {
+func (m *mockSystemExecutor) RunCloudInit(_ context.Context, _ system.OutputSink) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.cloudInitCalled = true
@@ -31,7 +37,7 @@ func (m *mockSystemExecutor) RunCloudInit() error {
 	return nil
 }
 
-func (m *mockSystemExecutor) Reboot() error {
+func (m *mockSystemExecutor) Reboot(_ context.Context, _ system.OutputSink) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.rebootCalled = true
@@ -41,7 +47,7 @@ func (m *mockSystemExecutor) Reboot() error {
 	return nil
 }
 
-func (m *mockSystemExecutor) UpdateSystem() error {
+func (m *mockSystemExecutor) UpdateSystem(_ context.Context, _ system.OutputSink) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.updateCalled = true
@@ -51,6 +57,46 @@ func (m *mockSystemExecutor) UpdateSystem() error {
 	return nil
 }
 
+func (m *mockSystemExecutor) Shutdown(_ context.Context, _ system.OutputSink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shutdownCalled = true
+	if m.shouldError {
+		return fmt.Errorf("mock shutdown error")
+	}
+	return nil
+}
+
+func (m *mockSystemExecutor) UpgradeSystem(_ context.Context, _ system.OutputSink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.upgradeCalled = true
+	if m.shouldError {
+		return fmt.Errorf("mock upgrade error")
+	}
+	return nil
+}
+
+func (m *mockSystemExecutor) RestartService(_ context.Context, _ system.OutputSink, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restartedService = name
+	if m.shouldError {
+		return fmt.Errorf("mock restart error")
+	}
+	return nil
+}
+
+func (m *mockSystemExecutor) ExecuteScript(_ context.Context, _ system.OutputSink, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executedScript = name
+	if m.shouldError {
+		return fmt.Errorf("mock script error")
+	}
+	return nil
+}
+
 func (m *mockSystemExecutor) DetectDistribution() system.Distribution {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -78,7 +124,7 @@ func TestActionService_ProcessAction_CloudInit(t *testing.T) {
 		Timestamp: time.Now().Unix(),
 	}
 
-	service.ProcessAction(req, "test_job_1")
+	service.ProcessAction(context.Background(), req, "test_job_1", nil)
 
 	// Give goroutine time to execute if needed
 	time.Sleep(10 * time.Millisecond)
@@ -103,7 +149,7 @@ func TestActionService_ProcessAction_Update(t *testing.T) {
 		Timestamp: time.Now().Unix(),
 	}
 
-	service.ProcessAction(req, "test_job_2")
+	service.ProcessAction(context.Background(), req, "test_job_2", nil)
 
 	mockExec.mu.Lock()
 	called := mockExec.updateCalled
@@ -124,7 +170,7 @@ func TestActionService_ProcessAction_Reboot(t *testing.T) {
 	}
 
 	// Note: Reboot is async with 10 second delay
-	service.ProcessAction(req, "test_job_3")
+	service.ProcessAction(context.Background(), req, "test_job_3", nil)
 
 	// Check that reboot is scheduled (not yet called)
 	mockExec.mu.Lock()
@@ -146,7 +192,7 @@ func TestActionService_ProcessAction_UnknownAction(t *testing.T) {
 	}
 
 	// This should not panic
-	service.ProcessAction(req, "test_job_4")
+	service.ProcessAction(context.Background(), req, "test_job_4", nil)
 
 	// Verify no actions were called
 	mockExec.mu.Lock()
@@ -160,6 +206,33 @@ func TestActionService_ProcessAction_UnknownAction(t *testing.T) {
 	}
 }
 
+func TestActionService_ProcessAction_ModuleTargets(t *testing.T) {
+	mockExec := &mockSystemExecutor{}
+	service := NewActionService(mockExec)
+
+	ctx := context.Background()
+	req := entity.WebhookRequest{Action: entity.ActionRestart, Module: "nginx"}
+	result := service.ProcessAction(ctx, req, "test_restart", nil)
+	if !result.Succeeded() {
+		t.Fatalf("restart failed: %v", result.Err)
+	}
+
+	req = entity.WebhookRequest{Action: entity.ActionExecuteScript, Module: "rotate-keys"}
+	result = service.ProcessAction(ctx, req, "test_script", nil)
+	if !result.Succeeded() {
+		t.Fatalf("execute_script failed: %v", result.Err)
+	}
+
+	mockExec.mu.Lock()
+	defer mockExec.mu.Unlock()
+	if mockExec.restartedService != "nginx" {
+		t.Errorf("RestartService called with %q, want nginx", mockExec.restartedService)
+	}
+	if mockExec.executedScript != "rotate-keys" {
+		t.Errorf("ExecuteScript called with %q, want rotate-keys", mockExec.executedScript)
+	}
+}
+
 func TestActionService_ProcessAction_WithError(t *testing.T) {
 	mockExec := &mockSystemExecutor{
 		shouldError: true,
@@ -172,7 +245,7 @@ func TestActionService_ProcessAction_WithError(t *testing.T) {
 	}
 
 	// Should handle error gracefully (log it)
-	service.ProcessAction(req, "test_job_error")
+	service.ProcessAction(context.Background(), req, "test_job_error", nil)
 
 	mockExec.mu.Lock()
 	called := mockExec.cloudInitCalled
@@ -222,7 +295,7 @@ func TestActionService_MultipleDistributions(t *testing.T) {
 				Timestamp: time.Now().Unix(),
 			}
 
-			service.ProcessAction(req, GenerateJobID())
+			service.ProcessAction(context.Background(), req, GenerateJobID(), nil)
 
 			mockExec.mu.Lock()
 			called := mockExec.updateCalled
@@ -252,7 +325,7 @@ func BenchmarkProcessAction(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		service.ProcessAction(req, GenerateJobID())
+		service.ProcessAction(context.Background(), req, GenerateJobID(), nil)
 	}
 }
 
@@ -270,7 +343,7 @@ func TestActionService_ProcessAction_UpdateError(t *testing.T) {
 	}
 
 	// This should handle the error gracefully
-	service.ProcessAction(req, "test_update_error")
+	service.ProcessAction(context.Background(), req, "test_update_error", nil)
 
 	mockExec.mu.Lock()
 	called := mockExec.updateCalled
@@ -298,7 +371,7 @@ func TestActionService_RebootAsyncError(t *testing.T) {
 	go func() {
 		// Simulate the reboot execution with error after a short delay
 		time.Sleep(10 * time.Millisecond) // Much shorter for test
-		if err := mockExec.Reboot(); err != nil {
+		if err := mockExec.Reboot(context.Background(), nil); err != nil {
 			// This covers the error path in the goroutine
 			t.Logf("Expected reboot error in test: %v", err)
 		}
@@ -316,5 +389,66 @@ func TestActionService_RebootAsyncError(t *testing.T) {
 	}
 
 	// The actual executeReboot function would cover the same pattern
-	actionSvc.executeReboot("test_reboot_async")
+	actionSvc.executeReboot("test_reboot_async", entity.NewActionResult(entity.ActionReboot))
+}
+
+func TestActionService_ProcessAction_Result(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      entity.ActionType
+		shouldError bool
+		wantErr     bool
+		wantStep    string
+		wantStatus  entity.StepStatus
+	}{
+		{"update success", entity.ActionUpdate, false, false, "update_system", entity.StepStatusSucceeded},
+		{"update failure", entity.ActionUpdate, true, true, "update_system", entity.StepStatusFailed},
+		{"reinit failure", entity.ActionReinit, true, true, "cloud_init", entity.StepStatusFailed},
+		{"reboot scheduled", entity.ActionReboot, false, false, "schedule_reboot", entity.StepStatusSucceeded},
+		{"shutdown scheduled", entity.ActionShutdown, false, false, "schedule_shutdown", entity.StepStatusSucceeded},
+		{"upgrade success", entity.ActionUpgrade, false, false, "upgrade_system", entity.StepStatusSucceeded},
+		{"upgrade failure", entity.ActionUpgrade, true, true, "upgrade_system", entity.StepStatusFailed},
+		{"restart failure", entity.ActionRestart, true, true, "restart_service", entity.StepStatusFailed},
+		{"script failure", entity.ActionExecuteScript, true, true, "execute_script", entity.StepStatusFailed},
+		{"unknown action", "unknown", false, true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockSystemExecutor{shouldError: tt.shouldError}
+			service := NewActionService(mockExec)
+
+			req := entity.WebhookRequest{Action: tt.action}
+			result := service.ProcessAction(context.Background(), req, "test_job_result", nil)
+			if result == nil {
+				t.Fatal("ProcessAction returned nil result")
+			}
+			if result.Action != tt.action {
+				t.Errorf("result.Action = %s, want %s", result.Action, tt.action)
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("result.Err = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if result.Succeeded() == tt.wantErr {
+				t.Errorf("result.Succeeded() = %v, want %v", result.Succeeded(), !tt.wantErr)
+			}
+
+			if tt.wantStep == "" {
+				if len(result.Steps) != 0 {
+					t.Errorf("expected no steps, got %d", len(result.Steps))
+				}
+				return
+			}
+			if len(result.Steps) != 1 {
+				t.Fatalf("expected 1 step, got %d", len(result.Steps))
+			}
+			step := result.Steps[0]
+			if step.Name != tt.wantStep || step.Status != tt.wantStatus {
+				t.Errorf("step = %s/%s, want %s/%s", step.Name, step.Status, tt.wantStep, tt.wantStatus)
+			}
+			if tt.wantErr && result.ExitCode == 0 {
+				t.Error("failed result should carry a non-zero exit code")
+			}
+		})
+	}
 }
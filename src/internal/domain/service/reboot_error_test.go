@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 )
 
 // mockRebootExecutor extends mockSystemExecutor with reboot completion signal.
@@ -22,8 +26,8 @@ func newMockRebootExecutor(shouldError bool) *mockRebootExecutor {
 	}
 }
 
-func (m *mockRebootExecutor) Reboot() error {
-	err := m.mockSystemExecutor.Reboot()
+func (m *mockRebootExecutor) Reboot(ctx context.Context, out system.OutputSink) error {
+	err := m.mockSystemExecutor.Reboot(ctx, out)
 	m.once.Do(func() {
 		close(m.rebootDone)
 	})
@@ -48,7 +52,7 @@ func TestExecuteReboot_ErrorPath(t *testing.T) {
 	}
 
 	// Call executeReboot which starts a goroutine
-	service.executeReboot("test_job_reboot_error")
+	service.executeReboot("test_job_reboot_error", entity.NewActionResult(entity.ActionReboot))
 
 	// Wait for reboot to be called or timeout (with shorter timeout)
 	select {
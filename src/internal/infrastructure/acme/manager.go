@@ -0,0 +1,209 @@
+// Package acme obtains and renews TLS certificates from an ACME certificate
+// authority such as Let's Encrypt, with golang.org/x/crypto/acme/autocert.
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// Challenge types proving control of the domain.
+const (
+	ChallengeHTTP01    = "http-01"     // Token served over HTTP on port 80
+	ChallengeTLSALPN01 = "tls-alpn-01" // Certificate served over TLS on port 443
+)
+
+// LetsEncryptURL is the directory of the Let's Encrypt production CA.
+const LetsEncryptURL = acme.LetsEncryptURL
+
+// Config holds the certificate manager settings.
+type Config struct {
+	DirectoryURL string // ACME directory (default: Let's Encrypt)
+	Domain       string // Domain the certificate is issued for
+	Email        string // Account contact, optional
+	Challenge    string // ChallengeTLSALPN01 (default) or ChallengeHTTP01
+	CacheDir     string // Directory holding the account key and certificate
+	CAFile       string // CA bundle trusted for the directory, e.g. a local Pebble instance
+}
+
+// Manager obtains a certificate for a domain and serves it. Renewal is left to
+// autocert, which renews certificates before they expire.
+type Manager struct {
+	cfg         Config
+	domain      string // Domain as autocert caches it
+	autocert    *autocert.Manager
+	httpHandler http.Handler
+}
+
+// NewManager creates a certificate manager keeping its account key and
+// certificates in cfg.CacheDir.
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Domain == "" || strings.ContainsAny(cfg.Domain, `/\`) {
+		return nil, fmt.Errorf("invalid ACME domain %q", cfg.Domain)
+	}
+	if cfg.DirectoryURL == "" {
+		cfg.DirectoryURL = LetsEncryptURL
+	}
+	if cfg.Challenge == "" {
+		cfg.Challenge = ChallengeTLSALPN01
+	}
+	if cfg.Challenge != ChallengeTLSALPN01 && cfg.Challenge != ChallengeHTTP01 {
+		return nil, fmt.Errorf("unsupported ACME challenge %q", cfg.Challenge)
+	}
+
+	httpClient, err := newHTTPClient(cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.CacheDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create ACME cache directory: %w", err)
+	}
+
+	m := &Manager{
+		cfg:    cfg,
+		domain: strings.ToLower(strings.TrimSuffix(cfg.Domain, ".")),
+		autocert: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cfg.CacheDir),
+			HostPolicy: autocert.HostWhitelist(cfg.Domain),
+			Email:      cfg.Email,
+			Client:     &acme.Client{DirectoryURL: cfg.DirectoryURL, HTTPClient: httpClient},
+		},
+	}
+	// autocert only attempts http-01 once its handler exists, so create it
+	// before the first certificate request
+	if cfg.Challenge == ChallengeHTTP01 {
+		m.httpHandler = m.autocert.HTTPHandler(http.HandlerFunc(refuseHTTP))
+	}
+	return m, nil
+}
+
+// GetCertificate serves the domain certificate, or the challenge certificate to
+// tls-alpn-01 validation connections.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return m.autocert.GetCertificate(hello)
+}
+
+// TLSConfig returns a copy of base serving the managed certificate. tls-alpn-01
+// validation connections get a dedicated configuration, without client
+// certificate verification.
+func (m *Manager) TLSConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	cfg.GetCertificate = m.GetCertificate
+	cfg.NextProtos = append(cfg.NextProtos, acme.ALPNProto)
+
+	challengeConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{acme.ALPNProto},
+		GetCertificate: m.GetCertificate,
+	}
+	cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+			return challengeConfig, nil
+		}
+		return nil, nil
+	}
+	return cfg
+}
+
+// HTTPHandler answers http-01 challenges. Every other request is refused, so
+// the webhook API is never served over plain HTTP.
+func (m *Manager) HTTPHandler() http.Handler {
+	if m.httpHandler == nil {
+		return http.HandlerFunc(refuseHTTP)
+	}
+	return m.httpHandler
+}
+
+// NotAfter returns the expiry of the cached certificate (zero if none).
+func (m *Manager) NotAfter() time.Time {
+	data, err := m.autocert.Cache.Get(context.Background(), m.domain)
+	if err != nil {
+		return time.Time{}
+	}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}
+		}
+		return leaf.NotAfter
+	}
+	return time.Time{}
+}
+
+// Run requests the certificate at startup instead of waiting for the first
+// client, then checks it twice a day until ctx is done. Failures are retried
+// with exponential backoff.
+func (m *Manager) Run(ctx context.Context) {
+	const minRetry, maxRetry = time.Minute, time.Hour
+	retry := minRetry
+	hello := &tls.ClientHelloInfo{
+		ServerName:   m.domain,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}
+
+	for {
+		wait := 12 * time.Hour
+		if _, err := m.autocert.GetCertificate(hello); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.WithField("domain", m.cfg.Domain).
+				WithField("retry_in", retry.String()).
+				WithField("error", err).
+				Error("Failed to obtain ACME certificate")
+			wait = retry
+			retry = min(2*retry, maxRetry)
+		} else {
+			retry = minRetry
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// refuseHTTP rejects requests that are not ACME challenges.
+func refuseHTTP(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "HTTPS required", http.StatusForbidden)
+}
+
+// newHTTPClient returns the client used to reach the ACME server, trusting caFile
+// in addition to the system roots when set.
+func newHTTPClient(caFile string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		data, err := os.ReadFile(caFile) //nolint:gosec // path comes from service configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ACME CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in ACME CA bundle %s", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
@@ -0,0 +1,163 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeCachedCertificate stores a self-signed certificate for domain in dir, in
+// the layout autocert.DirCache uses.
+func writeCachedCertificate(t *testing.T, dir, domain string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	if err := os.WriteFile(filepath.Join(dir, domain), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestManager(t *testing.T, challenge string) *Manager {
+	t.Helper()
+	m, err := NewManager(Config{
+		DirectoryURL: "https://127.0.0.1:1/dir", // Never contacted
+		Domain:       "example.com",
+		Challenge:    challenge,
+		CacheDir:     filepath.Join(t.TempDir(), "acme"),
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return m
+}
+
+func TestNewManager_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing domain", Config{CacheDir: t.TempDir()}},
+		{"domain with path", Config{Domain: "../etc", CacheDir: t.TempDir()}},
+		{"unknown challenge", Config{Domain: "example.com", Challenge: "dns-01", CacheDir: t.TempDir()}},
+		{"missing CA bundle", Config{Domain: "example.com", CacheDir: t.TempDir(), CAFile: "/nonexistent/ca.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewManager(tt.cfg); err == nil {
+				t.Error("NewManager() should fail")
+			}
+		})
+	}
+}
+
+func TestManager_CachedCertificate(t *testing.T) {
+	m := newTestManager(t, ChallengeTLSALPN01)
+	if !m.NotAfter().IsZero() {
+		t.Errorf("NotAfter() = %v, want zero without a cached certificate", m.NotAfter())
+	}
+
+	notAfter := time.Now().Add(60 * 24 * time.Hour).Truncate(time.Second)
+	writeCachedCertificate(t, m.cfg.CacheDir, "example.com", notAfter)
+
+	if got := m.NotAfter(); !got.Equal(notAfter) {
+		t.Errorf("NotAfter() = %v, want %v", got, notAfter)
+	}
+
+	// The cached certificate is served without contacting the CA
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:   "example.com",
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	})
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	if cert.Leaf == nil || !cert.Leaf.NotAfter.Equal(notAfter) {
+		t.Error("GetCertificate() did not serve the cached certificate")
+	}
+
+	if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example.com"}); err == nil {
+		t.Error("GetCertificate() should refuse other domains")
+	}
+}
+
+func TestManager_HTTPHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		path      string
+		want      int
+	}{
+		{"API over HTTP", ChallengeHTTP01, "/webhook", http.StatusForbidden},
+		{"unknown token", ChallengeHTTP01, "/.well-known/acme-challenge/unknown", http.StatusNotFound},
+		{"http-01 disabled", ChallengeTLSALPN01, "/.well-known/acme-challenge/unknown", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, tt.challenge)
+			rec := httptest.NewRecorder()
+			m.HTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com"+tt.path, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_TLSConfig(t *testing.T) {
+	m := newTestManager(t, ChallengeTLSALPN01)
+	base := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"h2"}, ClientAuth: tls.RequireAndVerifyClientCert}
+
+	cfg := m.TLSConfig(base)
+	if !slices.Equal(cfg.NextProtos, []string{"h2", "acme-tls/1"}) {
+		t.Errorf("NextProtos = %v", cfg.NextProtos)
+	}
+	if len(base.NextProtos) != 1 {
+		t.Error("TLSConfig() modified the base configuration")
+	}
+
+	challenge, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"acme-tls/1"}})
+	if err != nil || challenge == nil {
+		t.Fatalf("GetConfigForClient() = %v, %v, want the challenge configuration", challenge, err)
+	}
+	if challenge.ClientAuth != tls.NoClientCert {
+		t.Error("validation connections must not require a client certificate")
+	}
+
+	if regular, _ := cfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"h2"}}); regular != nil {
+		t.Error("regular connections should keep the base configuration")
+	}
+}
@@ -0,0 +1,228 @@
+// Package audit provides a tamper-evident audit log: an append-only file of JSON
+// records, each holding the hash of the previous one, so that editing, removing or
+// reordering records breaks the chain.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Audit events.
+const (
+	EventRequest = "request" // Signed webhook request and the response it got
+	EventCancel  = "cancel"  // Signed job cancel request and the response it got
+	EventCommand = "command" // Privileged system command run by a job
+	EventJob     = "job"     // Final outcome of a job
+)
+
+// GenesisHash is the previous hash of the first record.
+var GenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Record is an audit log entry.
+type Record struct {
+	Seq            uint64    `json:"seq"`
+	Time           time.Time `json:"time"`
+	Event          string    `json:"event"`
+	SourceIP       string    `json:"source_ip,omitempty"`
+	ForwardedFor   string    `json:"forwarded_for,omitempty"` // Client addresses claimed by proxies, unverified
+	KeyID          string    `json:"key_id,omitempty"`
+	ClientIdentity string    `json:"client_identity,omitempty"`
+	Action         string    `json:"action,omitempty"`
+	Module         string    `json:"module,omitempty"`
+	JobID          string    `json:"job_id,omitempty"`
+	Step           string    `json:"step,omitempty"`
+	Command        string    `json:"command,omitempty"`
+	ExitCode       *int      `json:"exit_code,omitempty"`
+	HTTPStatus     int       `json:"http_status,omitempty"`
+	Outcome        string    `json:"outcome,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	PrevHash       string    `json:"prev_hash"`
+	// Hash is the SHA-256 of the record line up to the hash field, chained with PrevHash
+	Hash string `json:"-"`
+}
+
+// Each record line ends with its hash field: `,"hash":"<hex>"}`.
+const (
+	hashPrefix = `,"hash":"`
+	hashLen    = 2 * sha256.Size
+	suffixLen  = len(hashPrefix) + hashLen + len(`"}`)
+)
+
+// encode returns the record line, without newline, and sets its hash.
+func (r *Record) encode() ([]byte, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	r.Hash = hashOf(body)
+
+	line := make([]byte, 0, len(body)+suffixLen)
+	line = append(line, body[:len(body)-1]...)
+	line = append(line, hashPrefix...)
+	line = append(line, r.Hash...)
+	return append(line, `"}`...), nil
+}
+
+// hashOf returns the hash of a record body: the record line without its hash field.
+func hashOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// decode parses a record line and checks that its hash matches its content.
+func decode(line []byte) (Record, error) {
+	var rec Record
+	if len(line) < suffixLen || !bytes.HasSuffix(line, []byte(`"}`)) ||
+		!bytes.Equal(line[len(line)-suffixLen:len(line)-suffixLen+len(hashPrefix)], []byte(hashPrefix)) {
+		return rec, errors.New("missing record hash")
+	}
+
+	hash := string(line[len(line)-hashLen-2 : len(line)-2])
+	body := append(append([]byte(nil), line[:len(line)-suffixLen]...), '}')
+	if err := json.Unmarshal(body, &rec); err != nil {
+		return rec, fmt.Errorf("invalid record: %w", err)
+	}
+	if hashOf(body) != hash {
+		return rec, errors.New("record hash does not match its content")
+	}
+	rec.Hash = hash
+	return rec, nil
+}
+
+// tailChunkSize is how much of the log is read at a time when looking for its
+// last record.
+const tailChunkSize = 64 << 10
+
+// Log is an append-only, hash-chained audit log file. It is safe for concurrent use.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      uint64
+	lastHash string
+}
+
+// Open opens the audit log at path, creating it if needed, to append records
+// after the last one.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	l := &Log{path: path, lastHash: GenesisHash}
+	last, err := lastRecord(path)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		l.seq, l.lastHash = last.Seq, last.Hash
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) //nolint:gosec // Configured path
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// lastRecord returns the last record of the log file, or nil when it is missing or empty.
+func lastRecord(path string) (*Record, error) {
+	file, err := os.Open(path) //nolint:gosec // Configured path
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Read the file backwards, a chunk at a time, until the line before the last
+	// record starts
+	var tail []byte
+	end := info.Size()
+	for offset := end; offset > 0; {
+		n := min(offset, tailChunkSize)
+		offset -= n
+		chunk := make([]byte, n, n+int64(len(tail)))
+		if _, err := file.ReadAt(chunk, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		tail = bytes.TrimRight(append(chunk, tail...), "\n")
+		if i := bytes.LastIndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+			break
+		}
+	}
+	if len(tail) == 0 {
+		return nil, nil
+	}
+	rec, err := decode(tail)
+	if err != nil {
+		return nil, fmt.Errorf("last audit record is corrupted, run --verify-audit: %w", err)
+	}
+	return &rec, nil
+}
+
+// Path returns the file the log is written to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains rec to the previous record and writes it to disk.
+func (l *Log) Append(rec Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	rec.Seq = l.seq + 1
+	rec.PrevHash = l.lastHash
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	rec.Time = rec.Time.UTC()
+	line, err := rec.encode()
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	l.seq, l.lastHash = rec.Seq, rec.Hash
+	return nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
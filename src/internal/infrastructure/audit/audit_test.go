@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog appends records with the given events to a new log and returns its path.
+func writeLog(t *testing.T, events ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, event := range events {
+		if err := l.Append(Record{Event: event, JobID: "job-1"}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return path
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	return lines[:len(lines)-1] // Every line ends with a newline
+}
+
+func writeLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+	data := bytes.Join(lines, nil)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+}
+
+func TestLog_AppendAndVerify(t *testing.T) {
+	path := writeLog(t, EventRequest, EventCommand, EventJob)
+
+	summary, err := VerifyFile(path)
+	if err != nil {
+		t.Fatalf("VerifyFile() error = %v", err)
+	}
+	if summary.Records != 3 || summary.LastHash == GenesisHash {
+		t.Errorf("VerifyFile() = %+v, want 3 records", summary)
+	}
+
+	lines := readLines(t, path)
+	first, err := decode(bytes.TrimSpace(lines[0]))
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if first.Seq != 1 || first.PrevHash != GenesisHash || first.Event != EventRequest || first.Time.IsZero() {
+		t.Errorf("first record = %+v", first)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(lines[0])), `,"hash":"`+first.Hash+`"}`) {
+		t.Errorf("record line does not end with its hash: %s", lines[0])
+	}
+}
+
+func TestLog_Reopen(t *testing.T) {
+	path := writeLog(t, EventRequest, EventJob)
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := l.Append(Record{Event: EventRequest}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	_ = l.Close()
+	if err := l.Append(Record{Event: EventRequest}); err == nil {
+		t.Error("Append() after Close() should fail")
+	}
+
+	summary, err := VerifyFile(path)
+	if err != nil || summary.Records != 3 {
+		t.Errorf("VerifyFile() = %+v, %v, want 3 chained records", summary, err)
+	}
+}
+
+func TestLog_ReopenLargeRecords(t *testing.T) {
+	path := writeLog(t, EventRequest)
+
+	// Records larger than the chunks the last record is looked for in
+	for _, size := range []int{3 * tailChunkSize, tailChunkSize - 10} {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		if err := l.Append(Record{Event: EventCommand, Error: strings.Repeat("x", size)}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		_ = l.Close()
+	}
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() after a large record error = %v", err)
+	}
+	if l.seq != 3 {
+		t.Errorf("reopened log continues after record %d, want 3", l.seq)
+	}
+	if err := l.Append(Record{Event: EventJob}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	_ = l.Close()
+
+	summary, err := VerifyFile(path)
+	if err != nil || summary.Records != 4 {
+		t.Errorf("VerifyFile() = %+v, %v, want 4 chained records", summary, err)
+	}
+}
+
+func TestVerify_Tampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(lines [][]byte) [][]byte
+		wantLine int
+	}{
+		{
+			name: "edited record",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(`"job-1"`), []byte(`"job-2"`), 1)
+				return lines
+			},
+			wantLine: 2,
+		},
+		{
+			name: "edited record with recomputed hash",
+			tamper: func(lines [][]byte) [][]byte {
+				line := bytes.TrimSpace(lines[1])
+				body := append(bytes.Replace(line[:len(line)-suffixLen], []byte(`"job-1"`), []byte(`"job-2"`), 1), '}')
+				forged := append(body[:len(body)-1:len(body)-1], hashPrefix+hashOf(body)+"\"}\n"...)
+				lines[1] = forged
+				return lines
+			},
+			wantLine: 3,
+		},
+		{
+			name:     "removed record",
+			tamper:   func(lines [][]byte) [][]byte { return append(lines[:1], lines[2:]...) },
+			wantLine: 2,
+		},
+		{
+			name: "reordered records",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+			wantLine: 2,
+		},
+		{
+			name: "garbage line",
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines, []byte("not a record\n"))
+			},
+			wantLine: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeLog(t, EventRequest, EventCommand, EventJob)
+			writeLines(t, path, tt.tamper(readLines(t, path)))
+
+			_, err := VerifyFile(path)
+			var verifyErr *VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("VerifyFile() error = %v, want a VerifyError", err)
+			}
+			if verifyErr.Line != tt.wantLine {
+				t.Errorf("VerifyFile() error = %v, want line %d", err, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestVerify_Empty(t *testing.T) {
+	summary, err := Verify(strings.NewReader(""))
+	if err != nil || summary.Records != 0 || summary.LastHash != GenesisHash {
+		t.Errorf("Verify(empty) = %+v, %v", summary, err)
+	}
+	if _, err := VerifyFile(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("VerifyFile() of a missing file should fail")
+	}
+}
+
+func TestOpen_CorruptedLastRecord(t *testing.T) {
+	path := writeLog(t, EventRequest)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	_, _ = f.WriteString(`{"seq":2,"event":"req`)
+	_ = f.Close()
+
+	if _, err := Open(path); err == nil {
+		t.Error("Open() should refuse to extend a log with a corrupted last record")
+	}
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// maxErrorLength bounds the error message of a record. The error of a failed
+// command carries its output, which the job log keeps, and a record must stay
+// small enough to be found again when the log is reopened.
+const maxErrorLength = 1024
+
+// current is the log records are written to, nil when auditing is disabled.
+var current atomic.Pointer[Log]
+
+// SetDefault sets the log Write records to. A nil log disables auditing.
+func SetDefault(l *Log) {
+	current.Store(l)
+}
+
+// Write appends a record to the default log. Failures are logged: auditing never
+// stops the service.
+func Write(rec Record) {
+	l := current.Load()
+	if l == nil {
+		return
+	}
+	if err := l.Append(rec); err != nil {
+		logger.WithField("event", rec.Event).WithField("error", err).Error("Failed to write audit record")
+	}
+}
+
+type jobKey struct{}
+
+// WithJobID returns a context recording that its commands are run by the job.
+func WithJobID(ctx context.Context, jobID string) context.Context {
+	return context.WithValue(ctx, jobKey{}, jobID)
+}
+
+// JobIDFromContext returns the job running the commands of ctx, or "".
+func JobIDFromContext(ctx context.Context) string {
+	jobID, _ := ctx.Value(jobKey{}).(string)
+	return jobID
+}
+
+// Command records a system command run on behalf of the job in ctx, with its exit code.
+func Command(ctx context.Context, step, command string, exitCode int, err error) {
+	rec := Record{
+		Event:    EventCommand,
+		JobID:    JobIDFromContext(ctx),
+		Step:     step,
+		Command:  command,
+		ExitCode: &exitCode,
+		Outcome:  "succeeded",
+	}
+	if err != nil {
+		rec.Outcome = "failed"
+		rec.Error = errorMessage(err)
+	}
+	Write(rec)
+}
+
+// Job records the final outcome of a job. Jobs that are not finished yet, such as
+// reboots waiting for the system to restart, are ignored.
+func Job(job entity.Job) {
+	if !job.Status.IsFinal() {
+		return
+	}
+
+	rec := Record{
+		Event:          EventJob,
+		JobID:          job.ID,
+		Action:         string(job.Action),
+		ClientIdentity: job.ClientIdentity,
+		Outcome:        string(job.Status),
+	}
+	if job.Request != nil {
+		rec.Module = job.Request.Module
+	}
+	if job.Result != nil {
+		rec.ExitCode = &job.Result.ExitCode
+	}
+	if job.Error != nil {
+		rec.Error = errorMessage(job.Error)
+	}
+	Write(rec)
+}
+
+// errorMessage returns the message of err, cut to maxErrorLength.
+func errorMessage(err error) string {
+	msg := err.Error()
+	if len(msg) <= maxErrorLength {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxErrorLength], "") + "..."
+}
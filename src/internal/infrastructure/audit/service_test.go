@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// useLog makes a new log the default one until the test ends.
+func useLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	SetDefault(l)
+	t.Cleanup(func() {
+		SetDefault(nil)
+		_ = l.Close()
+	})
+	return path
+}
+
+// records returns the records of the log at path.
+func records(t *testing.T, path string) []Record {
+	t.Helper()
+	f, err := os.Open(path) //nolint:gosec // Test file
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var recs []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		rec, err := decode(scanner.Bytes())
+		if err != nil {
+			t.Fatalf("decode() error = %v", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestWrite_Disabled(t *testing.T) {
+	SetDefault(nil)
+	Write(Record{Event: EventRequest}) // Must not panic
+}
+
+func TestCommand(t *testing.T) {
+	path := useLog(t)
+
+	ctx := WithJobID(context.Background(), "job-1")
+	Command(ctx, "update_system", "apt-get update", 0, nil)
+	Command(ctx, "upgrade_system", "apt-get upgrade -y", 100, errors.New("exit status 100"))
+
+	recs := records(t, path)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	ok, failed := recs[0], recs[1]
+	if ok.Event != EventCommand || ok.JobID != "job-1" || ok.Command != "apt-get update" ||
+		ok.ExitCode == nil || *ok.ExitCode != 0 || ok.Outcome != "succeeded" {
+		t.Errorf("successful command record = %+v", ok)
+	}
+	if failed.Step != "upgrade_system" || *failed.ExitCode != 100 || failed.Outcome != "failed" ||
+		failed.Error != "exit status 100" {
+		t.Errorf("failed command record = %+v", failed)
+	}
+}
+
+func TestCommand_LongError(t *testing.T) {
+	path := useLog(t)
+
+	Command(context.Background(), "upgrade_system", "apt-get upgrade -y", 100,
+		errors.New(strings.Repeat("E: unmet dependencies\n", 10000)))
+
+	recs := records(t, path)
+	if len(recs) != 1 || len(recs[0].Error) > maxErrorLength+len("...") {
+		t.Errorf("command record error is %d bytes, want at most %d", len(recs[0].Error), maxErrorLength)
+	}
+}
+
+func TestJob(t *testing.T) {
+	path := useLog(t)
+
+	running := entity.NewJob("job-1", entity.ActionUpgrade)
+	Job(running.Snapshot()) // Not finished: ignored
+
+	result := entity.NewActionResult(entity.ActionUpgrade)
+	result.Fail(errors.New("upgrade_system failed"))
+	job := running.Snapshot()
+	job.Status = entity.JobStatusFailed
+	job.Result = result
+	job.Error = result.Err
+	job.ClientIdentity = "CN=ci"
+	job.Request = &entity.WebhookRequest{Action: entity.ActionUpgrade, Module: "nginx"}
+	Job(job)
+
+	recs := records(t, path)
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want only the finished job", len(recs))
+	}
+	rec := recs[0]
+	if rec.Event != EventJob || rec.JobID != "job-1" || rec.Action != "upgrade" || rec.Outcome != "failed" ||
+		rec.Error != "upgrade_system failed" || rec.ClientIdentity != "CN=ci" || rec.Module != "nginx" ||
+		rec.ExitCode == nil {
+		t.Errorf("job record = %+v", rec)
+	}
+}
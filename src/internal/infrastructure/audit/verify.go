@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Summary describes a verified audit log.
+type Summary struct {
+	Records  uint64 // Number of records
+	LastHash string // Hash of the last record, GenesisHash for an empty log
+}
+
+// VerifyError reports the first record breaking the chain.
+type VerifyError struct {
+	Line   int // 1-based line number in the file
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Verify reads an audit log and checks that every record is intact and chained to
+// the previous one, in sequence. It returns a *VerifyError for the first bad record.
+func Verify(r io.Reader) (Summary, error) {
+	summary := Summary{LastHash: GenesisHash}
+	reader := bufio.NewReader(r)
+
+	line := 0
+	for {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(data) == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return summary, &VerifyError{Line: line + 1, Reason: err.Error()}
+		}
+		line++
+		rec, err := decode(bytes.TrimSuffix(data, []byte("\n")))
+		if err != nil {
+			return summary, &VerifyError{Line: line, Reason: err.Error()}
+		}
+		if rec.Seq != summary.Records+1 {
+			return summary, &VerifyError{
+				Line:   line,
+				Reason: fmt.Sprintf("sequence %d, expected %d (record removed or reordered)", rec.Seq, summary.Records+1),
+			}
+		}
+		if rec.PrevHash != summary.LastHash {
+			return summary, &VerifyError{Line: line, Reason: "previous hash does not match the previous record"}
+		}
+		summary.Records, summary.LastHash = rec.Seq, rec.Hash
+	}
+	return summary, nil
+}
+
+// VerifyFile verifies the audit log at path.
+func VerifyFile(path string) (Summary, error) {
+	file, err := os.Open(path) //nolint:gosec // Path given by the operator
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	return Verify(file)
+}
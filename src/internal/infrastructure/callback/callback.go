@@ -0,0 +1,330 @@
+// Package callback delivers completion callbacks: once a job finished, its final
+// record is POSTed to the callback URL of its request, or the configured one, and
+// signed like inbound webhook requests.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+)
+
+// Defaults of the delivery settings.
+const (
+	DefaultMaxAttempts = 5
+	DefaultTimeout     = 10 * time.Second
+	DefaultRetryDelay  = 10 * time.Second
+	// maxRetryDelay caps the exponential backoff between attempts
+	maxRetryDelay = 5 * time.Minute
+	// maxURLLength bounds callback URLs given by requests
+	maxURLLength = 2048
+)
+
+// Config holds the completion callback settings.
+type Config struct {
+	URL         string        // Callback URL of jobs requested without one (empty: none)
+	KeyID       string        // Sent in the key ID header, names Secret to the receiver
+	Secret      string        // HMAC secret signing callbacks
+	MaxAttempts int           // Delivery attempts before giving up
+	Timeout     time.Duration // Bound of each attempt
+	RetryDelay  time.Duration // Delay before the second attempt, doubled after each failure
+	// AllowedNetworks are the loopback, private or link-local networks callbacks may
+	// be delivered to, all of them being refused otherwise
+	AllowedNetworks []netip.Prefix
+}
+
+// errForbiddenAddress is returned by attempts to deliver a callback to a local
+// address out of the allowed networks.
+var errForbiddenAddress = errors.New("callback to a loopback, private or link-local address refused")
+
+// Recorder records the delivery attempts of the callback of a job.
+type Recorder interface {
+	RecordCallbackAttempt(jobID string, attempt entity.CallbackAttempt)
+}
+
+// Payload is the body of a callback: the final record of a job, with the fields
+// of /job/status, and the time it was signed.
+type Payload struct {
+	JobID          string              `json:"job_id"`
+	Action         entity.ActionType   `json:"action"`
+	Module         string              `json:"module,omitempty"`
+	Status         entity.JobStatus    `json:"status"`
+	Started        time.Time           `json:"started"`
+	Completed      *time.Time          `json:"completed,omitempty"`
+	ClientIdentity string              `json:"client_identity,omitempty"`
+	Error          string              `json:"error,omitempty"`
+	ExitCode       *int                `json:"exit_code,omitempty"`
+	Steps          []entity.StepResult `json:"steps,omitempty"`
+	Attempt        int                 `json:"attempt"`
+	// Timestamp is when the callback was signed (seconds since the Unix epoch), so
+	// receivers can reject replays like cloud-update does
+	Timestamp int64 `json:"timestamp"`
+}
+
+func newPayload(job entity.Job, attempt int, now time.Time) Payload {
+	p := Payload{
+		JobID:          job.ID,
+		Action:         job.Action,
+		Status:         job.Status,
+		Started:        job.StartTime,
+		Completed:      job.EndTime,
+		ClientIdentity: job.ClientIdentity,
+		Attempt:        attempt,
+		Timestamp:      now.Unix(),
+	}
+	if job.Request != nil {
+		p.Module = job.Request.Module
+	}
+	if job.Error != nil {
+		p.Error = job.Error.Error()
+	}
+	if job.Result != nil {
+		p.ExitCode = &job.Result.ExitCode
+		p.Steps = job.Result.Steps
+	}
+	return p
+}
+
+// ValidateURL checks that raw is an absolute http or https URL.
+func ValidateURL(raw string) error {
+	if len(raw) > maxURLLength {
+		return fmt.Errorf("callback URL longer than %d characters", maxURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("callback URL must be an http or https URL, got %q", raw)
+	}
+	return nil
+}
+
+// Notifier delivers completion callbacks in the background, retrying failed
+// deliveries with exponential backoff.
+type Notifier struct {
+	client *http.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	cfg    Config
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New creates a notifier. Zero-valued delivery settings take their defaults.
+func New(cfg Config) (*Notifier, error) {
+	cfg, err := withDefaults(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{cfg: cfg, ctx: ctx, cancel: cancel}
+	// Addresses are checked once resolved, so that a host name cannot lead callbacks
+	// to the services of the host or its network; a proxy would hide them
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: n.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck // Always an *http.Transport
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	n.client = &http.Client{
+		Transport: transport,
+		// A redirect could send the signed job record elsewhere
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return n, nil
+}
+
+// checkAddress refuses connections to loopback, private and link-local addresses
+// out of the allowed networks.
+func (n *Notifier) checkAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid callback address %q: %w", address, err)
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsLoopback() && !addr.IsPrivate() && !addr.IsLinkLocalUnicast() && !addr.IsUnspecified() {
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, network := range n.cfg.AllowedNetworks {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", errForbiddenAddress, addr)
+}
+
+// withDefaults validates cfg and sets the defaults of its zero-valued delivery settings.
+func withDefaults(cfg Config) (Config, error) {
+	if cfg.Secret == "" {
+		return cfg, errors.New("callback secret cannot be empty")
+	}
+	if cfg.URL != "" {
+		if err := ValidateURL(cfg.URL); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+	return cfg, nil
+}
+
+// Update replaces the settings, such as a rotated secret. Deliveries in progress
+// sign their next attempts with the new key and keep their other settings.
+func (n *Notifier) Update(cfg Config) error {
+	cfg, err := withDefaults(cfg)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cfg = cfg
+	return nil
+}
+
+// key returns the key signing callbacks.
+func (n *Notifier) key() security.HMACKey {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return security.HMACKey{ID: n.cfg.KeyID, Secret: n.cfg.Secret}
+}
+
+// Notify delivers the final record of job in the background, recording each
+// attempt with recorder. Unfinished jobs, such as reboots waiting for the system
+// to restart, and jobs without callback URL are ignored.
+func (n *Notifier) Notify(job entity.Job, recorder Recorder) {
+	if !job.Status.IsFinal() {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	cfg := n.cfg
+	target := job.CallbackURL
+	if target == "" {
+		target = cfg.URL
+	}
+	if target == "" {
+		return
+	}
+	if n.closed {
+		logger.WithField("job_id", job.ID).Warn("Service stopping, job callback not delivered")
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.deliver(cfg, target, job, recorder)
+	}()
+}
+
+// deliver sends the callback until it is delivered, fails permanently or runs out
+// of attempts.
+func (n *Notifier) deliver(cfg Config, target string, job entity.Job, recorder Recorder) {
+	delay := cfg.RetryDelay
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		status, err := n.send(target, job, attempt, cfg.Timeout)
+		rec := entity.CallbackAttempt{Attempt: attempt, Time: start, URL: target, StatusCode: status}
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		recorder.RecordCallbackAttempt(job.ID, rec)
+
+		log := logger.WithField("job_id", job.ID).WithField("attempt", attempt).WithField("status_code", status)
+		if err == nil {
+			log.Info("Job callback delivered")
+			return
+		}
+		log = log.WithField("error", err)
+		if attempt >= cfg.MaxAttempts || !retryable(status) || errors.Is(err, errForbiddenAddress) {
+			log.Error("Job callback not delivered, giving up")
+			return
+		}
+		log.WithField("retry_in", delay).Warn("Job callback failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-n.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// retryable reports whether a failed attempt may succeed later: the receiver was
+// unreachable, overloaded or failing.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests ||
+		status >= http.StatusInternalServerError
+}
+
+// send POSTs the signed job record and returns the response status.
+func (n *Notifier) send(target string, job entity.Job, attempt int, timeout time.Duration) (int, error) {
+	body, err := json.Marshal(newPayload(job, attempt, time.Now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode callback: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Job-ID", job.ID)
+	key := n.key()
+	req.Header.Set(security.SignatureHeader, key.Sign(body))
+	if key.ID != "" {
+		req.Header.Set(security.KeyIDHeader, key.ID)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // Drain to reuse the connection
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Close stops retrying and waits for the attempts in progress.
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	n.closed = true
+	n.mu.Unlock()
+
+	n.cancel()
+	n.wg.Wait()
+}
@@ -0,0 +1,294 @@
+package callback
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/security"
+)
+
+const testSecret = "test-callback-secret-with-32-chars!"
+
+// loopback lets test notifiers deliver to httptest servers.
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+// attemptRecorder records delivery attempts, signaling each one.
+type attemptRecorder struct {
+	mu       sync.Mutex
+	attempts []entity.CallbackAttempt
+	recorded chan struct{}
+}
+
+func newAttemptRecorder() *attemptRecorder {
+	return &attemptRecorder{recorded: make(chan struct{}, 16)}
+}
+
+func (r *attemptRecorder) RecordCallbackAttempt(_ string, attempt entity.CallbackAttempt) {
+	r.mu.Lock()
+	r.attempts = append(r.attempts, attempt)
+	r.mu.Unlock()
+	r.recorded <- struct{}{}
+}
+
+// wait returns the attempts once n were recorded.
+func (r *attemptRecorder) wait(t *testing.T, n int) []entity.CallbackAttempt {
+	t.Helper()
+	for range n {
+		select {
+		case <-r.recorded:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %d callback attempts", n)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entity.CallbackAttempt(nil), r.attempts...)
+}
+
+func newTestNotifier(t *testing.T, url string, maxAttempts int) *Notifier {
+	t.Helper()
+	n, err := New(Config{
+		URL: url, KeyID: "callback", Secret: testSecret,
+		MaxAttempts: maxAttempts, RetryDelay: time.Millisecond, AllowedNetworks: loopback,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(n.Close)
+	return n
+}
+
+func finishedJob() entity.Job {
+	job := entity.NewJob("job-1", entity.ActionUpgrade).Snapshot()
+	result := entity.NewActionResult(entity.ActionUpgrade)
+	result.Fail(errors.New("upgrade_system failed"))
+	job.Status = entity.JobStatusFailed
+	job.Result = result
+	job.Error = result.Err
+	job.Request = &entity.WebhookRequest{Action: entity.ActionUpgrade, Module: "nginx"}
+	return job
+}
+
+func TestNotifier_SignedDelivery(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		key := security.HMACKey{ID: "callback", Secret: testSecret}
+		if r.Header.Get(security.SignatureHeader) != key.Sign(body) || r.Header.Get(security.KeyIDHeader) != "callback" ||
+			r.Header.Get("X-Job-ID") != "job-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	recorder := newAttemptRecorder()
+	newTestNotifier(t, server.URL, 3).Notify(finishedJob(), recorder)
+
+	attempts := recorder.wait(t, 1)
+	if !attempts[0].Delivered() || attempts[0].StatusCode != http.StatusNoContent || attempts[0].URL != server.URL {
+		t.Fatalf("attempt = %+v, want a delivered callback", attempts[0])
+	}
+	if got.JobID != "job-1" || got.Status != entity.JobStatusFailed || got.Module != "nginx" ||
+		got.Error != "upgrade_system failed" || got.ExitCode == nil || got.Attempt != 1 || got.Timestamp == 0 {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestNotifier_Update(t *testing.T) {
+	const rotated = "rotated-callback-secret-with-32-chars"
+	signatures := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(security.SignatureHeader) == (security.HMACKey{Secret: rotated}).Sign(body) {
+			signatures <- r.Header.Get(security.KeyIDHeader)
+		} else {
+			signatures <- "invalid"
+		}
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(t, server.URL, 1)
+	if err := n.Update(Config{URL: server.URL}); err == nil {
+		t.Error("Update() without secret should fail")
+	}
+	if err := n.Update(Config{URL: server.URL, KeyID: "default", Secret: rotated, AllowedNetworks: loopback}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	recorder := newAttemptRecorder()
+	n.Notify(finishedJob(), recorder)
+	recorder.wait(t, 1)
+	if keyID := <-signatures; keyID != "default" {
+		t.Errorf("callback signed with key %q, want the rotated default key", keyID)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantLast     int
+		delivered    bool
+	}{
+		{"retried until delivered", []int{503, 429, 200}, 3, 200, true},
+		{"gives up after max attempts", []int{500, 502, 504, 500}, 3, 504, false},
+		{"permanent failure", []int{400, 200}, 1, 400, false},
+		{"redirect not followed", []int{302, 200}, 1, 302, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Location", "/elsewhere")
+				w.WriteHeader(tt.statuses[calls.Add(1)-1])
+			}))
+			defer server.Close()
+
+			recorder := newAttemptRecorder()
+			newTestNotifier(t, server.URL, 3).Notify(finishedJob(), recorder)
+
+			attempts := recorder.wait(t, tt.wantAttempts)
+			time.Sleep(20 * time.Millisecond) // No further attempt
+			if int(calls.Load()) != tt.wantAttempts {
+				t.Fatalf("got %d attempts, want %d", calls.Load(), tt.wantAttempts)
+			}
+			last := attempts[len(attempts)-1]
+			if last.Attempt != tt.wantAttempts || last.StatusCode != tt.wantLast || last.Delivered() != tt.delivered {
+				t.Errorf("last attempt = %+v", last)
+			}
+		})
+	}
+}
+
+func TestNotifier_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	recorder := newAttemptRecorder()
+	newTestNotifier(t, url, 2).Notify(finishedJob(), recorder)
+
+	attempts := recorder.wait(t, 2)
+	if attempts[1].Delivered() || attempts[1].StatusCode != 0 || attempts[1].Error == "" {
+		t.Errorf("attempt = %+v, want a network error", attempts[1])
+	}
+}
+
+func TestNotifier_LocalAddressRefused(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	n, err := New(Config{URL: server.URL, Secret: testSecret, MaxAttempts: 3, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer n.Close()
+	recorder := newAttemptRecorder()
+	n.Notify(finishedJob(), recorder)
+
+	attempts := recorder.wait(t, 1)
+	if !strings.Contains(attempts[0].Error, "refused") {
+		t.Errorf("attempt = %+v, want the loopback address refused", attempts[0])
+	}
+	n.Close()
+	if len(recorder.wait(t, 0)) != 1 || calls.Load() != 0 {
+		t.Errorf("got %d callbacks, want none and no retry", calls.Load())
+	}
+}
+
+func TestNotifier_checkAddress(t *testing.T) {
+	n := &Notifier{cfg: Config{AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}}}
+	for _, address := range []string{"203.0.113.7:443", "[2001:db8::1]:443", "10.1.2.3:8080"} {
+		if err := n.checkAddress("tcp", address, nil); err != nil {
+			t.Errorf("checkAddress(%q) error = %v", address, err)
+		}
+	}
+	for _, address := range []string{
+		"127.0.0.1:80", "[::1]:80", "10.2.0.1:80", "192.168.1.1:80", "172.16.0.1:80",
+		"169.254.169.254:80", "[fe80::1]:80", "[fd00::1]:80", "0.0.0.0:80", "[::ffff:127.0.0.1]:80",
+	} {
+		if err := n.checkAddress("tcp", address, nil); !errors.Is(err, errForbiddenAddress) {
+			t.Errorf("checkAddress(%q) error = %v, want refused", address, err)
+		}
+	}
+}
+
+func TestNotifier_Skipped(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(t, "", 1)
+	recorder := newAttemptRecorder()
+
+	running := finishedJob()
+	running.Status = entity.JobStatusRunning
+	running.CallbackURL = server.URL
+	n.Notify(running, recorder)       // Not finished
+	n.Notify(finishedJob(), recorder) // No callback URL
+
+	job := finishedJob()
+	job.CallbackURL = server.URL
+	n.Notify(job, recorder)
+	recorder.wait(t, 1)
+	if calls.Load() != 1 {
+		t.Errorf("got %d callbacks, want only the finished job with a callback URL", calls.Load())
+	}
+}
+
+func TestDefault(t *testing.T) {
+	SetDefault(nil)
+	if Enabled() {
+		t.Error("Enabled() without notifier should be false")
+	}
+	Job(finishedJob(), newAttemptRecorder()) // Must not panic
+
+	n := newTestNotifier(t, "", 1)
+	SetDefault(n)
+	defer SetDefault(nil)
+	if !Enabled() {
+		t.Error("Enabled() with a notifier should be true")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("New() without secret should fail")
+	}
+	if _, err := New(Config{Secret: testSecret, URL: "ftp://example.com"}); err == nil {
+		t.Error("New() with a non-HTTP URL should fail")
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, raw := range []string{"https://ci.example.com/hooks/cloud-update", "http://10.0.0.1:8080/done"} {
+		if err := ValidateURL(raw); err != nil {
+			t.Errorf("ValidateURL(%q) error = %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"", "/relative", "file:///etc/passwd", "https://", "http://[::1", "ci.example.com"} {
+		if err := ValidateURL(raw); err == nil {
+			t.Errorf("ValidateURL(%q) should fail", raw)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package callback
+
+import (
+	"sync/atomic"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// current delivers the callbacks, nil when they are disabled.
+var current atomic.Pointer[Notifier]
+
+// SetDefault sets the notifier Job delivers callbacks with. A nil notifier
+// disables callbacks.
+func SetDefault(n *Notifier) {
+	current.Store(n)
+}
+
+// Enabled reports whether callbacks are delivered.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Job delivers the completion callback of a finished job with the default
+// notifier, recording the delivery attempts with recorder.
+func Job(job entity.Job, recorder Recorder) {
+	if n := current.Load(); n != nil {
+		n.Notify(job, recorder)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// DefaultExpiryWarning is how long before expiry a certificate is reported as expiring.
+const DefaultExpiryWarning = 14 * 24 * time.Hour
+
+// CertificateExpiry reports when the served certificate expires (zero when there is none).
+type CertificateExpiry interface {
+	NotAfter() time.Time
+}
+
+// CertificateStatus describes the remaining validity of a certificate.
+type CertificateStatus struct {
+	NotAfter time.Time
+	DaysLeft int    // Whole days until expiry, negative once expired
+	State    string // "valid", "expiring", "expired" or "missing"
+}
+
+// CheckExpiry returns the status of the certificate at now, which is expiring once
+// less than warnBefore remains.
+func CheckExpiry(cert CertificateExpiry, warnBefore time.Duration, now time.Time) CertificateStatus {
+	status := CertificateStatus{NotAfter: cert.NotAfter()}
+	if status.NotAfter.IsZero() {
+		status.State = "missing"
+		return status
+	}
+
+	left := status.NotAfter.Sub(now)
+	status.DaysLeft = int(left / (24 * time.Hour))
+	switch {
+	case left <= 0:
+		status.State = "expired"
+		if status.DaysLeft == 0 {
+			status.DaysLeft = -1
+		}
+	case left < warnBefore:
+		status.State = "expiring"
+	default:
+		status.State = "valid"
+	}
+	return status
+}
+
+// MonitorExpiry logs a warning every interval while the certificate is expiring or
+// expired, until ctx is done.
+func MonitorExpiry(ctx context.Context, cert CertificateExpiry, warnBefore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := CheckExpiry(cert, warnBefore, time.Now())
+		switch status.State {
+		case "expiring", "expired":
+			logger.WithField("not_after", status.NotAfter.Format(time.RFC3339)).
+				WithField("days_left", status.DaysLeft).
+				Warnf("TLS certificate is %s", status.State)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedExpiry time.Time
+
+func (f fixedExpiry) NotAfter() time.Time { return time.Time(f) }
+
+func TestCheckExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		notAfter  time.Time
+		wantState string
+		wantDays  int
+	}{
+		{"valid", now.Add(60 * 24 * time.Hour), "valid", 60},
+		{"expiring", now.Add(10*24*time.Hour + time.Hour), "expiring", 10},
+		{"expired", now.Add(-time.Hour), "expired", -1},
+		{"expired days ago", now.Add(-72 * time.Hour), "expired", -3},
+		{"missing", time.Time{}, "missing", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := CheckExpiry(fixedExpiry(tt.notAfter), DefaultExpiryWarning, now)
+			if status.State != tt.wantState || status.DaysLeft != tt.wantDays {
+				t.Errorf("CheckExpiry() = %s, %d days, want %s, %d days",
+					status.State, status.DaysLeft, tt.wantState, tt.wantDays)
+			}
+		})
+	}
+}
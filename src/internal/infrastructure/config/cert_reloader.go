@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// CertReloader holds the server certificate and reloads it from disk on demand.
+type CertReloader struct {
+	mu       sync.RWMutex
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+}
+
+// NewCertReloader loads the certificate and key from the given files.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{}
+	if err := r.Load(certFile, keyFile); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load replaces the certificate with the one read from the given files.
+// The current certificate is kept if the new one cannot be loaded.
+func (r *CertReloader) Load(certFile, keyFile string) error {
+	cert, err := LoadCertificate(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	r.Set(certFile, keyFile, cert)
+	return nil
+}
+
+// Set replaces the certificate with cert, read from the given files.
+func (r *CertReloader) Set(certFile, keyFile string, cert *tls.Certificate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certFile = certFile
+	r.keyFile = keyFile
+	r.cert = cert
+}
+
+// LoadCertificate reads a certificate and its key, so that it can be checked
+// before being served.
+func LoadCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+	return &cert, nil
+}
+
+// Reload reads the certificate again from its current files.
+func (r *CertReloader) Reload() error {
+	r.mu.RLock()
+	certFile, keyFile := r.certFile, r.keyFile
+	r.mu.RUnlock()
+
+	return r.Load(certFile, keyFile)
+}
+
+// GetCertificate returns the current certificate; it is meant for tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// NotAfter returns the expiry of the current certificate.
+func (r *CertReloader) NotAfter() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil || r.cert.Leaf == nil {
+		return time.Time{}
+	}
+	return r.cert.Leaf.NotAfter
+}
+
+// Watch reloads the certificate whenever its files change on disk, checking every
+// interval until ctx is done. A pair that fails to load, e.g. while only one of the
+// files has been replaced, keeps the current certificate and is retried.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	loaded := r.files()
+	var failed certFiles
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := r.files()
+		if current == loaded {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			if current != failed {
+				logger.WithField("cert_file", current.certFile).
+					WithField("error", err).
+					Warn("Changed TLS certificate could not be loaded, keeping the current one")
+				failed = current
+			}
+			continue
+		}
+		loaded = current
+		logger.WithField("cert_file", current.certFile).
+			WithField("not_after", r.NotAfter().Format(time.RFC3339)).
+			Info("TLS certificate reloaded after a change on disk")
+	}
+}
+
+// certFiles identifies the version of the certificate and key files on disk.
+type certFiles struct {
+	certFile, keyFile string
+	certMod, keyMod   time.Time
+	certSize, keySize int64
+}
+
+func (r *CertReloader) files() certFiles {
+	r.mu.RLock()
+	f := certFiles{certFile: r.certFile, keyFile: r.keyFile}
+	r.mu.RUnlock()
+
+	if info, err := os.Stat(f.certFile); err == nil {
+		f.certMod, f.certSize = info.ModTime(), info.Size()
+	}
+	if info, err := os.Stat(f.keyFile); err == nil {
+		f.keyMod, f.keySize = info.ModTime(), info.Size()
+	}
+	return f
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCertReloader(t *testing.T) {
+	certFile, keyFile := createTempCertFiles(t, true)
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	first, _ := reloader.GetCertificate(nil)
+	if first == nil {
+		t.Fatal("GetCertificate() = nil")
+	}
+
+	// Replace the files in place, as a certificate renewal would
+	newCert, newKey := createTempCertFiles(t, true)
+	copyFile(t, newCert, certFile)
+	copyFile(t, newKey, keyFile)
+
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	second, _ := reloader.GetCertificate(nil)
+	if string(second.Certificate[0]) == string(first.Certificate[0]) {
+		t.Error("Reload() should serve the renewed certificate")
+	}
+
+	// An invalid certificate keeps the current one
+	badCert, badKey := createTempCertFiles(t, false)
+	if err := reloader.Load(badCert, badKey); err == nil {
+		t.Error("Load() should fail for invalid certificates")
+	}
+	current, _ := reloader.GetCertificate(nil)
+	if current != second {
+		t.Error("a failed Load() should keep the current certificate")
+	}
+}
+
+func TestCertReloader_Watch(t *testing.T) {
+	certFile, keyFile := createTempCertFiles(t, true)
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	first, _ := reloader.GetCertificate(nil)
+	if reloader.NotAfter().IsZero() {
+		t.Error("NotAfter() should report the certificate expiry")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// Only the certificate is replaced: the pair is invalid and the current one is kept
+	newCert, newKey := createTempCertFiles(t, true)
+	copyFile(t, newCert, certFile)
+	time.Sleep(50 * time.Millisecond)
+	if current, _ := reloader.GetCertificate(nil); current != first {
+		t.Fatal("a mismatched key pair should keep the current certificate")
+	}
+
+	copyFile(t, newKey, keyFile)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		current, _ := reloader.GetCertificate(nil)
+		if current != first {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload the renewed certificate")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	certFile, keyFile := createTempCertFiles(t, true)
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := ServerTLSConfig(reloader)
+	if cfg.GetCertificate == nil || len(cfg.Certificates) != 0 {
+		t.Error("ServerTLSConfig() should serve certificates through GetCertificate")
+	}
+	testCipherSuites(t, cfg)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
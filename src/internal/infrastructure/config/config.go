@@ -2,37 +2,156 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
+)
+
+// DefaultConfigPath is the configuration file written by --setup.
+const DefaultConfigPath = "/etc/cloud-update/config.yaml"
+
+// Webhook authentication methods.
+const (
+	AuthHMAC      = "hmac"       // Shared secrets signing an HMAC-SHA256 of the body
+	AuthPublicKey = "public_key" // Ed25519 or ECDSA P-256 signatures checked against public keys
+	AuthMTLS      = "mtls"       // Verified TLS client certificates, without request signatures
 )
 
 // Config represents the service configuration.
 type Config struct {
-	Port        string
-	Secret      string
-	LogLevel    string
-	LogFilePath string
+	Host   string
+	Port   string
+	Secret string
+	// Auth is the webhook authentication method (AuthHMAC, AuthPublicKey or AuthMTLS)
+	Auth string
+	// WebhookKeys are additional named webhook secrets accepted alongside Secret
+	WebhookKeys []WebhookKey
+	// PublicKeys are the keys trusted to sign webhooks with the public_key method
+	PublicKeys []PublicKeyFile
+	// RequireNonce rejects webhook requests without a nonce
+	RequireNonce bool
+	LogLevel     string
+	LogFilePath  string
+	// TLS is the HTTPS configuration
+	TLS *TLSConfig
+	// RateLimit limits webhook requests per client IP
+	RateLimit RateLimitConfig
+	// Workers sizes the worker pool processing actions
+	Workers WorkerConfig
+	// Executor is the policy system commands are run with
+	Executor system.Policy
+	// JobStorePath is the file job history is persisted to ("memory" disables persistence)
+	JobStorePath string
+	// JobLogDir is the directory the command output of jobs is written to ("memory" keeps it in memory)
+	JobLogDir string
+	// JobQueue is how many jobs may wait for the running job (0 rejects them with 409 Conflict)
+	JobQueue int
+	// JobRetention is how long finished jobs are kept in the job history
+	JobRetention time.Duration
+	// AllowedActions is the allow-list of actions accepted by the webhook
+	AllowedActions entity.ActionSet
+	// AuditLogPath is the hash-chained audit log of requests and commands ("none" disables it)
+	AuditLogPath string
+	// Maintenance restricts actions such as reboots to maintenance windows
+	Maintenance entity.MaintenancePolicy
+	// Metrics is the Prometheus metrics endpoint configuration
+	Metrics MetricsConfig
+	// Telemetry is the OpenTelemetry tracing configuration
+	Telemetry telemetry.Config
+	// Callbacks is the completion callback configuration (no secret: disabled)
+	Callbacks callback.Config
+	// File is the configuration file the settings were read from (empty if none)
+	File string
+}
+
+// WebhookKey is a named webhook secret, valid until NotAfter (zero never expires).
+type WebhookKey struct {
+	ID       string
+	Secret   string
+	NotAfter time.Time
+}
+
+// PublicKeyFile is a named PEM public key file, valid until NotAfter (zero never expires).
+type PublicKeyFile struct {
+	ID       string
+	File     string
+	NotAfter time.Time
+}
+
+// RateLimitConfig holds the webhook rate limiting settings.
+type RateLimitConfig struct {
+	RequestsPerSecond int
+	Burst             int
+	TTL               time.Duration
+}
+
+// MetricsConfig holds the Prometheus metrics endpoint settings.
+type MetricsConfig struct {
+	Enabled bool   // Serve /metrics
+	Listen  string // Separate plain HTTP listen address (empty: served with the webhook)
+}
+
+// WorkerConfig holds the worker pool settings.
+type WorkerConfig struct {
+	Count      int           // Number of concurrent workers
+	QueueSize  int           // Maximum number of queued tasks
+	JobTimeout time.Duration // Maximum run time of a job, all its commands included
 }
 
-// Load loads the configuration from environment variables.
+// Load loads the configuration from the configuration file, when present, and
+// environment variables. The process exits if the configuration is invalid.
 func Load() *Config {
-	config := &Config{
-		Port:        getEnvOrDefault("CLOUD_UPDATE_PORT", "9999"),
-		Secret:      getEnvOrDefault("CLOUD_UPDATE_SECRET", ""),
-		LogLevel:    getEnvOrDefault("CLOUD_UPDATE_LOG_LEVEL", "info"),
-		LogFilePath: getEnvOrDefault("CLOUD_UPDATE_LOG_FILE", "/var/log/cloud-update/cloud-update.log"),
+	config, err := LoadDefault()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
+	return config
+}
 
-	if config.Secret == "" {
-		log.Fatal("CLOUD_UPDATE_SECRET environment variable is required")
+// LoadDefault loads the configuration like Load, returning an error instead of exiting.
+func LoadDefault() (*Config, error) {
+	return LoadFile(defaultFilePath())
+}
+
+// LoadFile loads the configuration from the YAML file at path, then applies
+// environment variable overrides. An empty path loads environment variables only.
+func LoadFile(path string) (*Config, error) {
+	file := newFileConfig()
+	if path != "" {
+		if err := file.read(path); err != nil {
+			return nil, err
+		}
 	}
 
-	return config
+	s := newSettings(file)
+	s.applyEnv()
+
+	config, err := s.build()
+	if err != nil {
+		if path != "" {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return nil, err
+	}
+	config.File = path
+	return config, nil
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// defaultFilePath returns the configuration file Load reads: CLOUD_UPDATE_CONFIG_PATH
+// when set, otherwise DefaultConfigPath if it exists.
+func defaultFilePath() string {
+	if path := os.Getenv("CLOUD_UPDATE_CONFIG_PATH"); path != "" {
+		return path
+	}
+	if _, err := os.Stat(DefaultConfigPath); errors.Is(err, os.ErrNotExist) {
+		return ""
 	}
-	return defaultValue
+	return DefaultConfigPath
 }
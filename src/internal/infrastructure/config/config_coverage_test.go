@@ -36,7 +36,7 @@ func TestLoadWithoutSecret(t *testing.T) {
 	}
 
 	// Check that the error message was logged
-	if !strings.Contains(string(output), "CLOUD_UPDATE_SECRET environment variable is required") {
+	if !strings.Contains(string(output), "security.webhook_secret: is required") {
 		t.Errorf("Expected error message not found in output: %s", output)
 	}
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"testing"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
 )
 
 func TestLoad(t *testing.T) {
@@ -95,49 +97,6 @@ func TestLoad(t *testing.T) {
 	}
 }
 
-func TestGetEnvOrDefault(t *testing.T) {
-	testKey := "TEST_ENV_VAR_FOR_CLOUD_UPDATE"
-
-	// Save original value if it exists
-	origValue := os.Getenv(testKey)
-	defer func() { _ = os.Setenv(testKey, origValue) }()
-
-	tests := []struct {
-		name         string
-		envValue     string
-		defaultValue string
-		want         string
-	}{
-		{
-			name:         "env var set",
-			envValue:     "custom-value",
-			defaultValue: "default-value",
-			want:         "custom-value",
-		},
-		{
-			name:         "env var empty",
-			envValue:     "",
-			defaultValue: "default-value",
-			want:         "default-value",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.envValue == "" {
-				_ = os.Unsetenv(testKey)
-			} else {
-				_ = os.Setenv(testKey, tt.envValue)
-			}
-
-			got := getEnvOrDefault(testKey, tt.defaultValue)
-			if got != tt.want {
-				t.Errorf("getEnvOrDefault() = %s, want %s", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestConfigStructure(t *testing.T) {
 	cfg := &Config{
 		Port:     "9999",
@@ -157,3 +116,19 @@ func TestConfigStructure(t *testing.T) {
 		t.Errorf("Expected LogLevel to be info, got %s", cfg.LogLevel)
 	}
 }
+
+func TestLoad_AllowedActions(t *testing.T) {
+	t.Setenv("CLOUD_UPDATE_SECRET", "test-secret")
+
+	t.Setenv("CLOUD_UPDATE_ALLOWED_ACTIONS", "")
+	cfg := Load()
+	if got := cfg.AllowedActions.List(); len(got) != 1 || got[0] != entity.ActionUpdate {
+		t.Errorf("default AllowedActions = %v, want [update]", got)
+	}
+
+	t.Setenv("CLOUD_UPDATE_ALLOWED_ACTIONS", "update,reboot")
+	cfg = Load()
+	if !cfg.AllowedActions.Allows(entity.ActionReboot) || cfg.AllowedActions.Allows(entity.ActionShutdown) {
+		t.Errorf("AllowedActions = %v, want [reboot update]", cfg.AllowedActions.List())
+	}
+}
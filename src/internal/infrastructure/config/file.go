@@ -0,0 +1,863 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/acme"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
+)
+
+// fileConfig mirrors the YAML configuration file. Values are kept as strings so
+// file values and environment overrides go through the same validation.
+type fileConfig struct {
+	Server struct {
+		Host string `yaml:"host"`
+		Port string `yaml:"port"`
+	} `yaml:"server"`
+	Security struct {
+		Auth          string            `yaml:"auth"`
+		WebhookSecret string            `yaml:"webhook_secret"`
+		Keys          []keyConfig       `yaml:"keys"`
+		PublicKeys    []publicKeyConfig `yaml:"public_keys"`
+		RequireNonce  string            `yaml:"require_nonce"`
+	} `yaml:"security"`
+	Logging struct {
+		Level string `yaml:"level"`
+		File  string `yaml:"file"`
+	} `yaml:"logging"`
+	TLS struct {
+		Enabled  string `yaml:"enabled"`
+		CertFile string `yaml:"cert_file"`
+		KeyFile  string `yaml:"key_file"`
+		Auto     string `yaml:"auto"`
+		Domain   string `yaml:"domain"`
+
+		ClientAuth    string   `yaml:"client_auth"`
+		ClientCA      string   `yaml:"client_ca"`
+		ClientAllowed []string `yaml:"client_allowed"`
+		ExpiryWarning string   `yaml:"expiry_warning"`
+
+		ACME struct {
+			Directory string `yaml:"directory"`
+			Email     string `yaml:"email"`
+			Challenge string `yaml:"challenge"`
+			HTTPAddr  string `yaml:"http_addr"`
+			Cache     string `yaml:"cache"`
+			CAFile    string `yaml:"ca_file"`
+		} `yaml:"acme"`
+	} `yaml:"tls"`
+	RateLimit struct {
+		RequestsPerSecond string `yaml:"requests_per_second"`
+		Burst             string `yaml:"burst"`
+		TTL               string `yaml:"ttl"`
+	} `yaml:"rate_limit"`
+	Workers struct {
+		Count      string `yaml:"count"`
+		QueueSize  string `yaml:"queue_size"`
+		JobTimeout string `yaml:"job_timeout"`
+	} `yaml:"workers"`
+	Executor struct {
+		Privilege      string            `yaml:"privilege"`
+		Timeout        string            `yaml:"timeout"`
+		StepTimeouts   map[string]string `yaml:"step_timeouts"`
+		DistroTimeouts map[string]string `yaml:"distro_timeouts"`
+		Reboot         string            `yaml:"reboot"`
+	} `yaml:"executor"`
+	Actions struct {
+		Allowed []string `yaml:"allowed"`
+	} `yaml:"actions"`
+	Jobs struct {
+		Store     string `yaml:"store"`
+		Logs      string `yaml:"logs"`
+		Queue     string `yaml:"queue"`
+		Retention string `yaml:"retention"`
+	} `yaml:"jobs"`
+	Audit struct {
+		File string `yaml:"file"`
+	} `yaml:"audit"`
+	Maintenance struct {
+		Windows  []string `yaml:"windows"`
+		Timezone string   `yaml:"timezone"`
+		Actions  []string `yaml:"actions"`
+	} `yaml:"maintenance"`
+	Metrics struct {
+		Enabled string `yaml:"enabled"`
+		Listen  string `yaml:"listen"`
+	} `yaml:"metrics"`
+	Telemetry struct {
+		Enabled     string `yaml:"enabled"`
+		Endpoint    string `yaml:"endpoint"`
+		ServiceName string `yaml:"service_name"`
+		Environment string `yaml:"environment"`
+	} `yaml:"telemetry"`
+	Callbacks struct {
+		URL         string `yaml:"url"`
+		Secret      string `yaml:"secret"`
+		MaxAttempts string `yaml:"max_attempts"`
+		Timeout     string `yaml:"timeout"`
+		RetryDelay  string `yaml:"retry_delay"`
+		// AllowedNetworks are the local networks callbacks may be delivered to
+		AllowedNetworks []string `yaml:"allowed_networks"`
+	} `yaml:"callbacks"`
+}
+
+// keyConfig is a named webhook secret of the keyring.
+type keyConfig struct {
+	ID       string `yaml:"id"`
+	Secret   string `yaml:"secret"`
+	NotAfter string `yaml:"not_after"`
+}
+
+// publicKeyConfig is a named public key trusted to sign webhooks.
+type publicKeyConfig struct {
+	ID       string `yaml:"id"`
+	File     string `yaml:"file"`
+	NotAfter string `yaml:"not_after"`
+}
+
+// newFileConfig returns a configuration holding the default values.
+func newFileConfig() *fileConfig {
+	f := &fileConfig{}
+	f.Server.Port = "9999"
+	f.Security.Auth = AuthHMAC
+	f.Security.RequireNonce = "true"
+	f.Logging.Level = "info"
+	f.Logging.File = "/var/log/cloud-update/cloud-update.log"
+	f.TLS.Enabled = "false"
+	f.TLS.Auto = "false"
+	f.TLS.ClientAuth = ClientAuthNone
+	f.TLS.ExpiryWarning = DefaultExpiryWarning.String()
+	f.TLS.ACME.Directory = acme.LetsEncryptURL
+	f.TLS.ACME.Challenge = acme.ChallengeTLSALPN01
+	f.TLS.ACME.HTTPAddr = ":80"
+	f.TLS.ACME.Cache = "/etc/cloud-update/tls/acme"
+	f.RateLimit.RequestsPerSecond = "10"
+	f.RateLimit.Burst = "20"
+	f.RateLimit.TTL = "15m"
+	f.Workers.Count = "10"
+	f.Workers.QueueSize = "100"
+	f.Workers.JobTimeout = "5m"
+	f.Executor.Privilege = string(system.PrivilegeAuto)
+	f.Executor.Timeout = system.DefaultCommandTimeout.String()
+	f.Executor.Reboot = string(system.RebootImmediate)
+	f.Actions.Allowed = []string{string(entity.ActionUpdate)}
+	f.Jobs.Store = "/var/lib/cloud-update/jobs.log"
+	f.Jobs.Logs = "/var/lib/cloud-update/logs"
+	f.Jobs.Queue = "0"
+	f.Jobs.Retention = "168h"
+	f.Audit.File = "/var/log/cloud-update/audit.log"
+	f.Metrics.Enabled = "false"
+	f.Telemetry.Enabled = "false"
+	f.Telemetry.Endpoint = telemetry.DefaultEndpoint
+	f.Telemetry.ServiceName = "cloud-update"
+	f.Telemetry.Environment = "development"
+	f.Callbacks.MaxAttempts = strconv.Itoa(callback.DefaultMaxAttempts)
+	f.Callbacks.Timeout = callback.DefaultTimeout.String()
+	f.Callbacks.RetryDelay = callback.DefaultRetryDelay.String()
+	f.Maintenance.Timezone = "UTC"
+	f.Maintenance.Actions = []string{
+		string(entity.ActionReboot), string(entity.ActionShutdown), string(entity.ActionUpgrade),
+	}
+	return f
+}
+
+// read merges the YAML file at path over the current values. Unknown keys are rejected.
+func (f *fileConfig) read(path string) error {
+	file, err := os.Open(path) //nolint:gosec // path comes from the command line or service environment
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// envOverrides maps each configuration key to the environment variable overriding it.
+var envOverrides = []struct {
+	key string
+	env string
+}{
+	{"server.host", "CLOUD_UPDATE_HOST"},
+	{"server.port", "CLOUD_UPDATE_PORT"},
+	{"security.auth", "CLOUD_UPDATE_AUTH"},
+	{"security.webhook_secret", "CLOUD_UPDATE_SECRET"},
+	{"security.require_nonce", "CLOUD_UPDATE_REQUIRE_NONCE"},
+	{"logging.level", "CLOUD_UPDATE_LOG_LEVEL"},
+	{"logging.file", "CLOUD_UPDATE_LOG_FILE"},
+	{"tls.enabled", "CLOUD_UPDATE_TLS_ENABLED"},
+	{"tls.cert_file", "CLOUD_UPDATE_TLS_CERT"},
+	{"tls.key_file", "CLOUD_UPDATE_TLS_KEY"},
+	{"tls.auto", "CLOUD_UPDATE_TLS_AUTO"},
+	{"tls.domain", "CLOUD_UPDATE_DOMAIN"},
+	{"tls.client_auth", "CLOUD_UPDATE_TLS_CLIENT_AUTH"},
+	{"tls.client_ca", "CLOUD_UPDATE_TLS_CLIENT_CA"},
+	{"tls.client_allowed", "CLOUD_UPDATE_TLS_CLIENT_ALLOWED"},
+	{"tls.expiry_warning", "CLOUD_UPDATE_TLS_EXPIRY_WARNING"},
+	{"tls.acme.directory", "CLOUD_UPDATE_ACME_DIRECTORY"},
+	{"tls.acme.email", "CLOUD_UPDATE_ACME_EMAIL"},
+	{"tls.acme.challenge", "CLOUD_UPDATE_ACME_CHALLENGE"},
+	{"tls.acme.http_addr", "CLOUD_UPDATE_ACME_HTTP_ADDR"},
+	{"tls.acme.cache", "CLOUD_UPDATE_ACME_CACHE"},
+	{"tls.acme.ca_file", "CLOUD_UPDATE_ACME_CA"},
+	{"rate_limit.requests_per_second", "CLOUD_UPDATE_RATE_LIMIT"},
+	{"rate_limit.burst", "CLOUD_UPDATE_RATE_BURST"},
+	{"rate_limit.ttl", "CLOUD_UPDATE_RATE_TTL"},
+	{"workers.count", "CLOUD_UPDATE_WORKERS"},
+	{"workers.queue_size", "CLOUD_UPDATE_QUEUE_SIZE"},
+	{"workers.job_timeout", "CLOUD_UPDATE_JOB_TIMEOUT"},
+	{"executor.privilege", "CLOUD_UPDATE_PRIVILEGE"},
+	{"executor.timeout", "CLOUD_UPDATE_COMMAND_TIMEOUT"},
+	{"executor.reboot", "CLOUD_UPDATE_REBOOT_STRATEGY"},
+	{"actions.allowed", "CLOUD_UPDATE_ALLOWED_ACTIONS"},
+	{"jobs.store", "CLOUD_UPDATE_JOB_STORE"},
+	{"jobs.logs", "CLOUD_UPDATE_JOB_LOGS"},
+	{"jobs.queue", "CLOUD_UPDATE_JOB_QUEUE"},
+	{"jobs.retention", "CLOUD_UPDATE_JOB_RETENTION"},
+	{"audit.file", "CLOUD_UPDATE_AUDIT_LOG"},
+	{"maintenance.windows", "CLOUD_UPDATE_MAINTENANCE_WINDOWS"},
+	{"maintenance.timezone", "CLOUD_UPDATE_MAINTENANCE_TIMEZONE"},
+	{"maintenance.actions", "CLOUD_UPDATE_MAINTENANCE_ACTIONS"},
+	{"metrics.enabled", "CLOUD_UPDATE_METRICS_ENABLED"},
+	{"metrics.listen", "CLOUD_UPDATE_METRICS_LISTEN"},
+	{"telemetry.enabled", "CLOUD_UPDATE_OTEL_ENABLED"},
+	{"telemetry.endpoint", "CLOUD_UPDATE_OTEL_ENDPOINT"},
+	{"telemetry.service_name", "CLOUD_UPDATE_SERVICE_NAME"},
+	{"telemetry.environment", "CLOUD_UPDATE_ENVIRONMENT"},
+	{"callbacks.url", "CLOUD_UPDATE_CALLBACK_URL"},
+	{"callbacks.secret", "CLOUD_UPDATE_CALLBACK_SECRET"},
+	{"callbacks.max_attempts", "CLOUD_UPDATE_CALLBACK_MAX_ATTEMPTS"},
+	{"callbacks.timeout", "CLOUD_UPDATE_CALLBACK_TIMEOUT"},
+	{"callbacks.retry_delay", "CLOUD_UPDATE_CALLBACK_RETRY_DELAY"},
+	{"callbacks.allowed_networks", "CLOUD_UPDATE_CALLBACK_ALLOWED_NETWORKS"},
+}
+
+// ValidationError reports an invalid configuration value.
+type ValidationError struct {
+	Key    string // Configuration file key, e.g. "server.port"
+	EnvVar string // Environment variable the value came from, if any
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.EnvVar != "" {
+		return fmt.Sprintf("%s (set by %s): %s", e.Key, e.EnvVar, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Key, e.Reason)
+}
+
+// settings holds the raw configuration values by key while they are validated.
+type settings struct {
+	values  map[string]string
+	fromEnv map[string]string // key -> environment variable that set it
+	keys    []keyConfig
+	pubKeys []publicKeyConfig
+	// Per step and per distribution command timeouts, only set in the file
+	stepTimeouts   map[string]string
+	distroTimeouts map[string]string
+	errs           []error
+}
+
+func newSettings(f *fileConfig) *settings {
+	return &settings{
+		values: map[string]string{
+			"server.host":                    f.Server.Host,
+			"server.port":                    f.Server.Port,
+			"security.auth":                  f.Security.Auth,
+			"security.webhook_secret":        f.Security.WebhookSecret,
+			"security.require_nonce":         f.Security.RequireNonce,
+			"logging.level":                  f.Logging.Level,
+			"logging.file":                   f.Logging.File,
+			"tls.enabled":                    f.TLS.Enabled,
+			"tls.cert_file":                  f.TLS.CertFile,
+			"tls.key_file":                   f.TLS.KeyFile,
+			"tls.auto":                       f.TLS.Auto,
+			"tls.domain":                     f.TLS.Domain,
+			"tls.client_auth":                f.TLS.ClientAuth,
+			"tls.client_ca":                  f.TLS.ClientCA,
+			"tls.client_allowed":             strings.Join(f.TLS.ClientAllowed, ","),
+			"tls.expiry_warning":             f.TLS.ExpiryWarning,
+			"tls.acme.directory":             f.TLS.ACME.Directory,
+			"tls.acme.email":                 f.TLS.ACME.Email,
+			"tls.acme.challenge":             f.TLS.ACME.Challenge,
+			"tls.acme.http_addr":             f.TLS.ACME.HTTPAddr,
+			"tls.acme.cache":                 f.TLS.ACME.Cache,
+			"tls.acme.ca_file":               f.TLS.ACME.CAFile,
+			"rate_limit.requests_per_second": f.RateLimit.RequestsPerSecond,
+			"rate_limit.burst":               f.RateLimit.Burst,
+			"rate_limit.ttl":                 f.RateLimit.TTL,
+			"workers.count":                  f.Workers.Count,
+			"workers.queue_size":             f.Workers.QueueSize,
+			"workers.job_timeout":            f.Workers.JobTimeout,
+			"executor.privilege":             f.Executor.Privilege,
+			"executor.timeout":               f.Executor.Timeout,
+			"executor.reboot":                f.Executor.Reboot,
+			"actions.allowed":                strings.Join(f.Actions.Allowed, ","),
+			"jobs.store":                     f.Jobs.Store,
+			"jobs.logs":                      f.Jobs.Logs,
+			"jobs.queue":                     f.Jobs.Queue,
+			"jobs.retention":                 f.Jobs.Retention,
+			"audit.file":                     f.Audit.File,
+			"maintenance.windows":            strings.Join(f.Maintenance.Windows, ";"),
+			"maintenance.timezone":           f.Maintenance.Timezone,
+			"maintenance.actions":            strings.Join(f.Maintenance.Actions, ","),
+			"metrics.enabled":                f.Metrics.Enabled,
+			"metrics.listen":                 f.Metrics.Listen,
+			"telemetry.enabled":              f.Telemetry.Enabled,
+			"telemetry.endpoint":             f.Telemetry.Endpoint,
+			"telemetry.service_name":         f.Telemetry.ServiceName,
+			"telemetry.environment":          f.Telemetry.Environment,
+			"callbacks.url":                  f.Callbacks.URL,
+			"callbacks.secret":               f.Callbacks.Secret,
+			"callbacks.max_attempts":         f.Callbacks.MaxAttempts,
+			"callbacks.timeout":              f.Callbacks.Timeout,
+			"callbacks.retry_delay":          f.Callbacks.RetryDelay,
+			"callbacks.allowed_networks":     strings.Join(f.Callbacks.AllowedNetworks, ","),
+		},
+		fromEnv:        make(map[string]string),
+		keys:           f.Security.Keys,
+		pubKeys:        f.Security.PublicKeys,
+		stepTimeouts:   f.Executor.StepTimeouts,
+		distroTimeouts: f.Executor.DistroTimeouts,
+	}
+}
+
+// applyEnv overrides values with the non-empty environment variables.
+func (s *settings) applyEnv() {
+	for _, o := range envOverrides {
+		if value := os.Getenv(o.env); value != "" {
+			s.values[o.key] = value
+			s.fromEnv[o.key] = o.env
+		}
+	}
+}
+
+// build validates the values and returns the resulting configuration.
+func (s *settings) build() (*Config, error) {
+	auth := s.auth("security.auth")
+	config := &Config{
+		Host:         s.values["server.host"],
+		Port:         s.port("server.port"),
+		Auth:         auth,
+		Secret:       s.secret(auth),
+		WebhookKeys:  s.webhookKeys(),
+		PublicKeys:   s.publicKeys(auth),
+		RequireNonce: s.bool("security.require_nonce"),
+		LogLevel:     s.logLevel("logging.level"),
+		LogFilePath:  s.values["logging.file"],
+		TLS:          s.tls(auth),
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: s.positiveInt("rate_limit.requests_per_second"),
+			Burst:             s.positiveInt("rate_limit.burst"),
+			TTL:               s.duration("rate_limit.ttl"),
+		},
+		Workers: WorkerConfig{
+			Count:      s.positiveInt("workers.count"),
+			QueueSize:  s.positiveInt("workers.queue_size"),
+			JobTimeout: s.duration("workers.job_timeout"),
+		},
+		Executor:       s.executor(),
+		JobStorePath:   s.required("jobs.store"),
+		JobLogDir:      s.required("jobs.logs"),
+		JobQueue:       s.count("jobs.queue"),
+		JobRetention:   s.duration("jobs.retention"),
+		AllowedActions: s.actions("actions.allowed"),
+		AuditLogPath:   s.required("audit.file"),
+		Maintenance:    s.maintenance(),
+		Metrics:        s.metrics(),
+		Telemetry:      s.telemetry(),
+		Callbacks:      s.callbacks(),
+	}
+
+	if len(s.errs) > 0 {
+		return nil, errors.Join(s.errs...)
+	}
+	return config, nil
+}
+
+func (s *settings) invalid(key, format string, args ...any) {
+	s.errs = append(s.errs, &ValidationError{
+		Key:    key,
+		EnvVar: s.fromEnv[key],
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (s *settings) required(key string) string {
+	value := s.values[key]
+	if value == "" {
+		s.invalid(key, "is required (set it in the config file or with %s)", envVarFor(key))
+	}
+	return value
+}
+
+func (s *settings) auth(key string) string {
+	value := strings.ToLower(s.values[key])
+	if value != AuthHMAC && value != AuthPublicKey && value != AuthMTLS {
+		s.invalid(key, "must be %s, %s or %s, got %q", AuthHMAC, AuthPublicKey, AuthMTLS, s.values[key])
+	}
+	return value
+}
+
+// secret returns the webhook secret, which is only optional when a keyring is
+// configured or webhooks are not authenticated with HMAC.
+func (s *settings) secret(auth string) string {
+	if len(s.keys) > 0 || auth != AuthHMAC {
+		return s.values["security.webhook_secret"]
+	}
+	return s.required("security.webhook_secret")
+}
+
+// webhookKeys validates the keyring. Key IDs must be unique, and the ID "default"
+// is taken by webhook_secret when it is set.
+func (s *settings) webhookKeys() []WebhookKey {
+	keys := make([]WebhookKey, 0, len(s.keys))
+	seen := make(map[string]bool, len(s.keys))
+	if s.values["security.webhook_secret"] != "" {
+		seen["default"] = true
+	}
+
+	for i, k := range s.keys {
+		prefix := fmt.Sprintf("security.keys[%d]", i)
+		switch {
+		case k.ID == "":
+			s.invalid(prefix+".id", "is required")
+		case seen[k.ID]:
+			s.invalid(prefix+".id", "duplicate key ID %q", k.ID)
+		}
+		seen[k.ID] = true
+
+		if k.Secret == "" {
+			s.invalid(prefix+".secret", "is required")
+		}
+
+		key := WebhookKey{ID: k.ID, Secret: k.Secret}
+		if k.NotAfter != "" {
+			notAfter, err := parseTime(k.NotAfter)
+			if err != nil {
+				s.invalid(prefix+".not_after", "must be a date (2025-12-31) or an RFC 3339 time, got %q", k.NotAfter)
+			}
+			key.NotAfter = notAfter
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// publicKeys validates the public key files, at least one being required by the
+// public_key method. The files are read when the authenticator is created.
+func (s *settings) publicKeys(auth string) []PublicKeyFile {
+	if auth == AuthPublicKey && len(s.pubKeys) == 0 {
+		s.invalid("security.public_keys", "at least one key is required when security.auth is %s", AuthPublicKey)
+	}
+
+	keys := make([]PublicKeyFile, 0, len(s.pubKeys))
+	seen := make(map[string]bool, len(s.pubKeys))
+	for i, k := range s.pubKeys {
+		prefix := fmt.Sprintf("security.public_keys[%d]", i)
+		switch {
+		case k.ID == "":
+			s.invalid(prefix+".id", "is required")
+		case seen[k.ID]:
+			s.invalid(prefix+".id", "duplicate key ID %q", k.ID)
+		}
+		seen[k.ID] = true
+
+		if k.File == "" {
+			s.invalid(prefix+".file", "is required")
+		}
+
+		key := PublicKeyFile{ID: k.ID, File: k.File}
+		if k.NotAfter != "" {
+			notAfter, err := parseTime(k.NotAfter)
+			if err != nil {
+				s.invalid(prefix+".not_after", "must be a date (2025-12-31) or an RFC 3339 time, got %q", k.NotAfter)
+			}
+			key.NotAfter = notAfter
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *settings) port(key string) string {
+	value := s.values[key]
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		s.invalid(key, "must be a port number between 1 and 65535, got %q", value)
+	}
+	return value
+}
+
+func (s *settings) positiveInt(key string) int {
+	value := s.values[key]
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		s.invalid(key, "must be a positive integer, got %q", value)
+		return 0
+	}
+	return n
+}
+
+func (s *settings) count(key string) int {
+	value := s.values[key]
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		s.invalid(key, "must be zero or a positive integer, got %q", value)
+		return 0
+	}
+	return n
+}
+
+func (s *settings) duration(key string) time.Duration {
+	value := s.values[key]
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		s.invalid(key, "must be a positive duration such as 30s, 15m or 168h, got %q", value)
+		return 0
+	}
+	return d
+}
+
+func (s *settings) bool(key string) bool {
+	value := s.values[key]
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		s.invalid(key, "must be true or false, got %q", value)
+		return false
+	}
+	return b
+}
+
+func (s *settings) logLevel(key string) string {
+	value := strings.ToLower(s.values[key])
+	switch value {
+	case "trace", "debug", "info", "warn", "warning", "error":
+		return value
+	default:
+		s.invalid(key, "must be one of debug, info, warn, error, got %q", s.values[key])
+		return value
+	}
+}
+
+func (s *settings) actions(key string) entity.ActionSet {
+	set, err := entity.ParseActionSet(s.values[key])
+	if err != nil {
+		s.invalid(key, "%v", err)
+	}
+	return set
+}
+
+// metrics validates the metrics endpoint settings.
+func (s *settings) metrics() MetricsConfig {
+	cfg := MetricsConfig{
+		Enabled: s.bool("metrics.enabled"),
+		Listen:  s.values["metrics.listen"],
+	}
+	if cfg.Listen != "" {
+		if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+			s.invalid("metrics.listen", "must be a host:port listen address: %v", err)
+		}
+	}
+	return cfg
+}
+
+// telemetry validates the OpenTelemetry tracing settings.
+func (s *settings) telemetry() telemetry.Config {
+	cfg := telemetry.Config{
+		Enabled:      s.bool("telemetry.enabled"),
+		OTLPEndpoint: s.required("telemetry.endpoint"),
+		ServiceName:  s.required("telemetry.service_name"),
+		Environment:  s.values["telemetry.environment"],
+	}
+	if cfg.OTLPEndpoint != "" {
+		if _, err := cfg.TracesURL(); err != nil {
+			s.invalid("telemetry.endpoint", "must be a host:port or http(s) URL of an OTLP/HTTP collector")
+		}
+	}
+	return cfg
+}
+
+// callbacks validates the completion callback settings. Callbacks are signed with
+// callbacks.secret, or webhook_secret, and disabled when neither is set.
+func (s *settings) callbacks() callback.Config {
+	cfg := callback.Config{
+		URL:         s.values["callbacks.url"],
+		KeyID:       "callback",
+		Secret:      s.values["callbacks.secret"],
+		MaxAttempts: s.positiveInt("callbacks.max_attempts"),
+		Timeout:     s.duration("callbacks.timeout"),
+		RetryDelay:  s.duration("callbacks.retry_delay"),
+		// Loopback, private and link-local addresses are refused unless listed
+		AllowedNetworks: s.networks("callbacks.allowed_networks"),
+	}
+	if cfg.Secret == "" {
+		cfg.KeyID, cfg.Secret = "default", s.values["security.webhook_secret"]
+	}
+	if cfg.URL != "" {
+		if err := callback.ValidateURL(cfg.URL); err != nil {
+			s.invalid("callbacks.url", "%v", err)
+		}
+		if cfg.Secret == "" {
+			s.invalid("callbacks.secret", "is required to sign callbacks when security.webhook_secret is not set")
+		}
+	}
+	return cfg
+}
+
+// networks validates comma-separated networks in CIDR notation, a single address
+// standing for itself.
+func (s *settings) networks(key string) []netip.Prefix {
+	var networks []netip.Prefix
+	for _, value := range strings.Split(s.values[key], ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(value); err == nil {
+			networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		network, err := netip.ParsePrefix(value)
+		if err != nil {
+			s.invalid(key, "must list networks such as 10.0.0.0/8 or addresses, got %q", value)
+			continue
+		}
+		networks = append(networks, network.Masked())
+	}
+	return networks
+}
+
+// maintenance validates the maintenance windows, separated by semicolons.
+func (s *settings) maintenance() entity.MaintenancePolicy {
+	policy := entity.MaintenancePolicy{Actions: s.actions("maintenance.actions")}
+
+	timezone := s.values["maintenance.timezone"]
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		s.invalid("maintenance.timezone", "unknown time zone %q", timezone)
+		loc = time.UTC
+	}
+	policy.Location = loc
+
+	for _, spec := range strings.Split(s.values["maintenance.windows"], ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		window, err := entity.ParseMaintenanceWindow(spec)
+		if err != nil {
+			s.invalid("maintenance.windows", "%v", err)
+			continue
+		}
+		policy.Windows = append(policy.Windows, window)
+	}
+	return policy
+}
+
+// executor validates the policy system commands are run with.
+func (s *settings) executor() system.Policy {
+	policy := system.Policy{
+		Privilege:      system.PrivilegeMethod(strings.ToLower(s.values["executor.privilege"])),
+		Timeout:        s.duration("executor.timeout"),
+		StepTimeouts:   make(map[system.Step]time.Duration, len(s.stepTimeouts)),
+		DistroTimeouts: make(map[system.Distribution]time.Duration, len(s.distroTimeouts)),
+		Reboot:         system.RebootStrategy(strings.ToLower(s.values["executor.reboot"])),
+	}
+
+	switch policy.Privilege {
+	case system.PrivilegeAuto, system.PrivilegeNone, system.PrivilegeDoas, system.PrivilegeSudo:
+	default:
+		s.invalid("executor.privilege", "must be one of auto, none, doas, sudo, got %q", s.values["executor.privilege"])
+	}
+	switch policy.Reboot {
+	case system.RebootImmediate, system.RebootScheduled:
+	default:
+		s.invalid("executor.reboot", "must be %s or %s, got %q",
+			system.RebootImmediate, system.RebootScheduled, s.values["executor.reboot"])
+	}
+
+	for _, name := range sortedKeys(s.stepTimeouts) {
+		key := "executor.step_timeouts." + name
+		if !slices.Contains(system.Steps, system.Step(name)) {
+			s.invalid(key, "unknown step, expected one of %s", joinNames(system.Steps))
+			continue
+		}
+		policy.StepTimeouts[system.Step(name)] = s.timeout(key, s.stepTimeouts[name])
+	}
+	for _, name := range sortedKeys(s.distroTimeouts) {
+		key := "executor.distro_timeouts." + name
+		if !slices.Contains(system.Distributions, system.Distribution(name)) {
+			s.invalid(key, "unknown distribution, expected one of %s", joinNames(system.Distributions))
+			continue
+		}
+		policy.DistroTimeouts[system.Distribution(name)] = s.timeout(key, s.distroTimeouts[name])
+	}
+	return policy
+}
+
+// timeout validates a duration that is not a key of values.
+func (s *settings) timeout(key, value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		s.invalid(key, "must be a positive duration such as 30s, 15m or 168h, got %q", value)
+		return 0
+	}
+	return d
+}
+
+// tls builds the TLS configuration. Manual certificates default to the files in
+// /etc/cloud-update/tls, and are ignored with automatic certificates.
+func (s *settings) tls(auth string) *TLSConfig {
+	cfg := &TLSConfig{
+		Enabled:  s.bool("tls.enabled"),
+		CertFile: s.values["tls.cert_file"],
+		KeyFile:  s.values["tls.key_file"],
+		Auto:     s.bool("tls.auto"),
+		Domain:   s.values["tls.domain"],
+
+		ClientAuth:    s.clientAuth(auth),
+		ClientCAFile:  s.values["tls.client_ca"],
+		ClientAllowed: s.patterns("tls.client_allowed"),
+		ExpiryWarning: s.duration("tls.expiry_warning"),
+	}
+	if cfg.ClientAuth != ClientAuthNone {
+		if !cfg.Enabled {
+			s.invalid("tls.client_auth", "requires tls.enabled")
+		}
+		if cfg.ClientCAFile == "" {
+			s.invalid("tls.client_ca", "is required when tls.client_auth is %s", cfg.ClientAuth)
+		}
+	}
+	if !cfg.Enabled {
+		return cfg
+	}
+
+	if cfg.Auto {
+		if cfg.Domain == "" {
+			s.invalid("tls.domain", "is required when tls.auto is enabled")
+		}
+		cfg.ACME = s.acme()
+		cfg.CertFile = ""
+		cfg.KeyFile = ""
+		return cfg
+	}
+
+	if cfg.CertFile == "" {
+		cfg.CertFile = "/etc/cloud-update/tls/cert.pem"
+	}
+	if cfg.KeyFile == "" {
+		cfg.KeyFile = "/etc/cloud-update/tls/key.pem"
+	}
+	return cfg
+}
+
+// acme validates the automatic certificate settings.
+func (s *settings) acme() ACMEConfig {
+	cfg := ACMEConfig{
+		DirectoryURL: s.values["tls.acme.directory"],
+		Email:        s.values["tls.acme.email"],
+		Challenge:    strings.ToLower(s.values["tls.acme.challenge"]),
+		HTTPAddr:     s.values["tls.acme.http_addr"],
+		CacheDir:     s.required("tls.acme.cache"),
+		CAFile:       s.values["tls.acme.ca_file"],
+	}
+	if u, err := url.Parse(cfg.DirectoryURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		s.invalid("tls.acme.directory", "must be an https URL, got %q", cfg.DirectoryURL)
+	}
+	switch cfg.Challenge {
+	case acme.ChallengeTLSALPN01:
+	case acme.ChallengeHTTP01:
+		if _, _, err := net.SplitHostPort(cfg.HTTPAddr); err != nil {
+			s.invalid("tls.acme.http_addr", "must be a host:port listen address: %v", err)
+		}
+	default:
+		s.invalid("tls.acme.challenge", "must be %s or %s, got %q",
+			acme.ChallengeTLSALPN01, acme.ChallengeHTTP01, s.values["tls.acme.challenge"])
+	}
+	return cfg
+}
+
+// clientAuth validates the client certificate mode, which must be required when
+// client certificates are the webhook authentication method.
+func (s *settings) clientAuth(auth string) string {
+	key := "tls.client_auth"
+	value := strings.ToLower(s.values[key])
+	switch value {
+	case ClientAuthNone, ClientAuthOptional, ClientAuthRequired:
+	default:
+		s.invalid(key, "must be one of none, optional, required, got %q", s.values[key])
+	}
+	if auth == AuthMTLS && value != ClientAuthRequired {
+		s.invalid(key, "must be %s when security.auth is %s", ClientAuthRequired, AuthMTLS)
+	}
+	return value
+}
+
+// patterns validates a comma-separated list of path.Match patterns.
+func (s *settings) patterns(key string) []string {
+	patterns := splitList(s.values[key])
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			s.invalid(key, "invalid pattern %q", pattern)
+		}
+	}
+	return patterns
+}
+
+// parseTime parses an RFC 3339 time or a date, which is valid until the end of that day (UTC).
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func joinNames[T ~string](names []T) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = string(name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func envVarFor(key string) string {
+	for _, o := range envOverrides {
+		if o.key == key {
+			return o.env
+		}
+	}
+	return ""
+}
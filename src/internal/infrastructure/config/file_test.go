@@ -0,0 +1,752 @@
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/callback"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
+)
+
+// clearEnv unsets every variable that overrides the configuration file.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, o := range envOverrides {
+		t.Setenv(o.env, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+server:
+  host: "127.0.0.1"
+  port: 8443
+security:
+  webhook_secret: "file-secret"
+  require_nonce: true
+logging:
+  level: debug
+  file: /tmp/cloud-update.log
+tls:
+  enabled: true
+  cert_file: /tmp/cert.pem
+  key_file: /tmp/key.pem
+rate_limit:
+  requests_per_second: 5
+  burst: 8
+  ttl: 1m
+workers:
+  count: 2
+  queue_size: 16
+  job_timeout: 1h
+executor:
+  privilege: sudo
+  timeout: 10m
+  step_timeouts:
+    upgrade_system: 45m
+  distro_timeouts:
+    alpine: 3m
+  reboot: scheduled
+actions:
+  allowed: [update, reboot]
+jobs:
+  store: memory
+  logs: /tmp/cloud-update-logs
+  queue: 5
+  retention: 24h
+metrics:
+  enabled: true
+  listen: 127.0.0.1:9100
+telemetry:
+  enabled: true
+  endpoint: http://otel-collector:4318
+  service_name: edge-node
+  environment: staging
+audit:
+  file: /tmp/cloud-update-audit.log
+callbacks:
+  url: https://ci.example.com/hooks/cloud-update
+  secret: callback-secret
+  max_attempts: 3
+  timeout: 5s
+  retry_delay: 30s
+  allowed_networks: [10.0.0.0/8, 192.168.1.10]
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if cfg.Host != "127.0.0.1" || cfg.Port != "8443" {
+		t.Errorf("address = %s:%s, want 127.0.0.1:8443", cfg.Host, cfg.Port)
+	}
+	if cfg.Secret != "file-secret" || !cfg.RequireNonce {
+		t.Errorf("Secret = %q, RequireNonce = %v", cfg.Secret, cfg.RequireNonce)
+	}
+	if cfg.LogLevel != "debug" || cfg.LogFilePath != "/tmp/cloud-update.log" {
+		t.Errorf("logging = %s %s", cfg.LogLevel, cfg.LogFilePath)
+	}
+	if !cfg.TLS.Enabled || cfg.TLS.CertFile != "/tmp/cert.pem" || cfg.TLS.KeyFile != "/tmp/key.pem" {
+		t.Errorf("TLS = %+v", cfg.TLS)
+	}
+	if cfg.RateLimit != (RateLimitConfig{RequestsPerSecond: 5, Burst: 8, TTL: time.Minute}) {
+		t.Errorf("RateLimit = %+v", cfg.RateLimit)
+	}
+	if cfg.Workers != (WorkerConfig{Count: 2, QueueSize: 16, JobTimeout: time.Hour}) {
+		t.Errorf("Workers = %+v", cfg.Workers)
+	}
+	if cfg.Executor.Privilege != system.PrivilegeSudo || cfg.Executor.Timeout != 10*time.Minute ||
+		cfg.Executor.Reboot != system.RebootScheduled {
+		t.Errorf("Executor = %+v", cfg.Executor)
+	}
+	if cfg.Executor.TimeoutFor(system.StepUpgradeSystem, system.DistroDebian) != 45*time.Minute ||
+		cfg.Executor.TimeoutFor(system.StepUpgradeSystem, system.DistroAlpine) != 3*time.Minute {
+		t.Errorf("Executor timeouts = %v %v", cfg.Executor.StepTimeouts, cfg.Executor.DistroTimeouts)
+	}
+	if !cfg.AllowedActions.Allows(entity.ActionReboot) || cfg.AllowedActions.Allows(entity.ActionShutdown) {
+		t.Errorf("AllowedActions = %v, want [reboot update]", cfg.AllowedActions.List())
+	}
+	if cfg.JobStorePath != "memory" || cfg.JobLogDir != "/tmp/cloud-update-logs" || cfg.JobQueue != 5 ||
+		cfg.JobRetention != 24*time.Hour {
+		t.Errorf("jobs = %s %s %d %s", cfg.JobStorePath, cfg.JobLogDir, cfg.JobQueue, cfg.JobRetention)
+	}
+	if cfg.Metrics != (MetricsConfig{Enabled: true, Listen: "127.0.0.1:9100"}) {
+		t.Errorf("Metrics = %+v", cfg.Metrics)
+	}
+	if cfg.Telemetry != (telemetry.Config{
+		Enabled: true, OTLPEndpoint: "http://otel-collector:4318", ServiceName: "edge-node", Environment: "staging",
+	}) {
+		t.Errorf("Telemetry = %+v", cfg.Telemetry)
+	}
+	if cfg.AuditLogPath != "/tmp/cloud-update-audit.log" {
+		t.Errorf("AuditLogPath = %q", cfg.AuditLogPath)
+	}
+	if !reflect.DeepEqual(cfg.Callbacks, callback.Config{
+		URL: "https://ci.example.com/hooks/cloud-update", KeyID: "callback", Secret: "callback-secret",
+		MaxAttempts: 3, Timeout: 5 * time.Second, RetryDelay: 30 * time.Second,
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.10/32")},
+	}) {
+		t.Errorf("Callbacks = %+v", cfg.Callbacks)
+	}
+	if cfg.File != path {
+		t.Errorf("File = %q, want %q", cfg.File, path)
+	}
+}
+
+func TestLoadFile_Defaults(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "security:\n  webhook_secret: secret\n")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if cfg.Port != "9999" || cfg.LogLevel != "info" {
+		t.Errorf("Port = %s, LogLevel = %s, want defaults", cfg.Port, cfg.LogLevel)
+	}
+	if cfg.TLS.Enabled {
+		t.Error("TLS should be disabled by default")
+	}
+	if cfg.TLS.ExpiryWarning != DefaultExpiryWarning {
+		t.Errorf("TLS.ExpiryWarning = %v, want %v", cfg.TLS.ExpiryWarning, DefaultExpiryWarning)
+	}
+	if cfg.RateLimit.RequestsPerSecond != 10 || cfg.RateLimit.Burst != 20 {
+		t.Errorf("RateLimit = %+v, want 10 req/s burst 20", cfg.RateLimit)
+	}
+	if cfg.Workers.Count != 10 || cfg.Workers.QueueSize != 100 || cfg.Workers.JobTimeout != 5*time.Minute {
+		t.Errorf("Workers = %+v, want 10/100/5m", cfg.Workers)
+	}
+	if cfg.JobQueue != 0 {
+		t.Errorf("JobQueue = %d, want 0 (queueing disabled)", cfg.JobQueue)
+	}
+	if len(cfg.Maintenance.Windows) != 0 || cfg.Maintenance.Restricts(entity.ActionReboot) {
+		t.Errorf("Maintenance = %+v, want no maintenance window", cfg.Maintenance)
+	}
+	if cfg.Metrics != (MetricsConfig{}) {
+		t.Errorf("Metrics = %+v, want disabled", cfg.Metrics)
+	}
+	if cfg.Telemetry.Enabled || cfg.Telemetry.OTLPEndpoint != telemetry.DefaultEndpoint ||
+		cfg.Telemetry.ServiceName != "cloud-update" || cfg.Telemetry.Environment != "development" {
+		t.Errorf("Telemetry = %+v, want disabled with the default collector", cfg.Telemetry)
+	}
+	if cfg.AuditLogPath != "/var/log/cloud-update/audit.log" {
+		t.Errorf("AuditLogPath = %q, want the default audit log", cfg.AuditLogPath)
+	}
+	if !reflect.DeepEqual(cfg.Callbacks, callback.Config{
+		KeyID: "default", Secret: "secret", MaxAttempts: callback.DefaultMaxAttempts,
+		Timeout: callback.DefaultTimeout, RetryDelay: callback.DefaultRetryDelay,
+	}) {
+		t.Errorf("Callbacks = %+v, want signed with the webhook secret, without default URL", cfg.Callbacks)
+	}
+	if cfg.Executor.Privilege != system.PrivilegeAuto || cfg.Executor.Timeout != system.DefaultCommandTimeout ||
+		cfg.Executor.Reboot != system.RebootImmediate {
+		t.Errorf("Executor = %+v, want the default policy", cfg.Executor)
+	}
+	if got := cfg.AllowedActions.List(); len(got) != 1 || got[0] != entity.ActionUpdate {
+		t.Errorf("AllowedActions = %v, want [update]", got)
+	}
+	if !cfg.RequireNonce {
+		t.Error("RequireNonce should be enabled by default")
+	}
+}
+
+func TestLoadFile_EnvOverrides(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+server:
+  port: 8000
+security:
+  webhook_secret: file-secret
+workers:
+  count: 2
+`)
+	t.Setenv("CLOUD_UPDATE_PORT", "9000")
+	t.Setenv("CLOUD_UPDATE_SECRET", "env-secret")
+	t.Setenv("CLOUD_UPDATE_ALLOWED_ACTIONS", "all")
+	t.Setenv("CLOUD_UPDATE_AUDIT_LOG", "none")
+	t.Setenv("CLOUD_UPDATE_CALLBACK_URL", "http://ci:8080/done")
+	t.Setenv("CLOUD_UPDATE_SERVICE_NAME", "edge-node")
+	t.Setenv("CLOUD_UPDATE_ENVIRONMENT", "staging")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if cfg.Port != "9000" || cfg.Secret != "env-secret" {
+		t.Errorf("Port = %s, Secret = %s, want environment values", cfg.Port, cfg.Secret)
+	}
+	if cfg.Workers.Count != 2 {
+		t.Errorf("Workers.Count = %d, want 2 from the file", cfg.Workers.Count)
+	}
+	if len(cfg.AllowedActions.List()) != len(entity.AllActions()) {
+		t.Errorf("AllowedActions = %v, want all", cfg.AllowedActions.List())
+	}
+	if cfg.AuditLogPath != "none" {
+		t.Errorf("AuditLogPath = %q, want none from the environment", cfg.AuditLogPath)
+	}
+	if cfg.Callbacks.URL != "http://ci:8080/done" || cfg.Callbacks.Secret != "env-secret" {
+		t.Errorf("Callbacks = %+v, want the URL and webhook secret from the environment", cfg.Callbacks)
+	}
+	if cfg.Telemetry.ServiceName != "edge-node" || cfg.Telemetry.Environment != "staging" {
+		t.Errorf("Telemetry = %+v, want the service name and environment from the environment", cfg.Telemetry)
+	}
+}
+
+func TestLoadFile_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantKey string
+		wantEnv string
+	}{
+		{
+			name:    "missing secret",
+			content: "server:\n  port: 9999\n",
+			wantKey: "security.webhook_secret",
+		},
+		{
+			name:    "invalid port",
+			content: "server:\n  port: 70000\nsecurity:\n  webhook_secret: s\n",
+			wantKey: "server.port",
+		},
+		{
+			name:    "invalid port from environment",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_PORT": "http"},
+			wantKey: "server.port",
+			wantEnv: "CLOUD_UPDATE_PORT",
+		},
+		{
+			name:    "invalid log level",
+			content: "security:\n  webhook_secret: s\nlogging:\n  level: loud\n",
+			wantKey: "logging.level",
+		},
+		{
+			name:    "invalid metrics address",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_METRICS_LISTEN": "9100"},
+			wantKey: "metrics.listen",
+			wantEnv: "CLOUD_UPDATE_METRICS_LISTEN",
+		},
+		{
+			name:    "invalid telemetry endpoint",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_OTEL_ENDPOINT": "ftp://collector"},
+			wantKey: "telemetry.endpoint",
+			wantEnv: "CLOUD_UPDATE_OTEL_ENDPOINT",
+		},
+		{
+			name:    "invalid callback URL",
+			content: "security:\n  webhook_secret: s\ncallbacks:\n  url: ci.example.com/done\n",
+			wantKey: "callbacks.url",
+		},
+		{
+			name:    "invalid callback network",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_CALLBACK_ALLOWED_NETWORKS": "10.0.0.0/8,intranet"},
+			wantKey: "callbacks.allowed_networks",
+			wantEnv: "CLOUD_UPDATE_CALLBACK_ALLOWED_NETWORKS",
+		},
+		{
+			name:    "callback URL without secret",
+			content: "security:\n  keys:\n    - id: ci\n      secret: s\ncallbacks:\n  url: https://ci.example.com\n",
+			wantKey: "callbacks.secret",
+		},
+		{
+			name:    "unknown action",
+			content: "security:\n  webhook_secret: s\nactions:\n  allowed: [update, format]\n",
+			wantKey: "actions.allowed",
+		},
+		{
+			name:    "negative job queue",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_JOB_QUEUE": "-1"},
+			wantKey: "jobs.queue",
+			wantEnv: "CLOUD_UPDATE_JOB_QUEUE",
+		},
+		{
+			name:    "zero workers",
+			content: "security:\n  webhook_secret: s\nworkers:\n  count: 0\n",
+			wantKey: "workers.count",
+		},
+		{
+			name:    "unknown privilege method",
+			content: "security:\n  webhook_secret: s\nexecutor:\n  privilege: pkexec\n",
+			wantKey: "executor.privilege",
+		},
+		{
+			name:    "invalid reboot strategy from environment",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_REBOOT_STRATEGY": "kexec"},
+			wantKey: "executor.reboot",
+			wantEnv: "CLOUD_UPDATE_REBOOT_STRATEGY",
+		},
+		{
+			name:    "unknown step timeout",
+			content: "security:\n  webhook_secret: s\nexecutor:\n  step_timeouts:\n    reinstall: 1m\n",
+			wantKey: "executor.step_timeouts.reinstall",
+		},
+		{
+			name:    "invalid distribution timeout",
+			content: "security:\n  webhook_secret: s\nexecutor:\n  distro_timeouts:\n    debian: soon\n",
+			wantKey: "executor.distro_timeouts.debian",
+		},
+		{
+			name:    "invalid rate limit ttl",
+			content: "security:\n  webhook_secret: s\nrate_limit:\n  ttl: forever\n",
+			wantKey: "rate_limit.ttl",
+		},
+		{
+			name:    "auto TLS without domain",
+			content: "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  auto: true\n",
+			wantKey: "tls.domain",
+		},
+		{
+			name:    "invalid TLS flag",
+			content: "security:\n  webhook_secret: s\ntls:\n  enabled: maybe\n",
+			wantKey: "tls.enabled",
+		},
+		{
+			name:    "invalid retention from environment",
+			content: "security:\n  webhook_secret: s\n",
+			env:     map[string]string{"CLOUD_UPDATE_JOB_RETENTION": "-1h"},
+			wantKey: "jobs.retention",
+			wantEnv: "CLOUD_UPDATE_JOB_RETENTION",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+			if err == nil {
+				t.Fatal("LoadFile() should fail")
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("error %v is not a ValidationError", err)
+			}
+			if verr.Key != tt.wantKey || verr.EnvVar != tt.wantEnv {
+				t.Errorf("error key = %s (%s), want %s (%s)", verr.Key, verr.EnvVar, tt.wantKey, tt.wantEnv)
+			}
+			if !strings.Contains(err.Error(), tt.wantKey) {
+				t.Errorf("error %q should name %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadFile_UnknownKey(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "security:\n  webhook_secret: s\n  webhook_secrte: typo\n")
+
+	_, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "webhook_secrte") {
+		t.Errorf("LoadFile() error = %v, want unknown key webhook_secrte", err)
+	}
+}
+
+func TestLoadFile_MissingFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CLOUD_UPDATE_SECRET", "secret")
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadFile() should fail when the file does not exist")
+	}
+}
+
+func TestLoadFile_EnvOnly(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CLOUD_UPDATE_SECRET", "secret")
+
+	cfg, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Secret != "secret" || cfg.File != "" {
+		t.Errorf("Secret = %q, File = %q", cfg.Secret, cfg.File)
+	}
+}
+
+func TestLoad_ConfigPathEnv(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "server:\n  port: 7000\nsecurity:\n  webhook_secret: s\n")
+	t.Setenv("CLOUD_UPDATE_CONFIG_PATH", path)
+
+	cfg := Load()
+	if cfg.Port != "7000" || cfg.File != path {
+		t.Errorf("Port = %s, File = %s, want values from %s", cfg.Port, cfg.File, path)
+	}
+}
+
+func TestLoadFile_WebhookKeys(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+security:
+  keys:
+    - id: "2024"
+      secret: old-secret
+      not_after: 2025-01-31
+    - id: "2025"
+      secret: new-secret
+      not_after: 2026-01-31T12:00:00Z
+    - id: ci
+      secret: ci-secret
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Secret != "" {
+		t.Errorf("Secret = %q, want empty when only keys are configured", cfg.Secret)
+	}
+	if len(cfg.WebhookKeys) != 3 {
+		t.Fatalf("WebhookKeys = %+v, want 3 keys", cfg.WebhookKeys)
+	}
+
+	wantDay := time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC)
+	if !cfg.WebhookKeys[0].NotAfter.Equal(wantDay) {
+		t.Errorf("date not_after = %s, want end of day %s", cfg.WebhookKeys[0].NotAfter, wantDay)
+	}
+	if want := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC); !cfg.WebhookKeys[1].NotAfter.Equal(want) {
+		t.Errorf("RFC 3339 not_after = %s, want %s", cfg.WebhookKeys[1].NotAfter, want)
+	}
+	if !cfg.WebhookKeys[2].NotAfter.IsZero() {
+		t.Errorf("key without not_after should never expire, got %s", cfg.WebhookKeys[2].NotAfter)
+	}
+}
+
+func TestLoadFile_WebhookKeyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{
+			name:    "missing id",
+			content: "security:\n  keys:\n    - secret: s\n",
+			wantKey: "security.keys[0].id",
+		},
+		{
+			name:    "missing secret",
+			content: "security:\n  keys:\n    - id: k1\n",
+			wantKey: "security.keys[0].secret",
+		},
+		{
+			name:    "duplicate id",
+			content: "security:\n  keys:\n    - id: k1\n      secret: s\n    - id: k1\n      secret: t\n",
+			wantKey: "security.keys[1].id",
+		},
+		{
+			name:    "id taken by webhook_secret",
+			content: "security:\n  webhook_secret: s\n  keys:\n    - id: default\n      secret: t\n",
+			wantKey: "security.keys[0].id",
+		},
+		{
+			name:    "invalid not_after",
+			content: "security:\n  keys:\n    - id: k1\n      secret: s\n      not_after: soon\n",
+			wantKey: "security.keys[0].not_after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Key != tt.wantKey {
+				t.Errorf("LoadFile() error = %v, want error on %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadFile_PublicKeys(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+security:
+  auth: public_key
+  public_keys:
+    - id: deploy
+      file: /etc/cloud-update/keys/deploy.pub
+    - id: ci
+      file: /etc/cloud-update/keys/ci.pub
+      not_after: 2026-06-30
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Auth != AuthPublicKey {
+		t.Errorf("Auth = %q, want %q", cfg.Auth, AuthPublicKey)
+	}
+	if cfg.Secret != "" {
+		t.Errorf("Secret = %q, want empty with public key authentication", cfg.Secret)
+	}
+	if len(cfg.PublicKeys) != 2 || cfg.PublicKeys[1].File != "/etc/cloud-update/keys/ci.pub" {
+		t.Fatalf("PublicKeys = %+v, want 2 keys", cfg.PublicKeys)
+	}
+	if cfg.PublicKeys[1].NotAfter.IsZero() {
+		t.Error("not_after should be parsed")
+	}
+}
+
+func TestLoadFile_PublicKeyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{
+			name:    "unknown method",
+			content: "security:\n  auth: rsa\n  webhook_secret: s\n",
+			wantKey: "security.auth",
+		},
+		{
+			name:    "no public keys",
+			content: "security:\n  auth: public_key\n",
+			wantKey: "security.public_keys",
+		},
+		{
+			name:    "missing file",
+			content: "security:\n  auth: public_key\n  public_keys:\n    - id: deploy\n",
+			wantKey: "security.public_keys[0].file",
+		},
+		{
+			name:    "duplicate id",
+			content: "security:\n  auth: public_key\n  public_keys:\n    - id: a\n      file: a.pub\n    - id: a\n      file: b.pub\n",
+			wantKey: "security.public_keys[1].id",
+		},
+		{
+			name:    "invalid not_after",
+			content: "security:\n  auth: public_key\n  public_keys:\n    - id: a\n      file: a.pub\n      not_after: soon\n",
+			wantKey: "security.public_keys[0].not_after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Key != tt.wantKey {
+				t.Errorf("LoadFile() error = %v, want error on %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadFile_ACME(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CLOUD_UPDATE_ACME_EMAIL", "ops@example.com")
+	path := writeConfigFile(t, `
+security:
+  webhook_secret: s
+tls:
+  enabled: true
+  auto: true
+  domain: update.example.com
+  acme:
+    directory: "https://localhost:14000/dir"
+    challenge: http-01
+    http_addr: ":5002"
+    ca_file: /etc/cloud-update/tls/pebble.pem
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	want := ACMEConfig{
+		DirectoryURL: "https://localhost:14000/dir",
+		Email:        "ops@example.com",
+		Challenge:    "http-01",
+		HTTPAddr:     ":5002",
+		CacheDir:     "/etc/cloud-update/tls/acme",
+		CAFile:       "/etc/cloud-update/tls/pebble.pem",
+	}
+	if cfg.TLS.ACME != want {
+		t.Errorf("ACME = %+v, want %+v", cfg.TLS.ACME, want)
+	}
+
+	cfg, err = LoadFile(writeConfigFile(t, "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  auto: true\n  domain: example.com\n"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.TLS.ACME.DirectoryURL != "https://acme-v02.api.letsencrypt.org/directory" || cfg.TLS.ACME.Challenge != "tls-alpn-01" {
+		t.Errorf("ACME = %+v, want Let's Encrypt with tls-alpn-01", cfg.TLS.ACME)
+	}
+}
+
+func TestLoadFile_ACMEErrors(t *testing.T) {
+	const auto = "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  auto: true\n  domain: example.com\n  acme:\n"
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{
+			name:    "plain http directory",
+			content: auto + "    directory: http://localhost:14000/dir\n",
+			wantKey: "tls.acme.directory",
+		},
+		{
+			name:    "unknown challenge",
+			content: auto + "    challenge: dns-01\n",
+			wantKey: "tls.acme.challenge",
+		},
+		{
+			name:    "invalid http address",
+			content: auto + "    challenge: http-01\n    http_addr: \"80\"\n",
+			wantKey: "tls.acme.http_addr",
+		},
+		{
+			name:    "empty cache",
+			content: auto + "    cache: \"\"\n",
+			wantKey: "tls.acme.cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Key != tt.wantKey {
+				t.Errorf("LoadFile() error = %v, want error on %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadFile_Maintenance(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Paris"); err != nil {
+		t.Skip("time zone database not available")
+	}
+	clearEnv(t)
+	path := writeConfigFile(t, `security:
+  webhook_secret: s
+maintenance:
+  windows: ["sat,sun 02:00-05:00", "fri 23:00-01:00"]
+  timezone: Europe/Paris
+  actions: [reboot]
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	policy := cfg.Maintenance
+	if len(policy.Windows) != 2 || policy.Location.String() != "Europe/Paris" {
+		t.Errorf("Maintenance = %+v", policy)
+	}
+	if !policy.Restricts(entity.ActionReboot) || policy.Restricts(entity.ActionUpgrade) {
+		t.Errorf("Maintenance.Actions = %v, want [reboot]", policy.Actions.List())
+	}
+
+	t.Setenv("CLOUD_UPDATE_MAINTENANCE_WINDOWS", "mon 01:00-02:00;tue 01:00-02:00;wed 01:00-02:00")
+	t.Setenv("CLOUD_UPDATE_MAINTENANCE_TIMEZONE", "UTC")
+	if cfg, err = LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(cfg.Maintenance.Windows) != 3 || cfg.Maintenance.Location != time.UTC {
+		t.Errorf("Maintenance from environment = %+v", cfg.Maintenance)
+	}
+}
+
+func TestLoadFile_MaintenanceErrors(t *testing.T) {
+	const base = "security:\n  webhook_secret: s\nmaintenance:\n"
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{"invalid window", base + "  windows: [\"sat 2am-5am\"]\n", "maintenance.windows"},
+		{"unknown time zone", base + "  timezone: Mars/Olympus\n", "maintenance.timezone"},
+		{"unknown action", base + "  actions: [reformat]\n", "maintenance.actions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Key != tt.wantKey {
+				t.Errorf("LoadFile() error = %v, want error on %s", err, tt.wantKey)
+			}
+		})
+	}
+}
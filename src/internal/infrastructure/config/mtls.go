@@ -0,0 +1,105 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Client certificate verification modes.
+const (
+	ClientAuthNone     = "none"     // Client certificates are not requested
+	ClientAuthOptional = "optional" // Client certificates are verified when presented
+	ClientAuthRequired = "required" // Connections without a valid client certificate are refused
+)
+
+// ConfigureClientAuth enables client certificate verification (mutual TLS) on cfg
+// according to ClientAuth, trusting the CA bundle in ClientCAFile. When ClientAllowed
+// is set, verified certificates must also match one of its patterns.
+func (c *TLSConfig) ConfigureClientAuth(cfg *tls.Config) error {
+	switch c.ClientAuth {
+	case "", ClientAuthNone:
+		return nil
+	case ClientAuthOptional:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequired:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return fmt.Errorf("unknown client authentication mode %q", c.ClientAuth)
+	}
+
+	pool, err := loadCertPool(c.ClientCAFile)
+	if err != nil {
+		return err
+	}
+	cfg.ClientCAs = pool
+
+	if len(c.ClientAllowed) > 0 {
+		patterns := c.ClientAllowed
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.VerifiedChains) == 0 {
+				return nil // No certificate presented in optional mode
+			}
+			leaf := cs.VerifiedChains[0][0]
+			if !MatchClientCertificate(leaf, patterns) {
+				return fmt.Errorf("client certificate %q is not allowed", leaf.Subject)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// MatchClientCertificate reports whether the subject common name or one of the
+// DNS, email or URI SANs of cert matches one of the patterns (path.Match syntax).
+func MatchClientCertificate(cert *x509.Certificate, patterns []string) bool {
+	for _, name := range certificateNames(cert) {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// certificateNames returns the identities a client certificate was issued for.
+func certificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, 1+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.URIs))
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
+
+// loadCertPool reads a PEM bundle of CA certificates.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in client CA bundle %s", file)
+	}
+	return pool, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
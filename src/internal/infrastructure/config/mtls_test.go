@@ -0,0 +1,279 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues client certificates for mTLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string // PEM bundle holding the CA certificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "client-ca.pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue returns a client certificate for the common name and URI SAN.
+func (ca *testCA) issue(t *testing.T, commonName, uri string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if uri != "" {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// startMTLSServer starts an HTTPS server whose client authentication is configured by c.
+func startMTLSServer(t *testing.T, c *TLSConfig) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // Refused handshakes are expected
+	server.TLS = baseTLSConfig()
+	if err := c.ConfigureClientAuth(server.TLS); err != nil {
+		t.Fatalf("ConfigureClientAuth() error = %v", err)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// get requests the server over a new connection, presenting cert when given.
+func get(server *httptest.Server, cert *tls.Certificate) error {
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestConfigureClientAuth_Required(t *testing.T) {
+	ca := newTestCA(t)
+	server := startMTLSServer(t, &TLSConfig{
+		ClientAuth:    ClientAuthRequired,
+		ClientCAFile:  ca.file,
+		ClientAllowed: []string{"deploy-*", "spiffe://example.org/ci/*"},
+	})
+
+	deploy := ca.issue(t, "deploy-eu1", "")
+	if err := get(server, &deploy); err != nil {
+		t.Errorf("allowed common name should be accepted: %v", err)
+	}
+	ci := ca.issue(t, "", "spiffe://example.org/ci/runner")
+	if err := get(server, &ci); err != nil {
+		t.Errorf("allowed URI SAN should be accepted: %v", err)
+	}
+
+	other := ca.issue(t, "laptop", "")
+	if err := get(server, &other); err == nil {
+		t.Error("certificate not matching the allowed patterns should be refused")
+	}
+	untrusted := newTestCA(t).issue(t, "deploy-eu1", "")
+	if err := get(server, &untrusted); err == nil {
+		t.Error("certificate from another CA should be refused")
+	}
+	if err := get(server, nil); err == nil {
+		t.Error("connection without a client certificate should be refused")
+	}
+}
+
+func TestConfigureClientAuth_Optional(t *testing.T) {
+	ca := newTestCA(t)
+	server := startMTLSServer(t, &TLSConfig{
+		ClientAuth:    ClientAuthOptional,
+		ClientCAFile:  ca.file,
+		ClientAllowed: []string{"deploy-*"},
+	})
+
+	if err := get(server, nil); err != nil {
+		t.Errorf("connection without a client certificate should be accepted: %v", err)
+	}
+	deploy := ca.issue(t, "deploy-eu1", "")
+	if err := get(server, &deploy); err != nil {
+		t.Errorf("allowed certificate should be accepted: %v", err)
+	}
+	other := ca.issue(t, "laptop", "")
+	if err := get(server, &other); err == nil {
+		t.Error("presented certificate not matching the allowed patterns should be refused")
+	}
+}
+
+func TestConfigureClientAuth_Errors(t *testing.T) {
+	ca := newTestCA(t)
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *TLSConfig
+		wantErr bool
+	}{
+		{"disabled", &TLSConfig{ClientAuth: ClientAuthNone}, false},
+		{"unset", &TLSConfig{}, false},
+		{"valid bundle", &TLSConfig{ClientAuth: ClientAuthRequired, ClientCAFile: ca.file}, false},
+		{"unknown mode", &TLSConfig{ClientAuth: "sometimes", ClientCAFile: ca.file}, true},
+		{"missing bundle", &TLSConfig{ClientAuth: ClientAuthRequired, ClientCAFile: "/nonexistent/ca.pem"}, true},
+		{"empty bundle", &TLSConfig{ClientAuth: ClientAuthRequired, ClientCAFile: notPEM}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ConfigureClientAuth(baseTLSConfig())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfigureClientAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile_ClientAuth(t *testing.T) {
+	clearEnv(t)
+	cfg, err := LoadFile(writeConfigFile(t, `
+security:
+  auth: mtls
+tls:
+  enabled: true
+  client_auth: required
+  client_ca: /etc/cloud-update/tls/client-ca.pem
+  client_allowed: ["deploy-*", "spiffe://example.org/ci/*"]
+`))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Auth != AuthMTLS || cfg.TLS.ClientAuth != ClientAuthRequired {
+		t.Errorf("Auth = %q, ClientAuth = %q", cfg.Auth, cfg.TLS.ClientAuth)
+	}
+	if len(cfg.TLS.ClientAllowed) != 2 {
+		t.Errorf("ClientAllowed = %v, want 2 patterns", cfg.TLS.ClientAllowed)
+	}
+
+	t.Setenv("CLOUD_UPDATE_TLS_CLIENT_ALLOWED", "ops-*, ")
+	cfg, err = LoadFile(writeConfigFile(t, "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  client_auth: optional\n  client_ca: ca.pem\n"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(cfg.TLS.ClientAllowed) != 1 || cfg.TLS.ClientAllowed[0] != "ops-*" {
+		t.Errorf("ClientAllowed = %v, want [ops-*]", cfg.TLS.ClientAllowed)
+	}
+}
+
+func TestLoadFile_ClientAuthErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantKey string
+	}{
+		{
+			name:    "unknown mode",
+			content: "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  client_auth: maybe\n  client_ca: ca.pem\n",
+			wantKey: "tls.client_auth",
+		},
+		{
+			name:    "missing CA bundle",
+			content: "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  client_auth: required\n",
+			wantKey: "tls.client_ca",
+		},
+		{
+			name:    "TLS disabled",
+			content: "security:\n  webhook_secret: s\ntls:\n  client_auth: required\n  client_ca: ca.pem\n",
+			wantKey: "tls.client_auth",
+		},
+		{
+			name:    "mtls auth without required certificates",
+			content: "security:\n  auth: mtls\ntls:\n  enabled: true\n  client_auth: optional\n  client_ca: ca.pem\n",
+			wantKey: "tls.client_auth",
+		},
+		{
+			name:    "invalid pattern",
+			content: "security:\n  webhook_secret: s\ntls:\n  enabled: true\n  client_auth: required\n  client_ca: ca.pem\n  client_allowed: [\"deploy-[\"]\n",
+			wantKey: "tls.client_allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := LoadFile(writeConfigFile(t, tt.content))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Key != tt.wantKey {
+				t.Errorf("LoadFile() error = %v, want error on %s", err, tt.wantKey)
+			}
+		})
+	}
+}
@@ -2,10 +2,22 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/acme"
 )
 
 // TLSConfig holds TLS/HTTPS configuration.
@@ -15,35 +27,29 @@ type TLSConfig struct {
 	KeyFile  string // Path to private key file
 	Auto     bool   // Use automatic certificate management (Let's Encrypt)
 	Domain   string // Domain for automatic certificates
-}
 
-// LoadTLSConfig loads TLS configuration from environment variables.
-func LoadTLSConfig() *TLSConfig {
-	cfg := &TLSConfig{
-		Enabled:  os.Getenv("CLOUD_UPDATE_TLS_ENABLED") == "true",
-		CertFile: os.Getenv("CLOUD_UPDATE_TLS_CERT"),
-		KeyFile:  os.Getenv("CLOUD_UPDATE_TLS_KEY"),
-		Auto:     os.Getenv("CLOUD_UPDATE_TLS_AUTO") == "true",
-		Domain:   os.Getenv("CLOUD_UPDATE_DOMAIN"),
-	}
+	ClientAuth    string   // Client certificate verification: none, optional or required
+	ClientCAFile  string   // CA bundle verifying client certificates
+	ClientAllowed []string // Patterns client certificate names must match (empty allows any)
 
-	// Default paths if not specified
-	if cfg.Enabled && !cfg.Auto {
-		if cfg.CertFile == "" {
-			cfg.CertFile = "/etc/cloud-update/tls/cert.pem"
-		}
-		if cfg.KeyFile == "" {
-			cfg.KeyFile = "/etc/cloud-update/tls/key.pem"
-		}
-	}
+	ExpiryWarning time.Duration // Report the certificate as expiring when less than this remains
 
-	// Clear cert/key paths when auto mode is enabled AND TLS is enabled
-	if cfg.Enabled && cfg.Auto {
-		cfg.CertFile = ""
-		cfg.KeyFile = ""
-	}
+	ACME ACMEConfig // Automatic certificate settings, used when Auto is set
+}
 
-	return cfg
+// ACMEConfig holds the settings of automatic certificate management.
+type ACMEConfig struct {
+	DirectoryURL string // ACME directory, e.g. Let's Encrypt or a local Pebble instance
+	Email        string // Account contact, optional
+	Challenge    string // tls-alpn-01 or http-01
+	HTTPAddr     string // Listen address answering http-01 challenges
+	CacheDir     string // Directory holding the account key and certificates
+	CAFile       string // CA bundle trusted for the directory, empty for the system pool
+}
+
+// CertificateSource provides the certificate presented to TLS clients.
+type CertificateSource interface {
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
 // Validate checks if the TLS configuration is valid.
@@ -57,6 +63,11 @@ func (c *TLSConfig) Validate() error {
 		if c.Domain == "" {
 			return fmt.Errorf("domain required for automatic TLS certificates")
 		}
+		switch c.ACME.Challenge {
+		case "", acme.ChallengeTLSALPN01, acme.ChallengeHTTP01:
+		default:
+			return fmt.Errorf("unsupported ACME challenge %q", c.ACME.Challenge)
+		}
 		return nil
 	}
 
@@ -88,9 +99,26 @@ func (c *TLSConfig) GetTLSConfig() (*tls.Config, error) {
 		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
+	cfg := baseTLSConfig()
+	cfg.Certificates = []tls.Certificate{cert}
+	if err := c.ConfigureClientAuth(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ServerTLSConfig returns a crypto/tls.Config serving the certificate provided by certs,
+// so certificates can be replaced without restarting the listener.
+func ServerTLSConfig(certs CertificateSource) *tls.Config {
+	cfg := baseTLSConfig()
+	cfg.GetCertificate = certs.GetCertificate
+	return cfg
+}
+
+// baseTLSConfig returns the protocol settings shared by every server TLS configuration.
+func baseTLSConfig() *tls.Config {
 	return &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		MinVersion:   tls.VersionTLS12, // Minimum TLS 1.2
+		MinVersion: tls.VersionTLS12, // Minimum TLS 1.2
 		CipherSuites: []uint16{
 			// Prefer modern, secure cipher suites
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
@@ -105,14 +133,97 @@ func (c *TLSConfig) GetTLSConfig() (*tls.Config, error) {
 			tls.X25519,
 			tls.CurveP256,
 		},
-	}, nil
+	}
+}
+
+// DefaultCertValidity is how long generated self-signed certificates are valid.
+const DefaultCertValidity = 365 * 24 * time.Hour
+
+// DefaultCertHosts returns the names a self-signed certificate is issued for by
+// default: the host name of the machine and the loopback addresses.
+func DefaultCertHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" && hostname != "localhost" {
+		hosts = append([]string{hostname}, hosts...)
+	}
+	return hosts
+}
+
+// NewSelfSignedCert returns a PEM encoded self-signed certificate valid for hosts
+// (DNS names or IP addresses) and its ECDSA P-256 private key.
+func NewSelfSignedCert(hosts []string, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	if len(hosts) == 0 {
+		return nil, nil, fmt.Errorf("at least one host is required")
+	}
+	if validity <= 0 {
+		validity = DefaultCertValidity
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{"Cloud Update"}},
+		NotBefore:             now.Add(-5 * time.Minute), // tolerate clock skew
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		switch {
+		case host == "" || strings.ContainsAny(host, " /\\"):
+			return nil, nil, fmt.Errorf("invalid certificate host %q", host)
+		case net.ParseIP(host) != nil:
+			template.IPAddresses = append(template.IPAddresses, net.ParseIP(host))
+		default:
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
 }
 
-// GenerateSelfSignedCert generates a self-signed certificate for development.
-func GenerateSelfSignedCert(_, _ string, _ []string) error {
-	// This would use crypto/x509 and crypto/rsa to generate certificates
-	// For brevity, returning an error suggesting to use external tools
-	return fmt.Errorf("use 'openssl' or 'mkcert' to generate self-signed certificates for development")
+// GenerateSelfSignedCert writes a self-signed certificate for hosts to certFile and
+// its private key to keyFile, creating their directories.
+func GenerateSelfSignedCert(certFile, keyFile string, hosts []string, validity time.Duration) error {
+	certPEM, keyPEM, err := NewSelfSignedCert(hosts, validity)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{filepath.Dir(certFile), filepath.Dir(keyFile)} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create certificate directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil { //nolint:gosec // certificates are public
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+	return nil
 }
 
 // SetupCertificateDirectory creates the TLS certificate directory if it doesn't exist.
@@ -133,13 +244,14 @@ Place your TLS certificates in this directory:
 - cert.pem: The server certificate
 - key.pem: The private key
 
-## Generate Self-Signed Certificate (Development)
+## Generate Self-Signed Certificate
 
 ` + "```bash" + `
-openssl req -x509 -newkey rsa:4096 -keyout key.pem -out cert.pem -days 365 -nodes \
-  -subj "/C=US/ST=State/L=City/O=Organization/CN=localhost"
+cloud-update --gen-cert --cert-hosts "$(hostname),192.0.2.10" --cert-days 365
 ` + "```" + `
 
+Clients must trust cert.pem explicitly (e.g. curl --cacert cert.pem).
+
 ## Production Certificates
 
 For production, use certificates from a trusted CA or Let's Encrypt.
@@ -147,6 +259,9 @@ For production, use certificates from a trusted CA or Let's Encrypt.
 Enable automatic certificates with:
 - CLOUD_UPDATE_TLS_AUTO=true
 - CLOUD_UPDATE_DOMAIN=your-domain.com
+
+The ACME account key and the issued certificates are cached in the acme/
+subdirectory.
 `
 
 	if err := os.WriteFile(readmePath, []byte(readme), 0600); err != nil {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -8,8 +9,10 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"math/big"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -78,186 +81,6 @@ func createTempCertFiles(t *testing.T, valid bool) (certFile, keyFile string) {
 	return certFile, keyFile
 }
 
-// tlsConfigTestCase represents a test case for TLS configuration loading.
-type tlsConfigTestCase struct {
-	name     string
-	envVars  map[string]string
-	expected TLSConfig
-}
-
-// getTLSConfigTestCases returns all test cases for TLS configuration loading.
-func getTLSConfigTestCases() []tlsConfigTestCase {
-	return []tlsConfigTestCase{
-		{
-			name:    "default config - TLS disabled",
-			envVars: map[string]string{},
-			expected: TLSConfig{
-				Enabled:  false,
-				CertFile: "",
-				KeyFile:  "",
-				Auto:     false,
-				Domain:   "",
-			},
-		},
-		{
-			name: "TLS enabled with custom paths",
-			envVars: map[string]string{
-				"CLOUD_UPDATE_TLS_ENABLED": "true",
-				"CLOUD_UPDATE_TLS_CERT":    "/custom/cert.pem",
-				"CLOUD_UPDATE_TLS_KEY":     "/custom/key.pem",
-			},
-			expected: TLSConfig{
-				Enabled:  true,
-				CertFile: "/custom/cert.pem",
-				KeyFile:  "/custom/key.pem",
-				Auto:     false,
-				Domain:   "",
-			},
-		},
-		{
-			name: "TLS enabled with default paths",
-			envVars: map[string]string{
-				"CLOUD_UPDATE_TLS_ENABLED": "true",
-			},
-			expected: TLSConfig{
-				Enabled:  true,
-				CertFile: "/etc/cloud-update/tls/cert.pem",
-				KeyFile:  "/etc/cloud-update/tls/key.pem",
-				Auto:     false,
-				Domain:   "",
-			},
-		},
-		{
-			name: "auto TLS enabled",
-			envVars: map[string]string{
-				"CLOUD_UPDATE_TLS_ENABLED": "true",
-				"CLOUD_UPDATE_TLS_AUTO":    "true",
-				"CLOUD_UPDATE_DOMAIN":      "example.com",
-			},
-			expected: TLSConfig{
-				Enabled:  true,
-				CertFile: "",
-				KeyFile:  "",
-				Auto:     true,
-				Domain:   "example.com",
-			},
-		},
-		{
-			name: "auto TLS with manual cert paths (should ignore paths)",
-			envVars: map[string]string{
-				"CLOUD_UPDATE_TLS_ENABLED": "true",
-				"CLOUD_UPDATE_TLS_AUTO":    "true",
-				"CLOUD_UPDATE_TLS_CERT":    "/ignored/cert.pem",
-				"CLOUD_UPDATE_TLS_KEY":     "/ignored/key.pem",
-				"CLOUD_UPDATE_DOMAIN":      "example.com",
-			},
-			expected: TLSConfig{
-				Enabled:  true,
-				CertFile: "", // Cleared when auto is enabled
-				KeyFile:  "", // Cleared when auto is enabled
-				Auto:     true,
-				Domain:   "example.com",
-			},
-		},
-		{
-			name: "TLS disabled with other vars set",
-			envVars: map[string]string{
-				"CLOUD_UPDATE_TLS_ENABLED": "false",
-				"CLOUD_UPDATE_TLS_CERT":    "/some/cert.pem",
-				"CLOUD_UPDATE_TLS_KEY":     "/some/key.pem",
-				"CLOUD_UPDATE_TLS_AUTO":    "true",
-				"CLOUD_UPDATE_DOMAIN":      "example.com",
-			},
-			expected: TLSConfig{
-				Enabled:  false,
-				CertFile: "/some/cert.pem",
-				KeyFile:  "/some/key.pem",
-				Auto:     true,
-				Domain:   "example.com",
-			},
-		},
-	}
-}
-
-// setupTestEnvironment saves original environment variables and returns a cleanup function.
-func setupTestEnvironment() func() {
-	originalVars := map[string]string{
-		"CLOUD_UPDATE_TLS_ENABLED": os.Getenv("CLOUD_UPDATE_TLS_ENABLED"),
-		"CLOUD_UPDATE_TLS_CERT":    os.Getenv("CLOUD_UPDATE_TLS_CERT"),
-		"CLOUD_UPDATE_TLS_KEY":     os.Getenv("CLOUD_UPDATE_TLS_KEY"),
-		"CLOUD_UPDATE_TLS_AUTO":    os.Getenv("CLOUD_UPDATE_TLS_AUTO"),
-		"CLOUD_UPDATE_DOMAIN":      os.Getenv("CLOUD_UPDATE_DOMAIN"),
-	}
-	return func() {
-		for key, value := range originalVars {
-			if value == "" {
-				_ = os.Unsetenv(key)
-			} else {
-				os.Setenv(key, value)
-			}
-		}
-	}
-}
-
-// clearTLSEnvironment clears all TLS-related environment variables.
-func clearTLSEnvironment() {
-	envKeys := []string{
-		"CLOUD_UPDATE_TLS_ENABLED",
-		"CLOUD_UPDATE_TLS_CERT",
-		"CLOUD_UPDATE_TLS_KEY",
-		"CLOUD_UPDATE_TLS_AUTO",
-		"CLOUD_UPDATE_DOMAIN",
-	}
-	for _, key := range envKeys {
-		_ = os.Unsetenv(key)
-	}
-}
-
-// setTestEnvironment sets environment variables for a test case.
-func setTestEnvironment(envVars map[string]string) {
-	for key, value := range envVars {
-		if value == "" {
-			_ = os.Unsetenv(key)
-		} else {
-			os.Setenv(key, value)
-		}
-	}
-}
-
-// assertTLSConfig compares actual and expected TLS configurations.
-func assertTLSConfig(t *testing.T, actual *TLSConfig, expected TLSConfig) {
-	if actual.Enabled != expected.Enabled {
-		t.Errorf("Enabled = %v, want %v", actual.Enabled, expected.Enabled)
-	}
-	if actual.CertFile != expected.CertFile {
-		t.Errorf("CertFile = %q, want %q", actual.CertFile, expected.CertFile)
-	}
-	if actual.KeyFile != expected.KeyFile {
-		t.Errorf("KeyFile = %q, want %q", actual.KeyFile, expected.KeyFile)
-	}
-	if actual.Auto != expected.Auto {
-		t.Errorf("Auto = %v, want %v", actual.Auto, expected.Auto)
-	}
-	if actual.Domain != expected.Domain {
-		t.Errorf("Domain = %q, want %q", actual.Domain, expected.Domain)
-	}
-}
-
-func TestLoadTLSConfig(t *testing.T) {
-	cleanup := setupTestEnvironment()
-	defer cleanup()
-
-	tests := getTLSConfigTestCases()
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			clearTLSEnvironment()
-			setTestEnvironment(tt.envVars)
-			config := LoadTLSConfig()
-			assertTLSConfig(t, config, tt.expected)
-		})
-	}
-}
-
 func TestTLSConfig_Validate(t *testing.T) {
 	validCertFile, validKeyFile := createTempCertFiles(t, true)
 	invalidCertFile, invalidKeyFile := createTempCertFiles(t, false)
@@ -525,47 +348,66 @@ func testCurvePreferences(t *testing.T, tlsConfig *tls.Config) {
 }
 
 func TestGenerateSelfSignedCert(t *testing.T) {
-	tests := []struct {
-		name     string
-		certPath string
-		keyPath  string
-		hosts    []string
-	}{
-		{
-			name:     "basic call",
-			certPath: "cert.pem",
-			keyPath:  "key.pem",
-			hosts:    []string{"localhost"},
-		},
-		{
-			name:     "multiple hosts",
-			certPath: "cert.pem",
-			keyPath:  "key.pem",
-			hosts:    []string{"localhost", "example.com", "127.0.0.1"},
-		},
-		{
-			name:     "empty hosts",
-			certPath: "cert.pem",
-			keyPath:  "key.pem",
-			hosts:    []string{},
-		},
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls", "cert.pem")
+	keyPath := filepath.Join(dir, "tls", "key.pem")
+
+	err := GenerateSelfSignedCert(certPath, keyPath, []string{"update.example.com", "192.0.2.10", "::1"}, 48*time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateSelfSignedCert() error = %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := GenerateSelfSignedCert(tt.certPath, tt.keyPath, tt.hosts)
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v (%v), want 0600", info.Mode().Perm(), err)
+	}
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("LoadX509KeyPair() error = %v", err)
+	}
 
-			// The function should always return an error suggesting external tools
-			if err == nil {
-				t.Error("GenerateSelfSignedCert() should return an error suggesting external tools")
-				return
-			}
+	cert := pair.Leaf
+	if cert.Subject.CommonName != "update.example.com" {
+		t.Errorf("CommonName = %q, want update.example.com", cert.Subject.CommonName)
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "update.example.com" {
+		t.Errorf("DNSNames = %v, want [update.example.com]", cert.DNSNames)
+	}
+	if len(cert.IPAddresses) != 2 || !cert.IPAddresses[0].Equal(net.ParseIP("192.0.2.10")) {
+		t.Errorf("IPAddresses = %v, want [192.0.2.10 ::1]", cert.IPAddresses)
+	}
+	if lifetime := cert.NotAfter.Sub(cert.NotBefore); lifetime < 48*time.Hour || lifetime > 49*time.Hour {
+		t.Errorf("validity = %v, want about 48h", lifetime)
+	}
+	if _, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok {
+		t.Errorf("public key is %T, want ECDSA", cert.PublicKey)
+	}
 
-			expectedMsg := "use 'openssl' or 'mkcert' to generate self-signed certificates"
-			if !strings.Contains(err.Error(), expectedMsg) {
-				t.Errorf("GenerateSelfSignedCert() error = %v, want error containing %q", err, expectedMsg)
-			}
-		})
+	// A client trusting the certificate file accepts it for every host.
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	for _, host := range []string{"update.example.com", "192.0.2.10", "::1"} {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("Verify(%s) error = %v", host, err)
+		}
+	}
+}
+
+func TestNewSelfSignedCert_Errors(t *testing.T) {
+	if _, _, err := NewSelfSignedCert(nil, time.Hour); err == nil {
+		t.Error("NewSelfSignedCert() without hosts should fail")
+	}
+	if _, _, err := NewSelfSignedCert([]string{"bad host"}, time.Hour); err == nil {
+		t.Error("NewSelfSignedCert() with an invalid host should fail")
+	}
+	if _, _, err := NewSelfSignedCert([]string{"localhost"}, 0); err != nil {
+		t.Errorf("NewSelfSignedCert() with the default validity error = %v", err)
+	}
+}
+
+func TestDefaultCertHosts(t *testing.T) {
+	hosts := DefaultCertHosts()
+	if !slices.Contains(hosts, "localhost") || !slices.Contains(hosts, "127.0.0.1") {
+		t.Errorf("DefaultCertHosts() = %v, want localhost and 127.0.0.1", hosts)
 	}
 }
 
@@ -720,12 +562,6 @@ func TestTLSConfig_GetTLSConfig_EdgeCases(t *testing.T) {
 }
 
 // Benchmark tests.
-func BenchmarkLoadTLSConfig(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		LoadTLSConfig()
-	}
-}
-
 func BenchmarkTLSConfig_Validate(b *testing.B) {
 	config := &TLSConfig{
 		Enabled: true,
@@ -0,0 +1,41 @@
+// Package httputil provides helpers shared by the HTTP middlewares and handlers.
+package httputil
+
+import "net/http"
+
+// StatusRecorder remembers the status code written to a response.
+type StatusRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps w. The status is 200 until another one is written.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, code: http.StatusOK}
+}
+
+// Status returns the status code sent with the response.
+func (r *StatusRecorder) Status() int {
+	return r.code
+}
+
+// WriteHeader records the first status code written and sends it.
+func (r *StatusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write sends b, with an implicit 200 status when none was written.
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, to flush streams.
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
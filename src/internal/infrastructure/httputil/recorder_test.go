@@ -0,0 +1,49 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  int
+	}{
+		{"nothing written", func(w http.ResponseWriter) {}, http.StatusOK},
+		{"body only", func(w http.ResponseWriter) { _, _ = w.Write([]byte("ok")) }, http.StatusOK},
+		{"status", func(w http.ResponseWriter) { w.WriteHeader(http.StatusAccepted) }, http.StatusAccepted},
+		{"status after body", func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte("ok"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusOK},
+		{"second status", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusOK)
+		}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewStatusRecorder(httptest.NewRecorder())
+			tt.write(rec)
+			if rec.Status() != tt.want {
+				t.Errorf("Status() = %d, want %d", rec.Status(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusRecorder_Flush(t *testing.T) {
+	underlying := httptest.NewRecorder()
+	rec := NewStatusRecorder(underlying)
+	_, _ = rec.Write([]byte("data"))
+	if err := http.NewResponseController(rec).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if !underlying.Flushed {
+		t.Error("Flush() did not reach the underlying writer")
+	}
+}
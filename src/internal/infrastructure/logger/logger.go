@@ -18,6 +18,7 @@ var (
 	once      sync.Once
 	mu        sync.RWMutex
 	logFile   *os.File
+	logPath   string // Configured log file, empty when logging to stdout only
 	stopChan  chan struct{}
 	monitorWG sync.WaitGroup
 )
@@ -86,6 +87,8 @@ func isRunningInTest() bool {
 }
 
 func setupFileOutput(cfg Config) error {
+	logPath = cfg.FilePath
+
 	// Create log directory if it doesn't exist
 	logDir := filepath.Dir(cfg.FilePath)
 	if err := os.MkdirAll(logDir, 0750); err != nil {
@@ -193,6 +196,24 @@ func cleanOldBackups(cfg Config) {
 	}
 }
 
+// CheckFile reports whether the log file is open and can still be written.
+func CheckFile() error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if logPath == "" {
+		return nil
+	}
+	if logFile == nil {
+		return fmt.Errorf("log file %s is not open", logPath)
+	}
+	file, err := openLogFile(logPath)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
 // Get returns the logger instance.
 func Get() *logrus.Logger {
 	if instance == nil {
@@ -210,6 +231,16 @@ func Get() *logrus.Logger {
 	return instance
 }
 
+// SetLevel changes the log level at runtime.
+func SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	Get().SetLevel(parsed)
+	return nil
+}
+
 // WithField creates an entry with a single field.
 func WithField(key string, value interface{}) *logrus.Entry {
 	return Get().WithField(key, value)
@@ -244,6 +275,7 @@ func Close() {
 		_ = logFile.Close() //nolint:errcheck // Switch to stdout, ignore close errors
 		logFile = nil
 	}
+	logPath = ""
 	// Reset stopChan here after goroutine has exited
 	stopChan = nil
 	// Reset the once to allow re-initialization in tests
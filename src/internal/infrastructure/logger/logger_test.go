@@ -115,6 +115,28 @@ func TestLogger_InvalidLevel(t *testing.T) {
 	defer Close()
 }
 
+func TestLogger_SetLevel(t *testing.T) {
+	Close()
+	if err := Initialize(Config{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel(debug) error = %v", err)
+	}
+	if Get().GetLevel() != logrus.DebugLevel {
+		t.Errorf("level = %v, want debug", Get().GetLevel())
+	}
+
+	if err := SetLevel("loud"); err == nil {
+		t.Error("SetLevel(loud) should fail")
+	}
+	if Get().GetLevel() != logrus.DebugLevel {
+		t.Errorf("invalid level changed the level to %v", Get().GetLevel())
+	}
+}
+
 func TestLogger_NoFilePath(t *testing.T) {
 	// Reset logger state
 	Close()
@@ -134,6 +156,34 @@ func TestLogger_NoFilePath(t *testing.T) {
 	Info("Test message")
 }
 
+func TestLogger_CheckFile(t *testing.T) {
+	// Reset logger state
+	Close()
+
+	tmpDir := t.TempDir()
+	if err := Initialize(Config{Level: "info", FilePath: filepath.Join(tmpDir, "logs", "test.log")}); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer Close()
+
+	if err := CheckFile(); err != nil {
+		t.Errorf("CheckFile() error = %v", err)
+	}
+
+	// The log file can no longer be opened once its directory is gone
+	if err := os.RemoveAll(filepath.Join(tmpDir, "logs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckFile(); err == nil {
+		t.Error("CheckFile() should fail when the log file cannot be written")
+	}
+
+	Close()
+	if err := CheckFile(); err != nil {
+		t.Errorf("CheckFile() without log file error = %v", err)
+	}
+}
+
 func TestLogger_CreateDirectory(t *testing.T) {
 	// Reset logger state
 	Close()
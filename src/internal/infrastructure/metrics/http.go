@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/httputil"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// Handler serves the Default registry in the Prometheus exposition format.
+func Handler() http.HandlerFunc {
+	return RegistryHandler(Default)
+}
+
+// RegistryHandler serves the metrics of a registry in the Prometheus exposition
+// format, failing metrics being logged and left out.
+func RegistryHandler(r *prometheus.Registry) http.HandlerFunc {
+	served := promhttp.HandlerFor(r, promhttp.HandlerOpts{
+		ErrorLog:      errorLogger{},
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		served.ServeHTTP(w, req)
+	}
+}
+
+// errorLogger logs the errors of the metrics handler.
+type errorLogger struct{}
+
+func (errorLogger) Println(v ...any) {
+	logger.WithField("error", v).Error("Failed to write metrics")
+}
+
+// Instrument counts the requests served by next in HTTPRequests under the handler name.
+func Instrument(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := httputil.NewStatusRecorder(w)
+		next(rec, r)
+		HTTPRequests.WithLabelValues(name, statusCode(rec.Status())).Inc()
+	}
+}
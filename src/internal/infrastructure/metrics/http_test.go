@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrument(t *testing.T) {
+	handler := Instrument("/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+		// Streaming handlers reach the underlying writer
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+	})
+	ok := testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "200"))
+	unauthorized := testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "401"))
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test?fail=1", nil))
+
+	if testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "200")) != ok+1 ||
+		testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "401")) != unauthorized+1 {
+		t.Errorf("requests = %v %v", testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "200")),
+			testutil.ToFloat64(HTTPRequests.WithLabelValues("/test", "401")))
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Test."})
+	r.MustRegister(counter)
+	counter.Inc()
+
+	rr := httptest.NewRecorder()
+	RegistryHandler(r)(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain; version=0.0.4") ||
+		!strings.Contains(rr.Body.String(), "test_total 1\n") {
+		t.Errorf("GET /metrics = %d %q %s", rr.Code, rr.Header().Get("Content-Type"), rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	Handler()(rr, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /metrics = %d, want 405", rr.Code)
+	}
+}
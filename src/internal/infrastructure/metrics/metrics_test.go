@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// sampleCount returns how many values a histogram observed.
+func sampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := o.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestRegisterFunc(t *testing.T) {
+	if err := RegisterGaugeFunc("test_busy", "Busy workers.", func() float64 { return 3 }); err != nil {
+		t.Fatalf("RegisterGaugeFunc() error = %v", err)
+	}
+	if err := RegisterCounterFunc("test_rejected_total", "Rejected.", func() float64 { return 2 }); err != nil {
+		t.Fatalf("RegisterCounterFunc() error = %v", err)
+	}
+	if err := RegisterGaugeFunc("test_busy", "Again.", func() float64 { return 0 }); err == nil {
+		t.Error("registering a name twice should fail")
+	}
+
+	if err := testutil.GatherAndCompare(Default, strings.NewReader(`# HELP test_busy Busy workers.
+# TYPE test_busy gauge
+test_busy 3
+# HELP test_rejected_total Rejected.
+# TYPE test_rejected_total counter
+test_rejected_total 2
+`), "test_busy", "test_rejected_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObserveJob(t *testing.T) {
+	start := time.Now()
+	end := start.Add(90 * time.Second)
+	completed := Jobs.WithLabelValues("reinit", "completed")
+	durations := JobDuration.WithLabelValues("reinit")
+	before := testutil.ToFloat64(completed)
+	count := sampleCount(t, durations)
+
+	ObserveJob(entity.Job{Action: entity.ActionReinit, Status: entity.JobStatusRunning, StartTime: start})
+	if testutil.ToFloat64(completed) != before || sampleCount(t, durations) != count {
+		t.Error("running jobs should not be recorded")
+	}
+
+	ObserveJob(entity.Job{Action: entity.ActionReinit, Status: entity.JobStatusCompleted, StartTime: start, EndTime: &end})
+	if testutil.ToFloat64(completed) != before+1 || sampleCount(t, durations) != count+1 {
+		t.Error("completed job was not recorded")
+	}
+
+	rr := httptest.NewRecorder()
+	Handler()(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rr.Body.String(), `cloud_update_job_duration_seconds_bucket{action="reinit",le="120"}`) {
+		t.Errorf("default registry is missing the job duration:\n%s", rr.Body.String())
+	}
+}
@@ -0,0 +1,78 @@
+// Package metrics provides the service metrics, served in the Prometheus exposition
+// format by the Prometheus client library.
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// Default is the registry served by Handler.
+var Default = prometheus.NewRegistry()
+
+// DefaultBuckets are histogram buckets, in seconds, suited to job durations.
+var DefaultBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// Service metrics.
+var (
+	// HTTPRequests counts requests by handler and response status code
+	HTTPRequests = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "cloud_update_http_requests_total",
+		Help: "HTTP requests by handler and status code.",
+	}, []string{"handler", "code"})
+	// AuthFailures counts signed requests rejected before running, by reason
+	AuthFailures = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "cloud_update_auth_failures_total",
+		Help: "Signed requests rejected by timestamp, signature or nonce checks.",
+	}, []string{"reason"})
+	// Jobs counts finished jobs by action and final status
+	Jobs = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "cloud_update_jobs_total",
+		Help: "Finished jobs by action and status.",
+	}, []string{"action", "status"})
+	// JobDuration records how long jobs ran, by action
+	JobDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cloud_update_job_duration_seconds",
+		Help:    "Run time of finished jobs by action.",
+		Buckets: DefaultBuckets,
+	}, []string{"action"})
+)
+
+// Reasons of authentication failures.
+const (
+	AuthReasonTimestamp = "timestamp"
+	AuthReasonSignature = "signature"
+	AuthReasonNonce     = "nonce"
+)
+
+// RegisterGaugeFunc registers on Default a gauge reporting the value returned by fn.
+func RegisterGaugeFunc(name, help string, fn func() float64) error {
+	return Default.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, fn))
+}
+
+// RegisterCounterFunc registers on Default a counter reporting the value returned
+// by fn, which must never decrease.
+func RegisterCounterFunc(name, help string, fn func() float64) error {
+	return Default.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, fn))
+}
+
+// ObserveJob records the outcome and run time of a job. Jobs that are not
+// finished yet, such as reboots waiting for the system to restart, are ignored.
+func ObserveJob(job entity.Job) {
+	if !job.Status.IsFinal() {
+		return
+	}
+	Jobs.WithLabelValues(string(job.Action), string(job.Status)).Inc()
+	if job.EndTime != nil && !job.EndTime.Before(job.StartTime) {
+		JobDuration.WithLabelValues(string(job.Action)).Observe(job.EndTime.Sub(job.StartTime).Seconds())
+	}
+}
+
+// statusCode formats an HTTP status code as a label value.
+func statusCode(code int) string {
+	return strconv.Itoa(code)
+}
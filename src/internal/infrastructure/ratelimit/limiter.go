@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -23,6 +24,7 @@ type RateLimiter struct {
 	ttl      time.Duration
 	lastSeen map[string]time.Time
 	maxSize  int // Maximum number of limiters to keep in memory
+	rejected atomic.Uint64
 }
 
 // Config holds rate limiter configuration.
@@ -58,6 +60,20 @@ func NewRateLimiter(cfg Config) *RateLimiter {
 	return rl
 }
 
+// Update applies a new configuration, including to the clients already being tracked.
+func (rl *RateLimiter) Update(cfg Config) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.limit = rate.Limit(cfg.RequestsPerSecond)
+	rl.burst = cfg.Burst
+	rl.ttl = cfg.TTL
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(rl.limit)
+		limiter.SetBurst(rl.burst)
+	}
+}
+
 // Allow checks if a request from the given identifier is allowed.
 func (rl *RateLimiter) Allow(identifier string) bool {
 	// Fast path: check with read lock first
@@ -70,7 +86,11 @@ func (rl *RateLimiter) Allow(identifier string) bool {
 		rl.mu.Lock()
 		rl.lastSeen[identifier] = time.Now()
 		rl.mu.Unlock()
-		return limiter.Allow()
+		if !limiter.Allow() {
+			rl.rejected.Add(1)
+			return false
+		}
+		return true
 	}
 
 	// Slow path: create new limiter
@@ -96,6 +116,7 @@ func (rl *RateLimiter) Allow(identifier string) bool {
 	allowed := limiter.Allow()
 
 	if !allowed {
+		rl.rejected.Add(1)
 		logger.WithField("identifier", identifier).Warn("Rate limit exceeded")
 	}
 
@@ -239,6 +260,18 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// Rejected returns how many requests were rejected since the limiter was created.
+func (rl *RateLimiter) Rejected() uint64 {
+	return rl.rejected.Load()
+}
+
+// ActiveClients returns the number of clients being tracked.
+func (rl *RateLimiter) ActiveClients() int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return len(rl.limiters)
+}
+
 // Stats returns statistics about current rate limiters.
 func (rl *RateLimiter) Stats() map[string]interface{} {
 	rl.mu.RLock()
@@ -249,5 +282,6 @@ func (rl *RateLimiter) Stats() map[string]interface{} {
 		"limit_per_second": int(rl.limit),
 		"burst_size":       rl.burst,
 		"ttl_minutes":      int(rl.ttl.Minutes()),
+		"rejected":         rl.Rejected(),
 	}
 }
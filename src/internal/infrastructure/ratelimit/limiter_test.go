@@ -697,3 +697,57 @@ func TestRateLimiter_TimeBasedRecovery(t *testing.T) {
 		t.Error("request after recovery should be allowed")
 	}
 }
+
+func TestRateLimiter_Update(t *testing.T) {
+	rl := NewRateLimiter(Config{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		TTL:               15 * time.Minute,
+	})
+
+	if !rl.Allow("client1") {
+		t.Fatal("first request should be allowed")
+	}
+	if rl.Allow("client1") {
+		t.Fatal("second request should exceed the burst")
+	}
+
+	rl.Update(Config{
+		RequestsPerSecond: 100,
+		Burst:             10,
+		TTL:               time.Minute,
+	})
+
+	stats := rl.Stats()
+	if stats["limit_per_second"] != 100 || stats["burst_size"] != 10 || stats["ttl_minutes"] != 1 {
+		t.Errorf("stats after update = %v", stats)
+	}
+
+	// The existing client gets the new burst once its tokens refill
+	time.Sleep(50 * time.Millisecond)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if rl.Allow("client1") {
+			allowed++
+		}
+	}
+	if allowed < 2 {
+		t.Errorf("allowed %d requests after raising the limit, want more than 1", allowed)
+	}
+}
+
+func TestRateLimiter_Rejected(t *testing.T) {
+	rl := NewRateLimiter(Config{RequestsPerSecond: 1, Burst: 2, TTL: time.Minute})
+
+	for i := 0; i < 5; i++ {
+		rl.Allow("client1")
+	}
+	rl.Allow("client2")
+
+	if rl.Rejected() != 3 {
+		t.Errorf("Rejected() = %d, want 3", rl.Rejected())
+	}
+	if rl.ActiveClients() != 2 || rl.Stats()["rejected"] != uint64(3) {
+		t.Errorf("ActiveClients() = %d, stats = %v", rl.ActiveClients(), rl.Stats())
+	}
+}
@@ -7,15 +7,61 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
 )
 
+// SignatureHeader carries the signature of a request body.
+const SignatureHeader = "X-Cloud-Update-Signature"
+
+// KeyIDHeader names the key a request was signed with. Without it, every active key is tried.
+const KeyIDHeader = "X-Cloud-Update-Key-Id"
+
+// DefaultKeyID is the ID of the key created from a single webhook secret.
+const DefaultKeyID = "default"
+
 // Authenticator defines the interface for request authentication.
 type Authenticator interface {
 	ValidateSignature(r *http.Request, body []byte) bool
 }
 
+// KeyMatcher is implemented by authenticators that can name the key a request was signed with.
+type KeyMatcher interface {
+	// MatchKey returns the ID of the key that signed a valid request, or false for an invalid one.
+	MatchKey(r *http.Request, body []byte) (string, bool)
+}
+
+// Authenticate validates a request with auth. It also returns the ID of the key the
+// request was signed with, when auth is a KeyMatcher.
+func Authenticate(auth Authenticator, r *http.Request, body []byte) (string, bool) {
+	if matcher, ok := auth.(KeyMatcher); ok {
+		return matcher.MatchKey(r, body)
+	}
+	return "", auth.ValidateSignature(r, body)
+}
+
+// HMACKey is a named webhook signing secret.
+type HMACKey struct {
+	ID       string
+	Secret   string
+	NotAfter time.Time // The key is rejected after this time (zero never expires)
+}
+
+// Expired reports whether the key is no longer valid at the given time.
+func (k HMACKey) Expired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && now.After(k.NotAfter)
+}
+
+// Sign returns the signature of body, as sent in the SignatureHeader.
+func (k HMACKey) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(k.Secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
 type hmacAuthenticator struct {
-	secret string
+	keys []HMACKey
 }
 
 // NewHMACAuthenticator creates a new HMAC-based authenticator.
@@ -23,23 +69,75 @@ func NewHMACAuthenticator(secret string) (Authenticator, error) {
 	if secret == "" {
 		return nil, fmt.Errorf("HMAC secret cannot be empty")
 	}
-	if len(secret) < 32 {
-		return nil, fmt.Errorf("HMAC secret must be at least 32 characters long for security")
+	return NewHMACKeyringAuthenticator([]HMACKey{{ID: DefaultKeyID, Secret: secret}})
+}
+
+// NewHMACKeyringAuthenticator creates an HMAC authenticator accepting several named keys,
+// so secrets can be rotated without every signer switching at the same time.
+func NewHMACKeyringAuthenticator(keys []HMACKey) (Authenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one HMAC key is required")
 	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("HMAC key ID cannot be empty")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate HMAC key ID %q", key.ID)
+		}
+		seen[key.ID] = true
+
+		if len(key.Secret) < 32 {
+			return nil, fmt.Errorf("HMAC secret must be at least 32 characters long for security (key %q)", key.ID)
+		}
+	}
+
 	return &hmacAuthenticator{
-		secret: secret,
+		keys: append([]HMACKey(nil), keys...),
 	}, nil
 }
 
 func (a *hmacAuthenticator) ValidateSignature(r *http.Request, body []byte) bool {
-	signature := r.Header.Get("X-Cloud-Update-Signature")
+	_, ok := a.MatchKey(r, body)
+	return ok
+}
+
+// MatchKey returns the ID of the active key whose signature matches the request.
+func (a *hmacAuthenticator) MatchKey(r *http.Request, body []byte) (string, bool) {
+	signature := r.Header.Get(SignatureHeader)
 	if signature == "" {
-		return false
+		return "", false
 	}
 
-	expectedMAC := hmac.New(sha256.New, []byte(a.secret))
-	expectedMAC.Write(body)
-	expectedSignature := "sha256=" + hex.EncodeToString(expectedMAC.Sum(nil))
+	keyID := r.Header.Get(KeyIDHeader)
+	now := time.Now()
+	found := false
+
+	for _, key := range a.keys {
+		if keyID != "" && key.ID != keyID {
+			continue
+		}
+		found = true
+
+		if key.Expired(now) {
+			if keyID != "" {
+				logger.WithField("key_id", key.ID).
+					WithField("not_after", key.NotAfter).
+					Warn("Rejected request signed with an expired key")
+			}
+			continue
+		}
 
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+		if hmac.Equal([]byte(signature), []byte(key.Sign(body))) {
+			logger.WithField("key_id", key.ID).Info("Request signature validated")
+			return key.ID, true
+		}
+	}
+
+	if keyID != "" && !found {
+		logger.WithField("key_id", keyID).Warn("Rejected request signed with an unknown key")
+	}
+	return "", false
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewHMACAuthenticator(t *testing.T) {
@@ -101,6 +102,117 @@ func TestHMACAuthenticator_ValidateSignature(t *testing.T) {
 	}
 }
 
+func TestNewHMACKeyringAuthenticator(t *testing.T) {
+	secret := "test-secret-key-that-is-at-least-32-characters-long"
+
+	tests := []struct {
+		name    string
+		keys    []HMACKey
+		wantErr bool
+	}{
+		{"single key", []HMACKey{{ID: "k1", Secret: secret}}, false},
+		{"several keys", []HMACKey{{ID: "k1", Secret: secret}, {ID: "k2", Secret: secret + "-2"}}, false},
+		{"no keys", nil, true},
+		{"missing ID", []HMACKey{{Secret: secret}}, true},
+		{"duplicate ID", []HMACKey{{ID: "k1", Secret: secret}, {ID: "k1", Secret: secret}}, true},
+		{"short secret", []HMACKey{{ID: "k1", Secret: "too-short"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHMACKeyringAuthenticator(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewHMACKeyringAuthenticator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticator_Keyring(t *testing.T) {
+	oldKey := HMACKey{ID: "2024", Secret: "old-secret-key-that-is-at-least-32-characters"}
+	newKey := HMACKey{ID: "2025", Secret: "new-secret-key-that-is-at-least-32-characters"}
+	expiredKey := HMACKey{
+		ID:       "2023",
+		Secret:   "expired-secret-key-that-is-at-least-32-chars",
+		NotAfter: time.Now().Add(-time.Hour),
+	}
+
+	auth, err := NewHMACKeyringAuthenticator([]HMACKey{oldKey, newKey, expiredKey})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	body := []byte(`{"action":"update"}`)
+
+	tests := []struct {
+		name  string
+		key   HMACKey
+		keyID string
+		want  bool
+	}{
+		{"old key without key ID", oldKey, "", true},
+		{"new key without key ID", newKey, "", true},
+		{"new key with its key ID", newKey, "2025", true},
+		{"new key with another key ID", newKey, "2024", false},
+		{"unknown key ID", newKey, "1999", false},
+		{"expired key", expiredKey, "", false},
+		{"expired key with its key ID", expiredKey, "2023", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+			req.Header.Set("X-Cloud-Update-Signature", tt.key.Sign(body))
+			if tt.keyID != "" {
+				req.Header.Set(KeyIDHeader, tt.keyID)
+			}
+
+			if got := auth.ValidateSignature(req, body); got != tt.want {
+				t.Errorf("ValidateSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_MatchKey(t *testing.T) {
+	oldKey := HMACKey{ID: "2024", Secret: "old-secret-key-that-is-at-least-32-characters"}
+	newKey := HMACKey{ID: "2025", Secret: "new-secret-key-that-is-at-least-32-characters"}
+	keyring, err := NewHMACKeyringAuthenticator([]HMACKey{oldKey, newKey})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	auth := NewReloadableAuthenticator(keyring)
+	body := []byte(`{"action":"update"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header.Set("X-Cloud-Update-Signature", newKey.Sign(body))
+	if keyID, ok := Authenticate(auth, req, body); !ok || keyID != "2025" {
+		t.Errorf("Authenticate() = %q, %v, want 2025, true", keyID, ok)
+	}
+
+	req.Header.Set("X-Cloud-Update-Signature", "sha256=invalid")
+	if keyID, ok := Authenticate(auth, req, body); ok || keyID != "" {
+		t.Errorf("Authenticate() = %q, %v, want rejected", keyID, ok)
+	}
+
+	// Authenticators that cannot name keys only validate
+	if keyID, ok := Authenticate(NewClientCertAuthenticator(), req, body); ok || keyID != "" {
+		t.Errorf("Authenticate() without client certificate = %q, %v, want rejected", keyID, ok)
+	}
+}
+
+func TestHMACKey_Expired(t *testing.T) {
+	now := time.Now()
+	if (HMACKey{}).Expired(now) {
+		t.Error("key without not_after should never expire")
+	}
+	if (HMACKey{NotAfter: now.Add(time.Minute)}).Expired(now) {
+		t.Error("key should be valid before not_after")
+	}
+	if !(HMACKey{NotAfter: now.Add(-time.Minute)}).Expired(now) {
+		t.Error("key should be expired after not_after")
+	}
+}
+
 func BenchmarkHMACAuthenticator_ValidateSignature(b *testing.B) {
 	secret := "benchmark-secret-key-that-is-at-least-32-characters"
 	auth, err := NewHMACAuthenticator(secret)
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"net/http"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// ClientIdentity returns the identity of the verified TLS client certificate of r:
+// its subject, or its first SAN when the subject is empty. It returns "" when the
+// client did not present a verified certificate.
+func ClientIdentity(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	if subject := cert.Subject.String(); subject != "" {
+		return subject
+	}
+	switch {
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	}
+	return ""
+}
+
+type clientCertAuthenticator struct{}
+
+// NewClientCertAuthenticator creates an authenticator accepting any request made with
+// a verified TLS client certificate. The certificate is checked against the client CA
+// bundle and allowed names during the TLS handshake.
+func NewClientCertAuthenticator() Authenticator {
+	return clientCertAuthenticator{}
+}
+
+func (clientCertAuthenticator) ValidateSignature(r *http.Request, _ []byte) bool {
+	identity := ClientIdentity(r)
+	if identity == "" {
+		logger.Warn("Rejected request without a verified client certificate")
+		return false
+	}
+	logger.WithField("client_identity", identity).Info("Request client certificate validated")
+	return true
+}
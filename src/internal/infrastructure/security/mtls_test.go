@@ -0,0 +1,81 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// withClientCert returns a request made over TLS with a verified client certificate.
+func withClientCert(cert *x509.Certificate) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return req
+}
+
+func TestClientIdentity(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://example.org/ci/runner")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{
+			name: "plain HTTP",
+			req:  httptest.NewRequest(http.MethodPost, "/webhook", nil),
+			want: "",
+		},
+		{
+			name: "TLS without client certificate",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+				req.TLS = &tls.ConnectionState{}
+				return req
+			}(),
+			want: "",
+		},
+		{
+			name: "subject",
+			req:  withClientCert(&x509.Certificate{Subject: pkix.Name{CommonName: "deploy", Organization: []string{"Ops"}}}),
+			want: "CN=deploy,O=Ops",
+		},
+		{
+			name: "URI SAN without subject",
+			req:  withClientCert(&x509.Certificate{URIs: []*url.URL{spiffe}, DNSNames: []string{"ci.example.org"}}),
+			want: "spiffe://example.org/ci/runner",
+		},
+		{
+			name: "DNS SAN without subject",
+			req:  withClientCert(&x509.Certificate{DNSNames: []string{"ci.example.org"}}),
+			want: "ci.example.org",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClientIdentity(tt.req); got != tt.want {
+				t.Errorf("ClientIdentity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	auth := NewClientCertAuthenticator()
+
+	verified := withClientCert(&x509.Certificate{Subject: pkix.Name{CommonName: "deploy"}})
+	if !auth.ValidateSignature(verified, nil) {
+		t.Error("request with a verified client certificate should be accepted")
+	}
+
+	plain := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	plain.Header.Set("X-Cloud-Update-Signature", "sha256=abcdef")
+	if auth.ValidateSignature(plain, nil) {
+		t.Error("request without a client certificate should be rejected")
+	}
+}
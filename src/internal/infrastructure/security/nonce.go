@@ -0,0 +1,276 @@
+package security
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// Request freshness limits used for replay protection.
+const (
+	MaxRequestAge = 5 * time.Minute  // Older requests are rejected as expired
+	MaxClockSkew  = 30 * time.Second // Tolerated clock drift of signers sending future timestamps
+)
+
+// DefaultNonceCacheSize is the default number of nonces remembered.
+const DefaultNonceCacheSize = 10000
+
+// MaxNonceLength is the maximum accepted length of a request nonce.
+const MaxNonceLength = 128
+
+// nonceTTL is how long a nonce must be remembered: after that the request timestamp
+// check rejects the request anyway.
+const nonceTTL = MaxRequestAge + MaxClockSkew
+
+// nonceEntry is a remembered nonce, also the on-disk record format. Removed records
+// forget a nonce recorded earlier in the file.
+type nonceEntry struct {
+	Nonce   string    `json:"nonce"`
+	Expires time.Time `json:"expires,omitzero"`
+	Removed bool      `json:"removed,omitempty"`
+}
+
+// NonceCache remembers the nonces of recently accepted requests to reject replays.
+// It is bounded: when full, the oldest nonces are forgotten first.
+type NonceCache struct {
+	mu       sync.Mutex
+	maxSize  int
+	seen     map[string]time.Time // nonce -> expiry
+	order    []nonceEntry         // insertion order, which is also expiry order
+	path     string
+	file     *os.File
+	appended int // records appended since the file was last compacted
+}
+
+// NewNonceCache creates an in-memory nonce cache holding at most maxSize nonces.
+func NewNonceCache(maxSize int) *NonceCache {
+	if maxSize <= 0 {
+		maxSize = DefaultNonceCacheSize
+	}
+	return &NonceCache{
+		maxSize: maxSize,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+// NewFileNonceCache creates a nonce cache persisted to path, so replays are still
+// rejected after a service restart.
+func NewFileNonceCache(path string, maxSize int) (*NonceCache, error) {
+	c := NewNonceCache(maxSize)
+	c.path = path
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create nonce cache directory: %w", err)
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add records a nonce. It returns false if the nonce was already seen.
+func (c *NonceCache) Add(nonce string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.expire(now)
+
+	if _, seen := c.seen[nonce]; seen {
+		return false
+	}
+
+	entry := nonceEntry{Nonce: nonce, Expires: now.Add(nonceTTL)}
+	c.insert(entry)
+	c.persist(entry)
+	return true
+}
+
+// Remove forgets a nonce, so that the request it came with can be sent again
+// after being rejected.
+func (c *NonceCache) Remove(nonce string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, seen := c.seen[nonce]; !seen {
+		return
+	}
+	c.forget(nonce)
+	c.persist(nonceEntry{Nonce: nonce, Removed: true})
+}
+
+// Len returns the number of nonces currently remembered.
+func (c *NonceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(time.Now())
+	return len(c.order)
+}
+
+// Close closes the nonce file of a persistent cache.
+func (c *NonceCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// insert adds an entry, forgetting the oldest nonce when the cache is full.
+// Only requests with a valid signature reach the cache, so it cannot be flushed
+// by an attacker to make a captured request replayable.
+func (c *NonceCache) insert(entry nonceEntry) {
+	if len(c.order) >= c.maxSize {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.seen, oldest.Nonce)
+		logger.WithField("max_size", c.maxSize).Warn("Nonce cache full, forgetting the oldest nonce")
+	}
+	c.order = append(c.order, entry)
+	c.seen[entry.Nonce] = entry.Expires
+}
+
+// forget removes a remembered nonce.
+func (c *NonceCache) forget(nonce string) {
+	delete(c.seen, nonce)
+	for i, entry := range c.order {
+		if entry.Nonce == nonce {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// expire forgets the nonces whose request timestamps can no longer be accepted.
+func (c *NonceCache) expire(now time.Time) {
+	n := 0
+	for n < len(c.order) && now.After(c.order[n].Expires) {
+		delete(c.seen, c.order[n].Nonce)
+		n++
+	}
+	if n > 0 {
+		c.order = c.order[n:]
+	}
+}
+
+// load reads the remembered nonces back from the file.
+func (c *NonceCache) load() error {
+	f, err := os.Open(c.path) //nolint:gosec // path comes from service configuration
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open nonce cache: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry nonceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Nonce == "" {
+			continue // A crash can leave a truncated last line behind
+		}
+		if entry.Removed {
+			if _, seen := c.seen[entry.Nonce]; seen {
+				c.forget(entry.Nonce)
+			}
+			continue
+		}
+		if now.After(entry.Expires) {
+			continue
+		}
+		if _, seen := c.seen[entry.Nonce]; !seen {
+			c.insert(entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read nonce cache: %w", err)
+	}
+	return nil
+}
+
+// persist appends an entry to the nonce file, compacting it once it has grown
+// past twice the cache size. It is called with c.mu held.
+func (c *NonceCache) persist(entry nonceEntry) {
+	if c.path == "" {
+		return // In-memory cache
+	}
+	if c.file == nil {
+		logger.WithField("nonce", entry.Nonce).Error("Nonce cache is closed, nonce not persisted")
+		return
+	}
+
+	if c.appended >= c.maxSize {
+		err := c.compact()
+		if err == nil {
+			return // compact wrote the remembered nonces, up to date with entry
+		}
+		// The current file is still valid: append to it and compact again later
+		logger.WithField("error", err).Error("Failed to compact nonce cache")
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.WithField("nonce", entry.Nonce).WithField("error", err).Error("Failed to persist nonce")
+		return
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		logger.WithField("nonce", entry.Nonce).WithField("error", err).Error("Failed to persist nonce")
+		return
+	}
+	c.appended++
+}
+
+// compact atomically rewrites the nonce file with the remembered nonces. The new
+// file is written through the handle later appended to, so the current file is
+// only replaced once nothing can fail anymore.
+func (c *NonceCache) compact() error {
+	tmpPath := c.path + ".tmp"
+	flags := os.O_CREATE | os.O_TRUNC | os.O_APPEND | os.O_WRONLY
+	tmp, err := os.OpenFile(tmpPath, flags, 0o600) //nolint:gosec // path from config
+	if err != nil {
+		return fmt.Errorf("failed to create nonce cache: %w", err)
+	}
+	discard := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+	}
+
+	enc := json.NewEncoder(tmp)
+	for _, entry := range c.order {
+		if err := enc.Encode(entry); err != nil {
+			discard()
+			return fmt.Errorf("failed to write nonce cache: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		discard()
+		return fmt.Errorf("failed to sync nonce cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		discard()
+		return fmt.Errorf("failed to replace nonce cache: %w", err)
+	}
+
+	if c.file != nil {
+		_ = c.file.Close()
+	}
+	c.file = tmp
+	c.appended = 0
+	return nil
+}
@@ -0,0 +1,180 @@
+package security
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNonceCache_Add(t *testing.T) {
+	c := NewNonceCache(10)
+
+	if !c.Add("n1") {
+		t.Error("first use of a nonce should be accepted")
+	}
+	if c.Add("n1") {
+		t.Error("replayed nonce should be rejected")
+	}
+	if !c.Add("n2") {
+		t.Error("another nonce should be accepted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestNonceCache_Bounded(t *testing.T) {
+	c := NewNonceCache(2)
+	c.Add("n1")
+	c.Add("n2")
+	c.Add("n3")
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	if c.Add("n3") {
+		t.Error("recent nonce should still be remembered")
+	}
+	if !c.Add("n1") {
+		t.Error("oldest nonce should have been forgotten")
+	}
+}
+
+func TestNonceCache_Expire(t *testing.T) {
+	c := NewNonceCache(10)
+	c.insert(nonceEntry{Nonce: "old", Expires: time.Now().Add(-time.Second)})
+
+	if !c.Add("old") {
+		t.Error("expired nonce should be accepted again")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+}
+
+func TestFileNonceCache_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.log")
+
+	c, err := NewFileNonceCache(path, 10)
+	if err != nil {
+		t.Fatalf("NewFileNonceCache() error = %v", err)
+	}
+	c.Add("n1")
+	c.Add("n2")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Simulate a truncated record and an expired nonce left in the file
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fmt.Fprintf(f, "{\"nonce\":\"old\",\"expires\":%q}\n{\"nonce\":\"n", time.Now().Add(-time.Minute).Format(time.RFC3339))
+	_ = f.Close()
+
+	reopened, err := NewFileNonceCache(path, 10)
+	if err != nil {
+		t.Fatalf("NewFileNonceCache() error = %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	if reopened.Add("n1") || reopened.Add("n2") {
+		t.Error("nonces seen before the restart should be rejected")
+	}
+	if !reopened.Add("old") {
+		t.Error("expired nonce should not be restored")
+	}
+}
+
+func TestFileNonceCache_Compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.log")
+	c, err := NewFileNonceCache(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < 20; i++ {
+		c.Add(fmt.Sprintf("n%d", i))
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines > 6 {
+		t.Errorf("nonce file has %d records, want at most twice the cache size", lines)
+	}
+	if c.Add("n19") {
+		t.Error("latest nonce should be remembered")
+	}
+}
+
+func TestFileNonceCache_Remove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.log")
+	c, err := NewFileNonceCache(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("n1")
+	c.Add("n2")
+	c.Remove("n1")
+	c.Remove("unknown")
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := NewFileNonceCache(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	if !reopened.Add("n1") {
+		t.Error("removed nonce should be accepted again after a restart")
+	}
+	if reopened.Add("n2") {
+		t.Error("remaining nonce should still be rejected")
+	}
+}
+
+func TestFileNonceCache_CompactionFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.log")
+	c, err := NewFileNonceCache(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in the way of the temporary file makes every compaction fail
+	if err := os.Mkdir(path+".tmp", 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		c.Add(fmt.Sprintf("n%d", i))
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if err := os.Remove(path + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewFileNonceCache(path, 10)
+	if err != nil {
+		t.Fatalf("NewFileNonceCache() error = %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	for i := 7; i < 10; i++ {
+		if reopened.Add(fmt.Sprintf("n%d", i)) {
+			t.Errorf("nonce n%d should have been persisted despite the failed compactions", i)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// Signature schemes accepted by the public key authenticator, used as the
+// prefix of the X-Cloud-Update-Signature header value.
+const (
+	SchemeEd25519   = "ed25519"    // Ed25519 signature of the body
+	SchemeECDSAP256 = "ecdsa-p256" // ASN.1 ECDSA P-256 signature of the body's SHA-256
+)
+
+// PublicKey is a named key trusted to sign webhook requests.
+type PublicKey struct {
+	ID       string
+	Key      crypto.PublicKey // ed25519.PublicKey or *ecdsa.PublicKey on P-256
+	NotAfter time.Time        // The key is rejected after this time (zero never expires)
+}
+
+// Expired reports whether the key is no longer valid at the given time.
+func (k PublicKey) Expired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && now.After(k.NotAfter)
+}
+
+// scheme returns the signature scheme of the key.
+func (k PublicKey) scheme() string {
+	switch key := k.Key.(type) {
+	case ed25519.PublicKey:
+		return SchemeEd25519
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() {
+			return SchemeECDSAP256
+		}
+	}
+	return ""
+}
+
+func (k PublicKey) verify(body, signature []byte) bool {
+	switch key := k.Key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, body, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(body)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	default:
+		return false
+	}
+}
+
+// ParsePublicKey parses a PEM encoded PKIX public key ("PUBLIC KEY" block).
+// Only Ed25519 and ECDSA P-256 keys are supported.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("no PEM public key found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if (PublicKey{Key: key}).scheme() == "" {
+		return nil, fmt.Errorf("unsupported public key type %T: only Ed25519 and ECDSA P-256 are supported", key)
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a PEM encoded public key from a file.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	key, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+type publicKeyAuthenticator struct {
+	keys []PublicKey
+}
+
+// NewPublicKeyAuthenticator creates an authenticator verifying Ed25519 or ECDSA P-256
+// signatures against trusted public keys. Servers only hold public keys, so a
+// compromised server cannot forge requests to the others.
+func NewPublicKeyAuthenticator(keys []PublicKey) (Authenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one public key is required")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("public key ID cannot be empty")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate public key ID %q", key.ID)
+		}
+		seen[key.ID] = true
+
+		if key.scheme() == "" {
+			return nil, fmt.Errorf("unsupported public key type %T (key %q)", key.Key, key.ID)
+		}
+	}
+
+	return &publicKeyAuthenticator{
+		keys: append([]PublicKey(nil), keys...),
+	}, nil
+}
+
+// ValidateSignature verifies the "<scheme>=<base64 signature>" signature header
+// with the key named by the key ID header, or with every key of that scheme.
+func (a *publicKeyAuthenticator) ValidateSignature(r *http.Request, body []byte) bool {
+	_, ok := a.MatchKey(r, body)
+	return ok
+}
+
+// MatchKey returns the ID of the active key whose signature matches the request.
+func (a *publicKeyAuthenticator) MatchKey(r *http.Request, body []byte) (string, bool) {
+	scheme, encoded, ok := strings.Cut(r.Header.Get(SignatureHeader), "=")
+	if !ok {
+		return "", false
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	keyID := r.Header.Get(KeyIDHeader)
+	now := time.Now()
+	found := false
+
+	for _, key := range a.keys {
+		if (keyID != "" && key.ID != keyID) || key.scheme() != scheme {
+			continue
+		}
+		found = true
+
+		if key.Expired(now) {
+			if keyID != "" {
+				logger.WithField("key_id", key.ID).
+					WithField("not_after", key.NotAfter).
+					Warn("Rejected request signed with an expired key")
+			}
+			continue
+		}
+
+		if key.verify(body, signature) {
+			logger.WithField("key_id", key.ID).WithField("scheme", scheme).Info("Request signature validated")
+			return key.ID, true
+		}
+	}
+
+	if keyID != "" && !found {
+		logger.WithField("key_id", keyID).WithField("scheme", scheme).Warn("Rejected request signed with an unknown key")
+	}
+	return "", false
+}
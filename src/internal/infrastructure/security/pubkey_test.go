@@ -0,0 +1,164 @@
+package security
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func marshalPublicKeyPEM(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestParsePublicKey(t *testing.T) {
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"ed25519", marshalPublicKeyPEM(t, edPub), false},
+		{"ecdsa p-256", marshalPublicKeyPEM(t, &p256.PublicKey), false},
+		{"ecdsa p-384", marshalPublicKeyPEM(t, &p384.PublicKey), true},
+		{"not PEM", []byte("ssh-ed25519 AAAA"), true},
+		{"private key block", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePublicKey(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	path := filepath.Join(t.TempDir(), "deploy.pub")
+	if err := os.WriteFile(path, marshalPublicKeyPEM(t, edPub), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadPublicKey(path)
+	if err != nil {
+		t.Fatalf("LoadPublicKey() error = %v", err)
+	}
+	if !edPub.Equal(key) {
+		t.Error("LoadPublicKey() returned a different key")
+	}
+
+	if _, err := LoadPublicKey(filepath.Join(t.TempDir(), "missing.pub")); err == nil {
+		t.Error("LoadPublicKey() should fail for a missing file")
+	}
+}
+
+func TestNewPublicKeyAuthenticator(t *testing.T) {
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	tests := []struct {
+		name    string
+		keys    []PublicKey
+		wantErr bool
+	}{
+		{"single key", []PublicKey{{ID: "deploy", Key: edPub}}, false},
+		{"no keys", nil, true},
+		{"missing ID", []PublicKey{{Key: edPub}}, true},
+		{"duplicate ID", []PublicKey{{ID: "deploy", Key: edPub}, {ID: "deploy", Key: edPub}}, true},
+		{"unsupported curve", []PublicKey{{ID: "deploy", Key: &p384.PublicKey}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPublicKeyAuthenticator(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPublicKeyAuthenticator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublicKeyAuthenticator_ValidateSignature(t *testing.T) {
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	ecPriv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	auth, err := NewPublicKeyAuthenticator([]PublicKey{
+		{ID: "deploy", Key: edPub},
+		{ID: "ci", Key: &ecPriv.PublicKey},
+		{ID: "retired", Key: otherPub, NotAfter: time.Now().Add(-time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	body := []byte(`{"action":"update"}`)
+	digest := sha256.Sum256(body)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecPriv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edHeader := SchemeEd25519 + "=" + base64.StdEncoding.EncodeToString(ed25519.Sign(edPriv, body))
+	ecHeader := SchemeECDSAP256 + "=" + base64.StdEncoding.EncodeToString(ecSig)
+	retiredHeader := SchemeEd25519 + "=" + base64.StdEncoding.EncodeToString(ed25519.Sign(otherPriv, body))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		keyID     string
+		want      bool
+	}{
+		{"ed25519 without key ID", body, edHeader, "", true},
+		{"ed25519 with its key ID", body, edHeader, "deploy", true},
+		{"ecdsa p-256", body, ecHeader, "", true},
+		{"ecdsa p-256 with its key ID", body, ecHeader, "ci", true},
+		{"ed25519 with another key ID", body, edHeader, "ci", false},
+		{"unknown key ID", body, edHeader, "nobody", false},
+		{"expired key", body, retiredHeader, "", false},
+		{"expired key with its key ID", body, retiredHeader, "retired", false},
+		{"tampered body", []byte(`{"action":"reboot"}`), edHeader, "", false},
+		{"wrong scheme", body, SchemeECDSAP256 + edHeader[len(SchemeEd25519):], "", false},
+		{"HMAC signature", body, "sha256=abcdef", "", false},
+		{"invalid base64", body, SchemeEd25519 + "=not base64!", "", false},
+		{"missing signature", body, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set("X-Cloud-Update-Signature", tt.signature)
+			}
+			if tt.keyID != "" {
+				req.Header.Set(KeyIDHeader, tt.keyID)
+			}
+
+			if got := auth.ValidateSignature(req, tt.body); got != tt.want {
+				t.Errorf("ValidateSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package security
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ReloadableAuthenticator is an Authenticator whose implementation can be replaced
+// at runtime, e.g. to rotate the webhook secret without restarting the service.
+type ReloadableAuthenticator struct {
+	mu      sync.RWMutex
+	current Authenticator
+}
+
+// NewReloadableAuthenticator creates a reloadable authenticator delegating to auth.
+func NewReloadableAuthenticator(auth Authenticator) *ReloadableAuthenticator {
+	return &ReloadableAuthenticator{current: auth}
+}
+
+// Swap atomically replaces the authenticator used to validate requests.
+func (a *ReloadableAuthenticator) Swap(auth Authenticator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.current = auth
+}
+
+// ValidateSignature validates the request with the current authenticator.
+func (a *ReloadableAuthenticator) ValidateSignature(r *http.Request, body []byte) bool {
+	a.mu.RLock()
+	auth := a.current
+	a.mu.RUnlock()
+
+	return auth.ValidateSignature(r, body)
+}
+
+// MatchKey validates the request with the current authenticator and names the key
+// that signed it, when that authenticator can.
+func (a *ReloadableAuthenticator) MatchKey(r *http.Request, body []byte) (string, bool) {
+	a.mu.RLock()
+	auth := a.current
+	a.mu.RUnlock()
+
+	return Authenticate(auth, r, body)
+}
@@ -0,0 +1,46 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func signedRequest(secret string, body []byte) *http.Request {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req := httptest.NewRequest("POST", "/webhook", nil)
+	req.Header.Set("X-Cloud-Update-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestReloadableAuthenticator_Swap(t *testing.T) {
+	oldSecret := "old-secret-key-that-is-at-least-32-characters"
+	newSecret := "new-secret-key-that-is-at-least-32-characters"
+	body := []byte(`{"action":"update"}`)
+
+	oldAuth, err := NewHMACAuthenticator(oldSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAuth, err := NewHMACAuthenticator(newSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewReloadableAuthenticator(oldAuth)
+	if !auth.ValidateSignature(signedRequest(oldSecret, body), body) {
+		t.Error("request signed with the current secret should be valid")
+	}
+
+	auth.Swap(newAuth)
+	if auth.ValidateSignature(signedRequest(oldSecret, body), body) {
+		t.Error("request signed with the rotated secret should be rejected")
+	}
+	if !auth.ValidateSignature(signedRequest(newSecret, body), body) {
+		t.Error("request signed with the new secret should be valid")
+	}
+}
@@ -0,0 +1,320 @@
+// Package store provides storage for job management.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// DefaultJobStorePath is the default location of the persistent job log.
+const DefaultJobStorePath = "/var/lib/cloud-update/jobs.log"
+
+// Retention defines how much job history is kept.
+type Retention struct {
+	MaxAge  time.Duration // Jobs started before now-MaxAge are dropped (0 keeps all)
+	MaxJobs int           // Maximum number of jobs kept in history
+}
+
+// DefaultRetention returns the default retention policy.
+func DefaultRetention() Retention {
+	return Retention{
+		MaxAge:  7 * 24 * time.Hour,
+		MaxJobs: 100,
+	}
+}
+
+// jobRecord is the on-disk representation of a job state change.
+type jobRecord struct {
+	ID        string               `json:"id"`
+	Action    entity.ActionType    `json:"action"`
+	Status    entity.JobStatus     `json:"status"`
+	StartTime time.Time            `json:"started"`
+	EndTime   *time.Time           `json:"ended,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Result    *entity.ActionResult `json:"result,omitempty"`
+	// ClientIdentity is the verified client certificate that requested the job
+	ClientIdentity string `json:"client_identity,omitempty"`
+	// TraceParent links the spans of a queued or scheduled job to its request
+	TraceParent string `json:"trace_parent,omitempty"`
+	// RunAt and Request let a scheduled job run after a restart
+	RunAt   *time.Time             `json:"run_at,omitempty"`
+	Request *entity.WebhookRequest `json:"request,omitempty"`
+	// CallbackURL and Callbacks record where the final job record is delivered
+	CallbackURL string                   `json:"callback_url,omitempty"`
+	Callbacks   []entity.CallbackAttempt `json:"callbacks,omitempty"`
+}
+
+func newJobRecord(job *entity.JobWithMutex) jobRecord {
+	snapshot := job.Snapshot()
+	rec := jobRecord{
+		ID:        snapshot.ID,
+		Action:    snapshot.Action,
+		Status:    snapshot.Status,
+		StartTime: snapshot.StartTime,
+		EndTime:   snapshot.EndTime,
+		Result:    snapshot.Result,
+		RunAt:     snapshot.RunAt,
+		Request:   snapshot.Request,
+
+		ClientIdentity: snapshot.ClientIdentity,
+		TraceParent:    snapshot.TraceParent,
+		CallbackURL:    snapshot.CallbackURL,
+		Callbacks:      snapshot.Callbacks,
+	}
+	if snapshot.Error != nil {
+		rec.Error = snapshot.Error.Error()
+	}
+	return rec
+}
+
+// toJob rebuilds a job from its persisted record.
+func (r jobRecord) toJob() *entity.JobWithMutex {
+	job := &entity.JobWithMutex{
+		Job: entity.Job{
+			ID:        r.ID,
+			Action:    r.Action,
+			Status:    r.Status,
+			StartTime: r.StartTime,
+			EndTime:   r.EndTime,
+			Result:    r.Result,
+			RunAt:     r.RunAt,
+			Request:   r.Request,
+
+			ClientIdentity: r.ClientIdentity,
+			TraceParent:    r.TraceParent,
+			CallbackURL:    r.CallbackURL,
+			Callbacks:      r.Callbacks,
+		},
+	}
+	if r.Error != "" {
+		job.Error = errors.New(r.Error)
+		if job.Result != nil {
+			job.Result.Err = job.Error
+		}
+	}
+	return job
+}
+
+// FileJobStore is a JobStore that persists every job state change to an
+// append-only JSON lines log, so job history survives service restarts.
+type FileJobStore struct {
+	*MemoryJobStore
+	path   string
+	file   *os.File
+	fileMu sync.Mutex
+}
+
+// NewFileJobStore opens (or creates) the job log at path and loads the jobs it contains.
+func NewFileJobStore(path string, retention Retention) (*FileJobStore, error) {
+	if retention.MaxJobs <= 0 {
+		retention.MaxJobs = DefaultRetention().MaxJobs
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+
+	s := &FileJobStore{
+		MemoryJobStore: NewJobStore(),
+		path:           path,
+	}
+	s.maxHistory = retention.MaxJobs
+
+	jobs, err := readJobLog(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Status == entity.JobStatusScheduled && job.RunAt != nil && job.Request != nil {
+			s.scheduled = append(s.scheduled, job)
+			continue
+		}
+		s.addToHistory(job)
+	}
+	if retention.MaxAge > 0 {
+		s.MemoryJobStore.CleanupOldJobs(retention.MaxAge)
+	}
+
+	// Rewrite the log so it only contains the retained jobs
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	s.onChange = s.persist
+	return s, nil
+}
+
+// readJobLog replays the job log and returns the latest state of each job in start order.
+func readJobLog(path string) ([]*entity.JobWithMutex, error) {
+	f, err := os.Open(path) //nolint:gosec // path comes from service configuration
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	order := make([]string, 0)
+	records := make(map[string]jobRecord)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var rec jobRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.ID == "" {
+			// A crash can leave a truncated last line behind
+			logger.WithField("path", path).WithField("line", line).Warn("Skipping invalid job store record")
+			continue
+		}
+		if _, seen := records[rec.ID]; !seen {
+			order = append(order, rec.ID)
+		}
+		records[rec.ID] = rec
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job store: %w", err)
+	}
+
+	jobs := make([]*entity.JobWithMutex, 0, len(order))
+	for _, id := range order {
+		jobs = append(jobs, records[id].toJob())
+	}
+	return jobs, nil
+}
+
+// persist appends the job's current state to the log.
+func (s *FileJobStore) persist(job *entity.JobWithMutex) {
+	data, err := json.Marshal(newJobRecord(job))
+	if err != nil {
+		logger.WithField("job_id", job.ID).WithField("error", err).Error("Failed to encode job record")
+		return
+	}
+	data = append(data, '\n')
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.file == nil {
+		logger.WithField("job_id", job.ID).Error("Job store is closed, job state not persisted")
+		return
+	}
+	if _, err := s.file.Write(data); err != nil {
+		logger.WithField("job_id", job.ID).WithField("error", err).Error("Failed to persist job state")
+		return
+	}
+	if err := s.file.Sync(); err != nil {
+		logger.WithField("job_id", job.ID).WithField("error", err).Warn("Failed to sync job store")
+	}
+}
+
+// compact atomically rewrites the log with one record per retained job.
+// State changes are blocked while the log is rewritten so none are lost.
+func (s *FileJobStore) compact() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*entity.JobWithMutex, 0, len(s.history)+len(s.pending)+len(s.scheduled)+1)
+	jobs = append(jobs, s.history...)
+	if s.currentJob != nil {
+		jobs = append(jobs, s.currentJob)
+	}
+	for _, p := range s.pending {
+		jobs = append(jobs, p.job)
+	}
+	jobs = append(jobs, s.scheduled...)
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // path from config
+	if err != nil {
+		return fmt.Errorf("failed to create job store: %w", err)
+	}
+
+	enc := json.NewEncoder(tmp)
+	for _, job := range jobs {
+		if err := enc.Encode(newJobRecord(job)); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("failed to write job store: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync job store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close job store: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace job store: %w", err)
+	}
+
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path from config
+	if err != nil {
+		s.file = nil
+		return fmt.Errorf("failed to open job store: %w", err)
+	}
+	return nil
+}
+
+// CleanupOldJobs removes jobs older than the specified duration and compacts the log.
+func (s *FileJobStore) CleanupOldJobs(maxAge time.Duration) {
+	s.MemoryJobStore.CleanupOldJobs(maxAge)
+	if err := s.compact(); err != nil {
+		logger.WithField("error", err).Error("Failed to compact job store")
+	}
+}
+
+// Path returns the location of the job log.
+func (s *FileJobStore) Path() string {
+	return s.path
+}
+
+// Check reports whether job state changes can still be persisted: the log must be
+// open and writable.
+func (s *FileJobStore) Check() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("job store %s is closed", s.path)
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path from config
+	if err != nil {
+		return fmt.Errorf("job store is not writable: %w", err)
+	}
+	return file.Close()
+}
+
+// Close flushes and closes the job log.
+func (s *FileJobStore) Close() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+func TestFileJobStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+
+	store, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	okJob := entity.NewJob("job-ok", entity.ActionUpdate)
+	okJob.ClientIdentity = "CN=deploy"
+	store.TryStartJob(okJob)
+	store.CompleteCurrentJob()
+
+	store.TryStartJob(entity.NewJob("job-failed", entity.ActionUpdate))
+	result := entity.NewActionResult(entity.ActionUpdate)
+	result.AddStep(entity.StepResult{Name: "update_system", Status: entity.StepStatusFailed, ExitCode: 100})
+	result.Fail(errors.New("update_system failed"))
+	store.FinishCurrentJob(result)
+
+	store.TryStartJob(entity.NewJob("job-running", entity.ActionReboot))
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	reopened, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	ok := reopened.GetJob("job-ok")
+	if ok == nil || ok.GetStatus() != entity.JobStatusCompleted || ok.EndTime == nil {
+		t.Fatalf("Expected completed job to be restored, got %+v", ok)
+	}
+	if ok.ClientIdentity != "CN=deploy" {
+		t.Errorf("Expected restored client identity, got %q", ok.ClientIdentity)
+	}
+
+	failed := reopened.GetJob("job-failed")
+	if failed == nil || failed.GetStatus() != entity.JobStatusFailed {
+		t.Fatalf("Expected failed job to be restored, got %+v", failed)
+	}
+	if failed.Error == nil || failed.Error.Error() != "update_system failed" {
+		t.Errorf("Expected restored error, got %v", failed.Error)
+	}
+	if res := failed.GetResult(); res == nil || res.ExitCode != 100 || res.Succeeded() {
+		t.Errorf("Expected restored failed result with exit code 100, got %+v", res)
+	}
+
+	if running := reopened.GetJob("job-running"); running == nil {
+		t.Error("Expected job that was running at shutdown to be restored")
+	}
+
+	// Restored jobs are history only: a new job can start
+	if !reopened.TryStartJob(entity.NewJob("job-new", entity.ActionUpdate)) {
+		t.Error("Expected to start a new job after restart")
+	}
+}
+
+func TestFileJobStore_Check(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	store, err := NewFileJobStore(filepath.Join(dir, "jobs.log"), DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	if err := store.Check(); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	// The log was removed from under the store
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Check(); err == nil {
+		t.Error("Check() should fail when the job log cannot be written")
+	}
+
+	_ = store.Close()
+	if err := store.Check(); err == nil {
+		t.Error("Check() on a closed store should fail")
+	}
+}
+
+func TestFileJobStore_Retention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+
+	store, err := NewFileJobStore(path, Retention{MaxJobs: 3})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		store.TryStartJob(entity.NewJob(fmt.Sprintf("job_%d", i), entity.ActionUpdate))
+		store.CompleteCurrentJob()
+	}
+	old := entity.NewJob("old-job", entity.ActionUpdate)
+	old.StartTime = time.Now().Add(-48 * time.Hour)
+	store.TryStartJob(old)
+	store.CompleteCurrentJob()
+	_ = store.Close()
+
+	reopened, err := NewFileJobStore(path, Retention{MaxAge: 24 * time.Hour, MaxJobs: 3})
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	if reopened.GetJob("old-job") != nil {
+		t.Error("Expected job older than MaxAge to be dropped")
+	}
+	if reopened.GetJob("job_2") != nil {
+		t.Error("Expected jobs beyond MaxJobs to be dropped")
+	}
+	for _, id := range []string{"job_3", "job_4"} {
+		if reopened.GetJob(id) == nil {
+			t.Errorf("Expected %s to be retained", id)
+		}
+	}
+
+	// The log is compacted to one record per retained job
+	reopened.CleanupOldJobs(24 * time.Hour)
+	jobs, err := readJobLog(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Errorf("Expected 2 jobs in compacted log, got %d", len(jobs))
+	}
+}
+
+func TestFileJobStore_SkipsCorruptRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	content := `{"id":"job-a","action":"update","status":"completed","started":"2026-01-01T00:00:00Z"}
+{"id":"job-b","action":"upd`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	store, err := NewFileJobStore(path, Retention{})
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if store.GetJob("job-a") == nil {
+		t.Error("Expected valid record to be loaded")
+	}
+	if store.GetJob("job-b") != nil {
+		t.Error("Expected truncated record to be skipped")
+	}
+}
+
+func TestFileJobStore_ReconcileAfterReboot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+
+	store, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	store.TryStartJob(entity.NewJob("reboot-job", entity.ActionReboot))
+	result := entity.NewActionResult(entity.ActionReboot)
+	result.RebootPending = true
+	store.FinishCurrentJob(result)
+	_ = store.Close()
+
+	// Service comes back after a boot that happened after the job started
+	reopened, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	reopened.Reconcile(time.Now())
+	_ = reopened.Close()
+
+	// The reconciled state is persisted
+	final, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer func() { _ = final.Close() }()
+
+	job := final.GetJob("reboot-job")
+	if job == nil || job.GetStatus() != entity.JobStatusCompleted {
+		t.Fatalf("Expected reboot job to be completed after reconciliation, got %+v", job)
+	}
+	if res := job.GetResult(); res == nil || res.RebootPending {
+		t.Errorf("Expected reboot to no longer be pending, got %+v", res)
+	}
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// RecordCallbackAttempt records an attempt to deliver the completion callback of a
+// job. Unknown jobs, such as jobs removed from the history, are ignored.
+func (s *MemoryJobStore) RecordCallbackAttempt(jobID string, attempt entity.CallbackAttempt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.findJob(jobID)
+	if job == nil {
+		return
+	}
+	job.AddCallbackAttempt(attempt)
+	s.notifyChange(job)
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+func TestJobStore_RecordCallbackAttempt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	store, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	job := entity.NewJob("job-1", entity.ActionUpdate)
+	job.CallbackURL = "https://ci.example.com/hook"
+	store.TryStartJob(job)
+	store.CompleteCurrentJob()
+
+	store.RecordCallbackAttempt("job-1", entity.CallbackAttempt{
+		Attempt: 1, Time: time.Now(), URL: job.CallbackURL, Error: "connection refused",
+	})
+	store.RecordCallbackAttempt("job-1", entity.CallbackAttempt{
+		Attempt: 2, Time: time.Now(), URL: job.CallbackURL, StatusCode: 204,
+	})
+	store.RecordCallbackAttempt("unknown", entity.CallbackAttempt{Attempt: 1}) // Ignored
+	if callbacks := job.Snapshot().Callbacks; len(callbacks) != 2 {
+		t.Fatalf("Callbacks = %+v, want 2 attempts", callbacks)
+	}
+	_ = store.Close()
+
+	// Delivery attempts survive restarts
+	reopened, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	restored := reopened.GetJob("job-1").Snapshot()
+	if restored.CallbackURL != job.CallbackURL || len(restored.Callbacks) != 2 ||
+		restored.Callbacks[0].Error != "connection refused" || restored.Callbacks[1].StatusCode != 204 {
+		t.Errorf("restored job = %+v", restored)
+	}
+}
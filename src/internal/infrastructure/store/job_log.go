@@ -0,0 +1,344 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// Job log bounds.
+const (
+	DefaultJobLogDir = "/var/lib/cloud-update/logs"
+	JobLogLines      = 1000             // Lines of each job kept in memory
+	MaxJobLogSize    = 10 * 1024 * 1024 // Bytes of each job written to disk
+)
+
+// LogLine is a line of command output of a job, addressed by its offset.
+type LogLine struct {
+	Offset int       `json:"offset"`
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Line   string    `json:"line"`
+}
+
+// JobLog is the command output of a job: the last JobLogLines lines in memory
+// and, when the log has a file, every line up to MaxJobLogSize bytes on disk.
+type JobLog struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	size      int64
+	truncated bool
+	ring      []LogLine
+	head      int  // Index of the oldest line once ring is full
+	next      int  // Offset of the next line
+	loaded    bool // Only the file of a job finished before a restart is available
+	closed    bool
+	closedAt  time.Time
+	changed   chan struct{} // Closed and replaced whenever the log changes
+}
+
+func newJobLog(path string) *JobLog {
+	l := &JobLog{path: path, changed: make(chan struct{})}
+	if path == "" {
+		return l
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // checked job ID
+	if err != nil {
+		logger.WithField("path", path).WithField("error", err).Error("Failed to create job log, keeping it in memory")
+		l.path = ""
+		return l
+	}
+	l.file = file
+	return l
+}
+
+// Append adds a line to the log. Lines appended after Close are dropped.
+func (l *JobLog) Append(stream, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+
+	entry := LogLine{Offset: l.next, Time: time.Now().UTC(), Stream: stream, Line: line}
+	l.next++
+	if len(l.ring) < JobLogLines {
+		l.ring = append(l.ring, entry)
+	} else {
+		l.ring[l.head] = entry
+		l.head = (l.head + 1) % len(l.ring)
+	}
+	l.write(entry)
+	l.notify()
+}
+
+// write appends a line to the log file until it reaches MaxJobLogSize.
+func (l *JobLog) write(entry LogLine) {
+	if l.file == nil || l.truncated {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	if l.size+int64(len(data)) > MaxJobLogSize {
+		l.truncated = true
+		logger.WithField("path", l.path).Warn("Job log reached its size limit, later lines are kept in memory only")
+		return
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		l.truncated = true
+		logger.WithField("path", l.path).WithField("error", err).Error("Failed to write job log")
+	}
+}
+
+// Close marks the log complete and closes its file.
+func (l *JobLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	l.closedAt = time.Now()
+	l.notify()
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// notify wakes up the readers waiting for a change. The caller holds l.mu.
+func (l *JobLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Changed returns a channel closed on the next appended line or on Close.
+func (l *JobLog) Changed() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.changed
+}
+
+// Closed reports whether the job has finished writing to the log.
+func (l *JobLog) Closed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// Read returns up to limit lines starting at offset, and the offset to continue
+// from. Lines no longer in memory are read from the log file; lines missing from
+// the file too are skipped.
+func (l *JobLog) Read(offset, limit int) ([]LogLine, int) {
+	l.mu.Lock()
+	first := l.next - len(l.ring)
+	if l.path == "" || (!l.loaded && offset >= first) {
+		lines := l.ringLines(offset, limit)
+		l.mu.Unlock()
+		return lines, nextOffset(lines, max(offset, first))
+	}
+	path, loaded := l.path, l.loaded
+	l.mu.Unlock()
+
+	to := first
+	if loaded {
+		to = -1
+	}
+	lines, err := readLogFile(path, offset, to, limit)
+	if err != nil {
+		logger.WithField("path", path).WithField("error", err).Error("Failed to read job log")
+	}
+	if !loaded && len(lines) < limit {
+		// Continue with the lines kept in memory
+		more, next := l.Read(first, limit-len(lines))
+		return append(lines, more...), next
+	}
+	return lines, nextOffset(lines, offset)
+}
+
+// ringLines returns up to limit lines kept in memory starting at offset. The
+// caller holds l.mu.
+func (l *JobLog) ringLines(offset, limit int) []LogLine {
+	first := l.next - len(l.ring)
+	var lines []LogLine
+	for o := max(offset, first); o < l.next && len(lines) < limit; o++ {
+		lines = append(lines, l.ring[(l.head+o-first)%len(l.ring)])
+	}
+	return lines
+}
+
+func nextOffset(lines []LogLine, offset int) int {
+	if len(lines) == 0 {
+		return offset
+	}
+	return lines[len(lines)-1].Offset + 1
+}
+
+// readLogFile reads up to limit lines of the log file at path with an offset in
+// [from, to). A negative to reads up to the end of the file.
+func readLogFile(path string, from, to, limit int) ([]LogLine, error) {
+	file, err := os.Open(path) //nolint:gosec // path built from a checked job ID
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var lines []LogLine
+	reader := bufio.NewReader(file)
+	for len(lines) < limit {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			var line LogLine
+			if json.Unmarshal(data, &line) == nil && line.Offset >= from && (to < 0 || line.Offset < to) {
+				lines = append(lines, line)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+	return lines, nil
+}
+
+// JobLogs keeps the logs of jobs, in memory and in a directory when one is set.
+type JobLogs struct {
+	dir  string
+	mu   sync.Mutex
+	logs map[string]*JobLog
+}
+
+// NewJobLogs creates the job log storage. An empty dir keeps the logs in memory only.
+func NewJobLogs(dir string) (*JobLogs, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create job log directory: %w", err)
+		}
+	}
+	return &JobLogs{dir: dir, logs: make(map[string]*JobLog)}, nil
+}
+
+// NewMemoryJobLogs creates a job log storage keeping the logs in memory only.
+func NewMemoryJobLogs() *JobLogs {
+	logs, _ := NewJobLogs("")
+	return logs
+}
+
+// Dir returns the directory job logs are written to (empty in memory).
+func (s *JobLogs) Dir() string {
+	return s.dir
+}
+
+// path returns the log file of a job, or an empty path when the logs are kept in
+// memory or the job ID cannot be used as a file name.
+func (s *JobLogs) path(jobID string) string {
+	if s.dir == "" || jobID == "" || strings.ContainsAny(jobID, `/\`) || strings.HasPrefix(jobID, ".") {
+		return ""
+	}
+	return filepath.Join(s.dir, jobID+".log")
+}
+
+// Create starts the log of a job, replacing any previous log with the same ID.
+func (s *JobLogs) Create(jobID string) *JobLog {
+	l := newJobLog(s.path(jobID))
+
+	s.mu.Lock()
+	previous := s.logs[jobID]
+	s.logs[jobID] = l
+	s.mu.Unlock()
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+	return l
+}
+
+// Get returns the log of a job, loading it from its file when it is no longer in
+// memory. It returns nil when the job has no log.
+func (s *JobLogs) Get(jobID string) *JobLog {
+	s.mu.Lock()
+	l := s.logs[jobID]
+	s.mu.Unlock()
+	if l != nil {
+		return l
+	}
+
+	path := s.path(jobID)
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return &JobLog{path: path, loaded: true, closed: true, changed: make(chan struct{})}
+}
+
+// CleanupOldLogs drops the logs of jobs finished more than maxAge ago.
+func (s *JobLogs) CleanupOldLogs(maxAge time.Duration) {
+	cutoff := time.Now().Add(-maxAge)
+
+	s.mu.Lock()
+	for id, l := range s.logs {
+		l.mu.Lock()
+		old := l.closed && l.closedAt.Before(cutoff)
+		l.mu.Unlock()
+		if old {
+			delete(s.logs, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to list job logs")
+		return
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".log")
+		if !ok || entry.IsDir() || s.open(id) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			logger.WithField("error", err).Error("Failed to remove job log")
+		}
+	}
+}
+
+// open reports whether the log of a job is still being written.
+func (s *JobLogs) open(jobID string) bool {
+	s.mu.Lock()
+	l := s.logs[jobID]
+	s.mu.Unlock()
+	return l != nil && !l.Closed()
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJobLog_ReadFromOffset(t *testing.T) {
+	l := NewMemoryJobLogs().Create("job-1")
+	l.Append("stdout", "one")
+	l.Append("stderr", "two")
+	l.Append("stdout", "three")
+
+	lines, next := l.Read(1, 10)
+	if len(lines) != 2 || lines[0].Line != "two" || lines[0].Stream != "stderr" || lines[1].Offset != 2 {
+		t.Fatalf("Read(1) = %+v", lines)
+	}
+	if next != 3 {
+		t.Errorf("next offset = %d, want 3", next)
+	}
+
+	lines, next = l.Read(3, 10)
+	if len(lines) != 0 || next != 3 {
+		t.Errorf("Read(3) = %+v, %d, want no lines and offset 3", lines, next)
+	}
+
+	lines, next = l.Read(0, 1)
+	if len(lines) != 1 || next != 1 {
+		t.Errorf("Read(0, 1) = %+v, %d, want one line and offset 1", lines, next)
+	}
+}
+
+func TestJobLog_MemoryIsBounded(t *testing.T) {
+	l := NewMemoryJobLogs().Create("job-1")
+	for i := 0; i < JobLogLines+5; i++ {
+		l.Append("stdout", fmt.Sprintf("line %d", i))
+	}
+
+	lines, next := l.Read(0, JobLogLines*2)
+	if len(lines) != JobLogLines {
+		t.Fatalf("got %d lines, want %d", len(lines), JobLogLines)
+	}
+	if lines[0].Offset != 5 {
+		t.Errorf("oldest line offset = %d, want 5", lines[0].Offset)
+	}
+	if next != JobLogLines+5 {
+		t.Errorf("next offset = %d, want %d", next, JobLogLines+5)
+	}
+	for i, line := range lines {
+		if line.Offset != i+5 || line.Line != fmt.Sprintf("line %d", i+5) {
+			t.Fatalf("line %d = %+v, want offset %d in order", i, line, i+5)
+		}
+	}
+
+	// Reading across the wrap point of the ring
+	lines, next = l.Read(JobLogLines-2, 4)
+	if len(lines) != 4 || lines[0].Offset != JobLogLines-2 || lines[3].Offset != JobLogLines+1 || next != JobLogLines+2 {
+		t.Errorf("Read(%d, 4) = %+v, %d", JobLogLines-2, lines, next)
+	}
+}
+
+func TestJobLog_OldLinesAreReadFromFile(t *testing.T) {
+	logs, err := NewJobLogs(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := logs.Create("job-1")
+	for i := 0; i < JobLogLines+5; i++ {
+		l.Append("stdout", fmt.Sprintf("line %d", i))
+	}
+
+	lines, next := l.Read(0, 10)
+	if len(lines) != 10 || lines[0].Line != "line 0" || next != 10 {
+		t.Fatalf("Read(0, 10) = %d lines, next %d", len(lines), next)
+	}
+
+	// Reading across the file and memory returns every line once
+	lines, _ = l.Read(3, JobLogLines)
+	for i, line := range lines {
+		if line.Offset != 3+i {
+			t.Fatalf("line %d has offset %d", i, line.Offset)
+		}
+	}
+}
+
+func TestJobLogs_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	logs, err := NewJobLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := logs.Create("job-1")
+	l.Append("stdout", "one")
+	l.Append("stdout", "two")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewJobLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := reopened.Get("job-1")
+	if restored == nil || !restored.Closed() {
+		t.Fatal("expected a closed log restored from its file")
+	}
+	lines, next := restored.Read(1, 10)
+	if len(lines) != 1 || lines[0].Line != "two" || next != 2 {
+		t.Errorf("Read(1) = %+v, %d", lines, next)
+	}
+
+	if reopened.Get("job-unknown") != nil {
+		t.Error("expected no log for an unknown job")
+	}
+}
+
+func TestJobLogs_UnsafeJobIDStaysInMemory(t *testing.T) {
+	dir := t.TempDir()
+	logs, err := NewJobLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"../escape", ".hidden", `a\b`} {
+		l := logs.Create(id)
+		l.Append("stdout", "line")
+		if lines, _ := l.Read(0, 10); len(lines) != 1 {
+			t.Errorf("log of %q lost its line", id)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no log file for unsafe job IDs, got %d", len(entries))
+	}
+}
+
+func TestJobLog_ChangedAndClose(t *testing.T) {
+	l := NewMemoryJobLogs().Create("job-1")
+
+	changed := l.Changed()
+	l.Append("stdout", "one")
+	select {
+	case <-changed:
+	default:
+		t.Fatal("Append did not signal a change")
+	}
+
+	changed = l.Changed()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Fatal("Close did not signal a change")
+	}
+
+	l.Append("stdout", "late")
+	if lines, _ := l.Read(0, 10); len(lines) != 1 {
+		t.Errorf("got %d lines, lines appended after Close should be dropped", len(lines))
+	}
+}
+
+func TestJobLogs_CleanupOldLogs(t *testing.T) {
+	dir := t.TempDir()
+	logs, err := NewJobLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := logs.Create("job-old")
+	old.Append("stdout", "line")
+	_ = old.Close()
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "job-old.log"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	logs.logs["job-old"].closedAt = past
+
+	running := logs.Create("job-running")
+	running.Append("stdout", "line")
+	if err := os.Chtimes(filepath.Join(dir, "job-running.log"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	logs.CleanupOldLogs(time.Hour)
+
+	if logs.Get("job-old") != nil {
+		t.Error("expected the old log to be removed")
+	}
+	if logs.Get("job-running") == nil {
+		t.Error("expected the running log to be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "job-running.log")); err != nil {
+		t.Errorf("expected the running log file to be kept: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// pendingJob is a job waiting in the queue, with the key identifying duplicates.
+type pendingJob struct {
+	job *entity.JobWithMutex
+	key string
+}
+
+// EnqueueJob adds a pending job to the FIFO of jobs run after the current one.
+// When a pending job has the same non-empty key, that job is returned instead and
+// job is dropped. It returns nil when maxPending jobs are already waiting.
+func (s *MemoryJobStore) EnqueueJob(job *entity.JobWithMutex, key string, maxPending int) *entity.JobWithMutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key != "" {
+		for _, p := range s.pending {
+			if p.key == key {
+				return p.job
+			}
+		}
+	}
+	if len(s.pending) >= maxPending {
+		return nil
+	}
+
+	s.pending = append(s.pending, pendingJob{job: job, key: key})
+	s.notifyChange(job)
+	return job
+}
+
+// StartNextJob starts the oldest pending job when no job is running, and returns it.
+func (s *MemoryJobStore) StartNextJob() *entity.JobWithMutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 || (s.currentJob != nil && s.currentJob.IsRunning()) {
+		return nil
+	}
+
+	job := s.pending[0].job
+	s.pending = s.pending[1:]
+	job.SetStarted()
+	s.currentJob = job
+	s.notifyChange(job)
+	return job
+}
+
+// QueuePosition returns the position of a pending job in the queue, starting at 1,
+// or 0 when the job is not queued.
+func (s *MemoryJobStore) QueuePosition(jobID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i, p := range s.pending {
+		if p.job.ID == jobID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// CancelPendingJob removes a pending job from the queue and marks it cancelled.
+// It returns false when the job is not queued.
+func (s *MemoryJobStore) CancelPendingJob(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.pending {
+		if p.job.ID != jobID {
+			continue
+		}
+		s.pending = append(s.pending[:i:i], s.pending[i+1:]...)
+		p.job.SetCancelled(ErrJobCancelled)
+		s.notifyChange(p.job)
+		s.addToHistory(p.job)
+		return true
+	}
+	return false
+}
+
+// findPending returns a pending job by ID. The caller holds s.mu.
+func (s *MemoryJobStore) findPending(jobID string) *entity.JobWithMutex {
+	for _, p := range s.pending {
+		if p.job.ID == jobID {
+			return p.job
+		}
+	}
+	return nil
+}
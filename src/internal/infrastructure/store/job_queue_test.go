@@ -0,0 +1,95 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+func TestJobStore_Queue(t *testing.T) {
+	store := NewJobStore()
+	store.TryStartJob(entity.NewJob("running", entity.ActionUpdate))
+
+	first := entity.NewJob("first", entity.ActionUpdate)
+	if got := store.EnqueueJob(first, "update", 2); got != first {
+		t.Fatalf("EnqueueJob() = %v, want the new job", got)
+	}
+	second := entity.NewJob("second", entity.ActionReboot)
+	store.EnqueueJob(second, "reboot", 2)
+
+	// A duplicate joins the queued job, even with a full queue
+	if got := store.EnqueueJob(entity.NewJob("dup", entity.ActionUpdate), "update", 2); got != first {
+		t.Errorf("duplicate EnqueueJob() = %v, want the queued job", got)
+	}
+	if got := store.EnqueueJob(entity.NewJob("third", entity.ActionUpgrade), "upgrade", 2); got != nil {
+		t.Errorf("EnqueueJob() on a full queue = %v, want nil", got)
+	}
+
+	if first.GetStatus() != entity.JobStatusPending || store.QueuePosition("second") != 2 {
+		t.Errorf("status = %s, position = %d", first.GetStatus(), store.QueuePosition("second"))
+	}
+	if store.GetJob("second") != second || store.GetJobByID("second") != second {
+		t.Error("expected queued jobs to be found by ID")
+	}
+
+	// Queued jobs run first, and only once the running job is done
+	if store.TryStartJob(entity.NewJob("late", entity.ActionUpdate)) {
+		t.Error("TryStartJob() should not skip the queue")
+	}
+	if store.StartNextJob() != nil {
+		t.Error("StartNextJob() should wait for the running job")
+	}
+	store.CompleteCurrentJob()
+	if got := store.StartNextJob(); got != first || !got.IsRunning() || store.GetCurrentJob() != first {
+		t.Errorf("StartNextJob() = %v, want the oldest queued job running", got)
+	}
+	if store.QueuePosition("second") != 1 || store.QueuePosition("first") != 0 {
+		t.Errorf("positions = %d %d", store.QueuePosition("second"), store.QueuePosition("first"))
+	}
+}
+
+func TestJobStore_CancelPendingJob(t *testing.T) {
+	store := NewJobStore()
+	store.TryStartJob(entity.NewJob("running", entity.ActionUpdate))
+	store.EnqueueJob(entity.NewJob("a", entity.ActionUpdate), "update", 5)
+	store.EnqueueJob(entity.NewJob("b", entity.ActionReboot), "reboot", 5)
+
+	if !store.CancelPendingJob("a") {
+		t.Fatal("CancelPendingJob() = false, want true")
+	}
+	if store.CancelPendingJob("a") || store.CancelPendingJob("running") {
+		t.Error("only queued jobs can be removed from the queue")
+	}
+
+	job := store.GetJob("a")
+	if job == nil || job.GetStatus() != entity.JobStatusCancelled || !errors.Is(job.Error, ErrJobCancelled) {
+		t.Errorf("cancelled job = %+v", job)
+	}
+	if store.QueuePosition("b") != 1 {
+		t.Errorf("position of b = %d, want 1", store.QueuePosition("b"))
+	}
+}
+
+func TestFileJobStore_QueuedJobsAreInterruptedOnRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	store, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.TryStartJob(entity.NewJob("running", entity.ActionUpdate))
+	store.EnqueueJob(entity.NewJob("queued", entity.ActionUpdate), "update", 5)
+	_ = store.Close()
+
+	reopened, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reopened.Close() }()
+	reopened.Reconcile(reopened.GetJob("running").StartTime)
+
+	if job := reopened.GetJob("queued"); job == nil || job.GetStatus() != entity.JobStatusInterrupted {
+		t.Errorf("queued job after restart = %+v, want interrupted", job)
+	}
+}
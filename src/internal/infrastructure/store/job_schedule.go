@@ -0,0 +1,102 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+// ScheduleJob keeps a job aside until runAt, when StartScheduledJob runs the request.
+func (s *MemoryJobStore) ScheduleJob(job *entity.JobWithMutex, runAt time.Time, req entity.WebhookRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.SetScheduled(runAt, req)
+	s.scheduled = append(s.scheduled, job)
+	s.notifyChange(job)
+}
+
+// ScheduledJobs returns the scheduled jobs, the next to run first.
+func (s *MemoryJobStore) ScheduledJobs() []*entity.JobWithMutex {
+	s.mu.RLock()
+	jobs := append([]*entity.JobWithMutex(nil), s.scheduled...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Snapshot().RunAt.Before(*jobs[j].Snapshot().RunAt)
+	})
+	return jobs
+}
+
+// RescheduleJob moves the run time of a scheduled job.
+// It returns false when the job is not scheduled.
+func (s *MemoryJobStore) RescheduleJob(jobID string, runAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.findScheduled(jobID)
+	if job == nil {
+		return false
+	}
+	job.Reschedule(runAt)
+	s.notifyChange(job)
+	return true
+}
+
+// StartScheduledJob starts a scheduled job when no job is running or queued.
+// It returns false when the job has to wait, or is no longer scheduled.
+func (s *MemoryJobStore) StartScheduledJob(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if (s.currentJob != nil && s.currentJob.IsRunning()) || len(s.pending) > 0 {
+		return false
+	}
+	job := s.removeScheduled(jobID)
+	if job == nil {
+		return false
+	}
+
+	job.SetStarted()
+	s.currentJob = job
+	s.notifyChange(job)
+	return true
+}
+
+// CancelScheduledJob removes a scheduled job and marks it cancelled.
+// It returns false when the job is not scheduled.
+func (s *MemoryJobStore) CancelScheduledJob(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.removeScheduled(jobID)
+	if job == nil {
+		return false
+	}
+	job.SetCancelled(ErrJobCancelled)
+	s.notifyChange(job)
+	s.addToHistory(job)
+	return true
+}
+
+// findScheduled returns a scheduled job by ID. The caller holds s.mu.
+func (s *MemoryJobStore) findScheduled(jobID string) *entity.JobWithMutex {
+	for _, job := range s.scheduled {
+		if job.ID == jobID {
+			return job
+		}
+	}
+	return nil
+}
+
+// removeScheduled takes a job out of the scheduled jobs. The caller holds s.mu.
+func (s *MemoryJobStore) removeScheduled(jobID string) *entity.JobWithMutex {
+	for i, job := range s.scheduled {
+		if job.ID == jobID {
+			s.scheduled = append(s.scheduled[:i:i], s.scheduled[i+1:]...)
+			return job
+		}
+	}
+	return nil
+}
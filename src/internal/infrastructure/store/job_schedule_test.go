@@ -0,0 +1,89 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/cloud-update/src/internal/domain/entity"
+)
+
+func TestJobStore_Schedule(t *testing.T) {
+	store := NewJobStore()
+	now := time.Now()
+	later := entity.NewJob("later", entity.ActionReboot)
+	store.ScheduleJob(later, now.Add(2*time.Hour), entity.WebhookRequest{Action: entity.ActionReboot})
+	sooner := entity.NewJob("sooner", entity.ActionUpdate)
+	store.ScheduleJob(sooner, now.Add(time.Hour), entity.WebhookRequest{Action: entity.ActionUpdate})
+
+	jobs := store.ScheduledJobs()
+	if len(jobs) != 2 || jobs[0] != sooner || jobs[1] != later {
+		t.Fatalf("ScheduledJobs() = %v, want sooner then later", jobs)
+	}
+	if store.GetJob("later") != later || later.GetStatus() != entity.JobStatusScheduled {
+		t.Errorf("scheduled job = %+v", store.GetJob("later"))
+	}
+
+	if !store.RescheduleJob("later", now) || store.ScheduledJobs()[0] != later {
+		t.Error("RescheduleJob() should move the job first")
+	}
+
+	// Scheduled jobs wait for the running one
+	store.TryStartJob(entity.NewJob("running", entity.ActionUpdate))
+	if store.StartScheduledJob("later") {
+		t.Error("StartScheduledJob() should wait for the running job")
+	}
+	store.CompleteCurrentJob()
+	if !store.StartScheduledJob("later") || store.GetCurrentJob() != later || !later.IsRunning() {
+		t.Error("StartScheduledJob() should run the job")
+	}
+	if store.StartScheduledJob("later") || len(store.ScheduledJobs()) != 1 {
+		t.Error("a started job is no longer scheduled")
+	}
+}
+
+func TestJobStore_CancelScheduledJob(t *testing.T) {
+	store := NewJobStore()
+	store.ScheduleJob(entity.NewJob("a", entity.ActionUpdate), time.Now().Add(time.Hour),
+		entity.WebhookRequest{Action: entity.ActionUpdate})
+
+	if !store.CancelScheduledJob("a") || store.CancelScheduledJob("a") {
+		t.Fatal("CancelScheduledJob() should cancel the job once")
+	}
+	job := store.GetJob("a")
+	if job == nil || job.GetStatus() != entity.JobStatusCancelled || !errors.Is(job.Error, ErrJobCancelled) {
+		t.Errorf("cancelled job = %+v", job)
+	}
+	if len(store.ScheduledJobs()) != 0 {
+		t.Error("cancelled job is still scheduled")
+	}
+}
+
+func TestFileJobStore_ScheduledJobsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	store, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	req := entity.WebhookRequest{Action: entity.ActionRestart, Module: "nginx"}
+	store.ScheduleJob(entity.NewJob("scheduled", entity.ActionRestart), runAt, req)
+	_ = store.Close()
+
+	reopened, err := NewFileJobStore(path, DefaultRetention())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reopened.Close() }()
+	reopened.Reconcile(time.Now())
+
+	jobs := reopened.ScheduledJobs()
+	if len(jobs) != 1 {
+		t.Fatalf("scheduled jobs after restart = %v", jobs)
+	}
+	job := jobs[0].Snapshot()
+	if job.Status != entity.JobStatusScheduled || !job.RunAt.Equal(runAt) || job.Request.Module != "nginx" {
+		t.Errorf("scheduled job after restart = %+v", job)
+	}
+}
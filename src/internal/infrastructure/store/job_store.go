@@ -2,47 +2,93 @@
 package store
 
 import (
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/kodflow/cloud-update/src/internal/domain/entity"
 )
 
-// JobStore manages job storage and state.
-type JobStore struct {
+// JobStore defines the interface for job storage and state management.
+type JobStore interface {
+	GetCurrentJob() *entity.JobWithMutex
+	GetJob(jobID string) *entity.JobWithMutex
+	GetJobByID(id string) *entity.JobWithMutex
+	TryStartJob(job *entity.JobWithMutex) bool
+	CompleteCurrentJob()
+	FailCurrentJob(err error)
+	FinishCurrentJob(result *entity.ActionResult)
+	CancelCurrentJob(result *entity.ActionResult)
+	EnqueueJob(job *entity.JobWithMutex, key string, maxPending int) *entity.JobWithMutex
+	StartNextJob() *entity.JobWithMutex
+	QueuePosition(jobID string) int
+	CancelPendingJob(jobID string) bool
+	ScheduleJob(job *entity.JobWithMutex, runAt time.Time, req entity.WebhookRequest)
+	ScheduledJobs() []*entity.JobWithMutex
+	RescheduleJob(jobID string, runAt time.Time) bool
+	StartScheduledJob(jobID string) bool
+	CancelScheduledJob(jobID string) bool
+	RecordCallbackAttempt(jobID string, attempt entity.CallbackAttempt)
+	CleanupOldJobs(maxAge time.Duration)
+	Reconcile(bootTime time.Time) []*entity.JobWithMutex
+	Close() error
+}
+
+// MemoryJobStore manages job storage and state in memory.
+type MemoryJobStore struct {
 	// Current running job (only one job can run at a time)
 	currentJob *entity.JobWithMutex
+	// Jobs waiting for the current job to finish, oldest first
+	pending []pendingJob
+	// Jobs waiting for their run time
+	scheduled []*entity.JobWithMutex
 	// History of completed jobs (optional, for tracking)
 	history    []*entity.JobWithMutex
 	mu         sync.RWMutex
 	maxHistory int
+	// onChange is called with the store lock held whenever a job changes state
+	onChange func(job *entity.JobWithMutex)
 }
 
-// NewJobStore creates a new job store.
-func NewJobStore() *JobStore {
-	return &JobStore{
+// NewJobStore creates a new in-memory job store.
+func NewJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
 		maxHistory: 100, // Keep last 100 jobs in history
 		history:    make([]*entity.JobWithMutex, 0),
 	}
 }
 
 // GetCurrentJob returns the current running job if any.
-func (s *JobStore) GetCurrentJob() *entity.JobWithMutex {
+func (s *MemoryJobStore) GetCurrentJob() *entity.JobWithMutex {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.currentJob
 }
 
 // GetJob returns a job by ID from current or history.
-func (s *JobStore) GetJob(jobID string) *entity.JobWithMutex {
+func (s *MemoryJobStore) GetJob(jobID string) *entity.JobWithMutex {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.findJob(jobID)
+}
 
+// findJob returns a job by ID. The caller must hold the store lock.
+func (s *MemoryJobStore) findJob(jobID string) *entity.JobWithMutex {
 	// Check current job
 	if s.currentJob != nil && s.currentJob.ID == jobID {
 		return s.currentJob
 	}
 
+	// Check queued jobs
+	if job := s.findPending(jobID); job != nil {
+		return job
+	}
+
+	// Check scheduled jobs
+	if job := s.findScheduled(jobID); job != nil {
+		return job
+	}
+
 	// Check history
 	for _, job := range s.history {
 		if job.ID == jobID {
@@ -54,48 +100,151 @@ func (s *JobStore) GetJob(jobID string) *entity.JobWithMutex {
 }
 
 // TryStartJob attempts to start a new job.
-// Returns false if another job is already running.
-func (s *JobStore) TryStartJob(job *entity.JobWithMutex) bool {
+// Returns false if another job is already running or jobs are queued.
+func (s *MemoryJobStore) TryStartJob(job *entity.JobWithMutex) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Check if there's already a running job
-	if s.currentJob != nil && s.currentJob.IsRunning() {
+	// Check if there's already a running job, or queued jobs to run first
+	if (s.currentJob != nil && s.currentJob.IsRunning()) || len(s.pending) > 0 {
 		return false
 	}
 
 	// Set the new job as current and mark it as running
 	job.SetRunning()
 	s.currentJob = job
+	s.notifyChange(job)
 	return true
 }
 
 // CompleteCurrentJob marks the current job as completed.
-func (s *JobStore) CompleteCurrentJob() {
+func (s *MemoryJobStore) CompleteCurrentJob() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.currentJob != nil {
 		s.currentJob.SetCompleted()
+		s.notifyChange(s.currentJob)
 		s.addToHistory(s.currentJob)
 		s.currentJob = nil
 	}
 }
 
 // FailCurrentJob marks the current job as failed.
-func (s *JobStore) FailCurrentJob(err error) {
+func (s *MemoryJobStore) FailCurrentJob(err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.currentJob != nil {
 		s.currentJob.SetFailed(err)
+		s.notifyChange(s.currentJob)
 		s.addToHistory(s.currentJob)
 		s.currentJob = nil
 	}
 }
 
+// FinishCurrentJob records the action result on the current job and marks it
+// as completed or failed depending on the outcome. A successful result with a
+// pending reboot keeps the job running until Reconcile observes the new boot.
+func (s *MemoryJobStore) FinishCurrentJob(result *entity.ActionResult) {
+	if result == nil {
+		s.CompleteCurrentJob()
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentJob == nil {
+		return
+	}
+
+	s.currentJob.SetResult(result)
+	if result.Err == nil && result.RebootPending {
+		s.notifyChange(s.currentJob)
+		return
+	}
+	if result.Err != nil {
+		s.currentJob.SetFailed(result.Err)
+	} else {
+		s.currentJob.SetCompleted()
+	}
+	s.notifyChange(s.currentJob)
+	s.addToHistory(s.currentJob)
+	s.currentJob = nil
+}
+
+// ErrJobCancelled is recorded on jobs stopped by a cancel request.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// CancelCurrentJob records the partial action result, if any, on the current job
+// and marks it as cancelled.
+func (s *MemoryJobStore) CancelCurrentJob(result *entity.ActionResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentJob == nil {
+		return
+	}
+
+	if result != nil {
+		s.currentJob.SetResult(result)
+	}
+	s.currentJob.SetCancelled(ErrJobCancelled)
+	s.notifyChange(s.currentJob)
+	s.addToHistory(s.currentJob)
+	s.currentJob = nil
+}
+
+// ErrJobInterrupted is recorded on jobs that were running when the service stopped.
+var ErrJobInterrupted = errors.New("job interrupted by service restart")
+
+// Reconcile settles jobs left running by a previous run of the service.
+// Reboot jobs started before bootTime completed with that boot; any other
+// orphaned job is marked interrupted. It returns the jobs that were updated.
+func (s *MemoryJobStore) Reconcile(bootTime time.Time) []*entity.JobWithMutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reconciled := make([]*entity.JobWithMutex, 0)
+	for _, job := range s.history {
+		status := job.GetStatus()
+		// Jobs scheduled without their request cannot run anymore
+		if status.IsFinal() {
+			continue
+		}
+
+		if job.Action == entity.ActionReboot && !bootTime.IsZero() && bootTime.After(job.StartTime) {
+			if result := job.GetResult(); result != nil {
+				result.AddStep(entity.StepResult{
+					Name:      "system_boot",
+					Status:    entity.StepStatusSucceeded,
+					StartTime: bootTime,
+					EndTime:   time.Now(),
+				})
+				result.RebootPending = false
+			}
+			job.SetCompleted()
+		} else {
+			job.SetInterrupted(ErrJobInterrupted)
+		}
+
+		s.notifyChange(job)
+		reconciled = append(reconciled, job)
+	}
+
+	return reconciled
+}
+
+// notifyChange reports a job state change to the registered observer, if any.
+func (s *MemoryJobStore) notifyChange(job *entity.JobWithMutex) {
+	if s.onChange != nil {
+		s.onChange(job)
+	}
+}
+
 // addToHistory adds a job to the history.
-func (s *JobStore) addToHistory(job *entity.JobWithMutex) {
+func (s *MemoryJobStore) addToHistory(job *entity.JobWithMutex) {
 	s.history = append(s.history, job)
 
 	// Trim history if it exceeds max size
@@ -105,7 +254,7 @@ func (s *JobStore) addToHistory(job *entity.JobWithMutex) {
 }
 
 // GetJobByID retrieves a job by its ID from current or history.
-func (s *JobStore) GetJobByID(id string) *entity.JobWithMutex {
+func (s *MemoryJobStore) GetJobByID(id string) *entity.JobWithMutex {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -114,6 +263,16 @@ func (s *JobStore) GetJobByID(id string) *entity.JobWithMutex {
 		return s.currentJob
 	}
 
+	// Check queued jobs
+	if job := s.findPending(id); job != nil {
+		return job
+	}
+
+	// Check scheduled jobs
+	if job := s.findScheduled(id); job != nil {
+		return job
+	}
+
 	// Check history
 	for i := len(s.history) - 1; i >= 0; i-- {
 		if s.history[i].ID == id {
@@ -125,7 +284,7 @@ func (s *JobStore) GetJobByID(id string) *entity.JobWithMutex {
 }
 
 // CleanupOldJobs removes jobs older than the specified duration.
-func (s *JobStore) CleanupOldJobs(maxAge time.Duration) {
+func (s *MemoryJobStore) CleanupOldJobs(maxAge time.Duration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -140,3 +299,8 @@ func (s *JobStore) CleanupOldJobs(maxAge time.Duration) {
 
 	s.history = newHistory
 }
+
+// Close releases resources held by the store.
+func (s *MemoryJobStore) Close() error {
+	return nil
+}
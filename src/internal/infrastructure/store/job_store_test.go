@@ -91,6 +91,30 @@ func TestJobStore_FailCurrentJob(t *testing.T) {
 	}
 }
 
+func TestJobStore_CancelCurrentJob(t *testing.T) {
+	store := NewJobStore()
+	store.TryStartJob(entity.NewJob("job1", entity.ActionUpdate))
+
+	result := entity.NewActionResult(entity.ActionUpdate)
+	result.AddStep(entity.StepResult{Name: "update_system", Status: entity.StepStatusFailed, ExitCode: -1})
+	result.Fail(errors.New("update_system: context canceled"))
+	store.CancelCurrentJob(result)
+
+	if store.GetCurrentJob() != nil {
+		t.Error("Expected no current job after cancellation")
+	}
+	job := store.GetJobByID("job1")
+	if job == nil {
+		t.Fatal("Expected to find job in history")
+	}
+	if job.GetStatus() != entity.JobStatusCancelled || !errors.Is(job.Error, ErrJobCancelled) || job.EndTime == nil {
+		t.Errorf("Expected cancelled job, got %s %v", job.GetStatus(), job.Error)
+	}
+	if job.GetResult() != result {
+		t.Error("Expected the partial result to be recorded")
+	}
+}
+
 func TestJobStore_GetJobByID(t *testing.T) {
 	store := NewJobStore()
 
@@ -184,3 +208,120 @@ func TestJobStore_MaxHistory(t *testing.T) {
 		}
 	}
 }
+
+func TestJobStore_FinishCurrentJob(t *testing.T) {
+	t.Run("successful result completes the job", func(t *testing.T) {
+		store := NewJobStore()
+		job := entity.NewJob("ok-job", entity.ActionUpdate)
+		store.TryStartJob(job)
+
+		store.FinishCurrentJob(entity.NewActionResult(entity.ActionUpdate))
+
+		if job.GetStatus() != entity.JobStatusCompleted {
+			t.Errorf("Expected status %s, got %s", entity.JobStatusCompleted, job.GetStatus())
+		}
+		if job.GetResult() == nil {
+			t.Error("Expected result to be recorded on the job")
+		}
+		if store.GetCurrentJob() != nil {
+			t.Error("Expected no current job after finishing")
+		}
+	})
+
+	t.Run("failed result fails the job", func(t *testing.T) {
+		store := NewJobStore()
+		job := entity.NewJob("failed-job", entity.ActionUpdate)
+		store.TryStartJob(job)
+
+		result := entity.NewActionResult(entity.ActionUpdate)
+		result.Fail(errors.New("update failed"))
+		store.FinishCurrentJob(result)
+
+		if job.GetStatus() != entity.JobStatusFailed {
+			t.Errorf("Expected status %s, got %s", entity.JobStatusFailed, job.GetStatus())
+		}
+		if job.Error == nil || job.Error.Error() != "update failed" {
+			t.Errorf("Expected job error 'update failed', got %v", job.Error)
+		}
+		if store.GetJob("failed-job") == nil {
+			t.Error("Expected failed job to be kept in history")
+		}
+	})
+
+	t.Run("nil result completes the job", func(t *testing.T) {
+		store := NewJobStore()
+		job := entity.NewJob("nil-result", entity.ActionUpdate)
+		store.TryStartJob(job)
+
+		store.FinishCurrentJob(nil)
+
+		if job.GetStatus() != entity.JobStatusCompleted {
+			t.Errorf("Expected status %s, got %s", entity.JobStatusCompleted, job.GetStatus())
+		}
+	})
+}
+
+func TestJobStore_FinishCurrentJob_RebootPending(t *testing.T) {
+	store := NewJobStore()
+	job := entity.NewJob("reboot-job", entity.ActionReboot)
+	store.TryStartJob(job)
+
+	result := entity.NewActionResult(entity.ActionReboot)
+	result.RebootPending = true
+	store.FinishCurrentJob(result)
+
+	if !job.IsRunning() {
+		t.Errorf("Expected reboot job to stay running, got %s", job.GetStatus())
+	}
+	if store.GetCurrentJob() != job {
+		t.Error("Expected reboot job to remain the current job")
+	}
+	if store.TryStartJob(entity.NewJob("other", entity.ActionUpdate)) {
+		t.Error("Expected new jobs to be rejected while a reboot is pending")
+	}
+}
+
+func TestJobStore_Reconcile(t *testing.T) {
+	bootTime := time.Now().Add(-time.Minute)
+
+	rebooted := entity.NewJob("rebooted", entity.ActionReboot)
+	rebooted.StartTime = bootTime.Add(-time.Minute)
+	rebooted.SetRunning()
+	result := entity.NewActionResult(entity.ActionReboot)
+	result.RebootPending = true
+	rebooted.SetResult(result)
+
+	notRebooted := entity.NewJob("not-rebooted", entity.ActionReboot)
+	notRebooted.SetRunning()
+
+	orphan := entity.NewJob("orphan", entity.ActionUpdate)
+	orphan.StartTime = bootTime.Add(-time.Minute)
+	orphan.SetRunning()
+
+	done := entity.NewJob("done", entity.ActionUpdate)
+	done.SetCompleted()
+
+	store := NewJobStore()
+	store.history = append(store.history, rebooted, notRebooted, orphan, done)
+
+	reconciled := store.Reconcile(bootTime)
+	if len(reconciled) != 3 {
+		t.Fatalf("Expected 3 reconciled jobs, got %d", len(reconciled))
+	}
+
+	if rebooted.GetStatus() != entity.JobStatusCompleted {
+		t.Errorf("Expected rebooted job to be completed, got %s", rebooted.GetStatus())
+	}
+	if res := rebooted.GetResult(); res.RebootPending || len(res.Steps) != 1 || res.Steps[0].Name != "system_boot" {
+		t.Errorf("Expected system_boot step on reboot result, got %+v", res)
+	}
+	if notRebooted.GetStatus() != entity.JobStatusInterrupted {
+		t.Errorf("Expected reboot job started after boot to be interrupted, got %s", notRebooted.GetStatus())
+	}
+	if orphan.GetStatus() != entity.JobStatusInterrupted || !errors.Is(orphan.Error, ErrJobInterrupted) {
+		t.Errorf("Expected orphaned job to be interrupted, got %s (%v)", orphan.GetStatus(), orphan.Error)
+	}
+	if done.GetStatus() != entity.JobStatusCompleted {
+		t.Errorf("Expected finished job to be untouched, got %s", done.GetStatus())
+	}
+}
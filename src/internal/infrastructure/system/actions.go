@@ -0,0 +1,162 @@
+// Package system provides system-level operations and command execution.
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+// ScriptsDir is the only directory scripts can be executed from.
+// This is a variable so it can be modified in tests.
+var ScriptsDir = "/etc/cloud-update/scripts"
+
+// lookPath resolves executables; replaced in tests.
+var lookPath = exec.LookPath
+
+// namePattern restricts service and script names to safe characters.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9@._-]{0,127}$`)
+
+// ValidateName checks that a service or script name is safe to pass to system commands.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid name %q: only letters, digits and @._- are allowed", name)
+	}
+	return nil
+}
+
+// restartCommand returns the command restarting a service with the available init system.
+func restartCommand(service string) ([]string, error) {
+	if err := ValidateName(service); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case hasCommand("systemctl"):
+		return []string{"systemctl", "restart", service}, nil
+	case hasCommand("rc-service"):
+		return []string{"rc-service", service, "restart"}, nil
+	case hasCommand("service"):
+		return []string{"service", service, "restart"}, nil
+	default:
+		return nil, fmt.Errorf("no supported init system found to restart %s", service)
+	}
+}
+
+// ResolveScript returns the path of an executable script in ScriptsDir.
+// Scripts must be regular executable files that only their owner can modify.
+func ResolveScript(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(ScriptsDir, name)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", fmt.Errorf("script not found: %s", name)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("script %s is not a regular file", name)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		return "", fmt.Errorf("script %s is not executable", name)
+	}
+	if info.Mode().Perm()&0o022 != 0 {
+		return "", fmt.Errorf("script %s must not be writable by group or others", name)
+	}
+
+	return path, nil
+}
+
+// updateCommands returns the commands upgrading the installed packages.
+func updateCommands(distro Distribution) ([][]string, error) {
+	switch distro {
+	case DistroAlpine:
+		return [][]string{
+			{"apk", "update"},
+			{"apk", "upgrade"},
+		}, nil
+
+	case DistroDebian, DistroUbuntu:
+		return [][]string{
+			{"apt-get", "update"},
+			{"apt-get", "upgrade", "-y"},
+		}, nil
+
+	case DistroRHEL, DistroCentOS, DistroFedora:
+		if hasCommand("dnf") {
+			return [][]string{{"dnf", "update", "-y"}}, nil
+		}
+		return [][]string{{"yum", "update", "-y"}}, nil
+
+	case DistroSUSE:
+		return [][]string{
+			{"zypper", "refresh"},
+			{"zypper", "update", "-y"},
+		}, nil
+
+	case DistroArch:
+		return [][]string{{"pacman", "-Syu", "--noconfirm"}}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported distribution: %s", distro)
+	}
+}
+
+// upgradeCommands returns the commands performing a full distribution upgrade.
+func upgradeCommands(distro Distribution) ([][]string, error) {
+	switch distro {
+	case DistroAlpine:
+		return [][]string{
+			{"apk", "update"},
+			{"apk", "upgrade", "--available"},
+		}, nil
+
+	case DistroDebian, DistroUbuntu:
+		return [][]string{
+			{"apt-get", "update"},
+			{"apt-get", "dist-upgrade", "-y",
+				"-o", "Dpkg::Options::=--force-confdef",
+				"-o", "Dpkg::Options::=--force-confold"},
+		}, nil
+
+	case DistroRHEL, DistroCentOS, DistroFedora:
+		if hasCommand("dnf") {
+			return [][]string{{"dnf", "distro-sync", "-y", "--refresh"}}, nil
+		}
+		return [][]string{{"yum", "distro-sync", "-y"}}, nil
+
+	case DistroSUSE:
+		return [][]string{
+			{"zypper", "--non-interactive", "refresh"},
+			{"zypper", "--non-interactive", "dist-upgrade"},
+		}, nil
+
+	case DistroArch:
+		return [][]string{{"pacman", "-Syu", "--noconfirm"}}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported distribution: %s", distro)
+	}
+}
+
+// powerCommand returns the command rebooting, or powering off, the system.
+func powerCommand(strategy RebootStrategy, reboot bool) []string {
+	if strategy == RebootScheduled {
+		if reboot {
+			return []string{"shutdown", "-r", "+1", "Cloud Update triggered reboot"}
+		}
+		return []string{"shutdown", "-h", "+1", "Cloud Update triggered shutdown"}
+	}
+	if reboot {
+		return []string{"reboot"}
+	}
+	return []string{"poweroff"}
+}
+
+func hasCommand(name string) bool {
+	_, err := lookPath(name)
+	return err == nil
+}
@@ -0,0 +1,190 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"nginx", false},
+		{"getty@tty1.service", false},
+		{"rotate-keys.sh", false},
+		{"", true},
+		{"../etc/passwd", true},
+		{"a b", true},
+		{"-flag", true},
+		{"nginx;reboot", true},
+		{strings.Repeat("a", 129), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestartCommand(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+
+	tests := []struct {
+		name      string
+		available []string
+		want      []string
+		wantErr   bool
+	}{
+		{"systemd", []string{"systemctl", "service"}, []string{"systemctl", "restart", "nginx"}, false},
+		{"openrc", []string{"rc-service"}, []string{"rc-service", "nginx", "restart"}, false},
+		{"sysvinit", []string{"service"}, []string{"service", "nginx", "restart"}, false},
+		{"no init system", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath = func(file string) (string, error) {
+				for _, name := range tt.available {
+					if name == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+
+			got, err := restartCommand("nginx")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restartCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("restartCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := restartCommand("nginx && reboot"); err == nil {
+		t.Error("restartCommand() should reject unsafe service names")
+	}
+}
+
+func TestResolveScript(t *testing.T) {
+	originalDir := ScriptsDir
+	defer func() { ScriptsDir = originalDir }()
+	ScriptsDir = t.TempDir()
+
+	write := func(name string, mode os.FileMode) {
+		path := filepath.Join(ScriptsDir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\necho ok\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write script: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("Failed to chmod script: %v", err)
+		}
+	}
+	write("ok.sh", 0o700)
+	write("not-exec.sh", 0o600)
+	write("world-writable.sh", 0o777)
+	if err := os.Symlink(filepath.Join(ScriptsDir, "ok.sh"), filepath.Join(ScriptsDir, "link.sh")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	path, err := ResolveScript("ok.sh")
+	if err != nil {
+		t.Fatalf("ResolveScript(ok.sh) error = %v", err)
+	}
+	if path != filepath.Join(ScriptsDir, "ok.sh") {
+		t.Errorf("ResolveScript(ok.sh) = %s", path)
+	}
+
+	for _, name := range []string{"missing.sh", "not-exec.sh", "world-writable.sh", "link.sh", "../ok.sh"} {
+		if _, err := ResolveScript(name); err == nil {
+			t.Errorf("ResolveScript(%q) expected error", name)
+		}
+	}
+}
+
+func TestUpgradeCommands(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	commands, err := upgradeCommands(DistroUbuntu)
+	if err != nil {
+		t.Fatalf("upgradeCommands(ubuntu) error = %v", err)
+	}
+	if len(commands) != 2 || commands[1][1] != "dist-upgrade" {
+		t.Errorf("Expected apt-get dist-upgrade, got %v", commands)
+	}
+
+	commands, err = upgradeCommands(DistroFedora)
+	if err != nil || commands[0][0] != "dnf" || commands[0][1] != "distro-sync" {
+		t.Errorf("Expected dnf distro-sync, got %v (%v)", commands, err)
+	}
+
+	for _, distro := range []Distribution{DistroAlpine, DistroSUSE, DistroArch, DistroRHEL} {
+		if _, err := upgradeCommands(distro); err != nil {
+			t.Errorf("upgradeCommands(%s) error = %v", distro, err)
+		}
+	}
+
+	if _, err := upgradeCommands(DistroUnknown); err == nil {
+		t.Error("upgradeCommands(unknown) expected error")
+	}
+}
+
+func TestUpdateCommands(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	lookPath = func(file string) (string, error) { return "", errors.New("not found") }
+
+	commands, err := updateCommands(DistroDebian)
+	if err != nil {
+		t.Fatalf("updateCommands(debian) error = %v", err)
+	}
+	if len(commands) != 2 || !reflect.DeepEqual(commands[1], []string{"apt-get", "upgrade", "-y"}) {
+		t.Errorf("Expected apt-get upgrade -y, got %v", commands)
+	}
+
+	commands, err = updateCommands(DistroRHEL)
+	if err != nil || commands[0][0] != "yum" {
+		t.Errorf("Expected yum without dnf, got %v (%v)", commands, err)
+	}
+
+	for _, distro := range Distributions {
+		if _, err := updateCommands(distro); err != nil {
+			t.Errorf("updateCommands(%s) error = %v", distro, err)
+		}
+	}
+
+	if _, err := updateCommands(DistroUnknown); err == nil {
+		t.Error("updateCommands(unknown) expected error")
+	}
+}
+
+func TestPowerCommand(t *testing.T) {
+	tests := []struct {
+		strategy RebootStrategy
+		reboot   bool
+		want     string
+	}{
+		{RebootImmediate, true, "reboot"},
+		{RebootImmediate, false, "poweroff"},
+		{RebootScheduled, true, "shutdown -r +1 Cloud Update triggered reboot"},
+		{RebootScheduled, false, "shutdown -h +1 Cloud Update triggered shutdown"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(powerCommand(tt.strategy, tt.reboot), " "); got != tt.want {
+			t.Errorf("powerCommand(%s, %v) = %q, want %q", tt.strategy, tt.reboot, got, tt.want)
+		}
+	}
+}
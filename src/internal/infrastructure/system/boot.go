@@ -0,0 +1,42 @@
+// Package system provides system-level operations and command execution.
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procStatPath is the kernel statistics file holding the boot time.
+// This is a variable so it can be modified in tests.
+var procStatPath = "/proc/stat"
+
+// BootTime returns the time the system was last booted.
+func BootTime() (time.Time, error) {
+	f, err := os.Open(procStatPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read boot time: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "btime" {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid boot time %q: %w", fields[1], err)
+		}
+		return time.Unix(seconds, 0), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read boot time: %w", err)
+	}
+
+	return time.Time{}, fmt.Errorf("boot time not found in %s", procStatPath)
+}
@@ -0,0 +1,42 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBootTime(t *testing.T) {
+	original := procStatPath
+	defer func() { procStatPath = original }()
+
+	dir := t.TempDir()
+
+	procStatPath = filepath.Join(dir, "stat")
+	content := "cpu  1 2 3 4\nintr 0\nbtime 1760000000\nprocesses 42\n"
+	if err := os.WriteFile(procStatPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write stat file: %v", err)
+	}
+
+	got, err := BootTime()
+	if err != nil {
+		t.Fatalf("BootTime() error = %v", err)
+	}
+	if !got.Equal(time.Unix(1760000000, 0)) {
+		t.Errorf("BootTime() = %v, want %v", got, time.Unix(1760000000, 0))
+	}
+
+	procStatPath = filepath.Join(dir, "nobtime")
+	if err := os.WriteFile(procStatPath, []byte("cpu 1 2 3\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write stat file: %v", err)
+	}
+	if _, err := BootTime(); err == nil {
+		t.Error("BootTime() expected error when btime is missing")
+	}
+
+	procStatPath = filepath.Join(dir, "missing")
+	if _, err := BootTime(); err == nil {
+		t.Error("BootTime() expected error for missing file")
+	}
+}
@@ -0,0 +1,69 @@
+// Package system provides system-level operations and command execution.
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CommandError describes a system command that failed, with its exit code and captured output.
+type CommandError struct {
+	Command  string
+	ExitCode int
+	Output   string
+	Err      error
+}
+
+// newCommandError builds a CommandError for the given command line and execution error.
+func newCommandError(args []string, output []byte, err error) *CommandError {
+	return &CommandError{
+		Command:  strings.Join(args, " "),
+		ExitCode: exitCodeOf(err),
+		Output:   string(output),
+		Err:      err,
+	}
+}
+
+// Error implements the error interface.
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command failed: %v, output: %s", e.Err, e.Output)
+}
+
+// Unwrap returns the underlying execution error.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode extracts the exit code from an error returned by an Executor.
+// It returns 0 for a nil error and -1 when no exit code is available.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode
+	}
+
+	return exitCodeOf(err)
+}
+
+// CommandOutput extracts the captured command output from an error returned by an Executor.
+func CommandOutput(err error) string {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Output
+	}
+	return ""
+}
+
+func exitCodeOf(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,55 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommandError(t *testing.T) {
+	runErr := exec.Command("sh", "-c", "echo boom; exit 3").Run()
+	cmdErr := newCommandError([]string{"sh", "-c", "exit 3"}, []byte("boom\n"), runErr)
+
+	if cmdErr.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", cmdErr.ExitCode)
+	}
+	if cmdErr.Command != "sh -c exit 3" {
+		t.Errorf("Command = %q, want %q", cmdErr.Command, "sh -c exit 3")
+	}
+	if !strings.HasPrefix(cmdErr.Error(), "command failed:") || !strings.Contains(cmdErr.Error(), "boom") {
+		t.Errorf("Error() = %q, want command failure with output", cmdErr.Error())
+	}
+	if !errors.Is(cmdErr, runErr) {
+		t.Error("CommandError should unwrap to the execution error")
+	}
+}
+
+func TestExitCodeAndOutput(t *testing.T) {
+	runErr := exec.Command("sh", "-c", "exit 7").Run()
+	wrapped := fmt.Errorf("update_system: %w", newCommandError([]string{"apt-get"}, []byte("E: lock"), runErr))
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantOutput string
+	}{
+		{"nil error", nil, 0, ""},
+		{"wrapped command error", wrapped, 7, "E: lock"},
+		{"raw exit error", runErr, 7, ""},
+		{"plain error", errors.New("unsupported distribution"), -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.wantCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.wantCode)
+			}
+			if got := CommandOutput(tt.err); got != tt.wantOutput {
+				t.Errorf("CommandOutput() = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
@@ -2,12 +2,9 @@ package system
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
-	"time"
 )
 
 // This file contains targeted tests to achieve 100% coverage of specific missing lines.
@@ -147,7 +144,7 @@ func TestUpdateSystem_DistributionSpecific(t *testing.T) {
 				distro:          tt.distro, //nolint:govet // Field is used in DetectDistribution method
 			}
 
-			err := executor.UpdateSystem()
+			err := executor.UpdateSystem(context.Background(), nil)
 
 			// All will fail in test environment, but this exercises the distribution-specific paths
 			if err == nil {
@@ -168,191 +165,3 @@ type mockExecutorForUpdateSystem struct {
 func (m *mockExecutorForUpdateSystem) DetectDistribution() Distribution {
 	return m.distro
 }
-
-// Test SecureExecutor UpdateSystem paths.
-func TestSecureExecutor_UpdateSystem_DistributionPaths(t *testing.T) {
-	tests := []struct {
-		name   string
-		distro Distribution
-	}{
-		{"Alpine", DistroAlpine},
-		{"Debian", DistroDebian},
-		{"Ubuntu", DistroUbuntu},
-		{"RHEL", DistroRHEL},
-		{"CentOS", DistroCentOS},
-		{"Fedora", DistroFedora},
-		{"Arch", DistroArch},
-		{"SUSE", DistroSUSE},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &mockSecureExecutorForUpdate{
-				privilegeCmd: "",
-				timeout:      1 * time.Second,
-				distro:       tt.distro,
-			}
-
-			err := executor.UpdateSystem()
-
-			// Should fail but exercises the code paths
-			if err != nil {
-				t.Logf("SecureExecutor UpdateSystem for %s failed as expected: %v", tt.distro, err)
-			}
-		})
-	}
-}
-
-// mockSecureExecutorForUpdate helps test SecureExecutor distribution paths.
-type mockSecureExecutorForUpdate struct {
-	privilegeCmd string
-	timeout      time.Duration
-	distro       Distribution
-}
-
-func (m *mockSecureExecutorForUpdate) DetectDistribution() Distribution {
-	return m.distro
-}
-
-func (m *mockSecureExecutorForUpdate) UpdateSystem() error {
-	ctx := context.Background()
-	distro := m.DetectDistribution()
-
-	switch distro {
-	case DistroAlpine:
-		if err := m.runPrivilegedSecure(ctx, "apk", "update"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "apk", "upgrade", "--available")
-
-	case DistroDebian, DistroUbuntu:
-		if err := m.runPrivilegedSecure(ctx, "apt-get", "update"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "apt-get", "upgrade", "-y", "--with-new-pkgs",
-			"-o", "Dpkg::Options::=--force-confdef", "-o", "Dpkg::Options::=--force-confold")
-
-	case DistroRHEL, DistroCentOS, DistroFedora:
-		return m.runPrivilegedSecure(ctx, "dnf", "upgrade", "-y", "--refresh")
-
-	case DistroArch:
-		return m.runPrivilegedSecure(ctx, "pacman", "-Syu", "--noconfirm")
-
-	case DistroSUSE:
-		if err := m.runPrivilegedSecure(ctx, "zypper", "refresh"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "zypper", "update", "-y")
-
-	default:
-		return fmt.Errorf("unsupported distribution: %s", distro)
-	}
-}
-
-func (m *mockSecureExecutorForUpdate) runPrivilegedSecure(ctx context.Context, command string, args ...string) error {
-	// Always fail to simulate test environment
-	return fmt.Errorf("command failed in test environment: %s %v", command, args)
-}
-
-// Test timeout executor success paths that are missing coverage.
-func TestExecutorTimeout_SuccessPaths(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-
-	// Test UpdateSystemWithTimeout with successful mock
-	t.Run("UpdateSystemWithTimeout success path", func(t *testing.T) {
-		successExecutor := &mockTimeoutExecutorSuccess{
-			ExecutorWithTimeout: executor,
-		}
-
-		err := successExecutor.UpdateSystemWithTimeout(context.Background())
-
-		if err != nil {
-			t.Logf("UpdateSystemWithTimeout returned expected error: %v", err)
-		} else {
-			t.Log("UpdateSystemWithTimeout succeeded")
-		}
-	})
-
-	// Test RebootWithDelay success path
-	t.Run("RebootWithDelay success path", func(t *testing.T) {
-		successExecutor := &mockTimeoutExecutorSuccess{
-			ExecutorWithTimeout: executor,
-		}
-
-		err := successExecutor.RebootWithDelay(1 * time.Minute)
-
-		if err != nil {
-			t.Logf("RebootWithDelay returned expected error: %v", err)
-		} else {
-			t.Log("RebootWithDelay succeeded")
-		}
-	})
-}
-
-// mockTimeoutExecutorSuccess simulates successful command execution.
-type mockTimeoutExecutorSuccess struct {
-	*ExecutorWithTimeout
-}
-
-func (m *mockTimeoutExecutorSuccess) runUpdate(ctx context.Context, distro Distribution, timeout time.Duration) error {
-	// Simulate success for runUpdate
-	return nil
-}
-
-func (m *mockTimeoutExecutorSuccess) runUpgrade(ctx context.Context, distro Distribution, timeout time.Duration) error {
-	// Simulate success for runUpgrade
-	return nil
-}
-
-func (m *mockTimeoutExecutorSuccess) UpdateSystemWithTimeout(ctx context.Context) error {
-	distro := m.DetectDistribution()
-	timeout := getTimeoutForDistro(distro)
-
-	// Update package lists - simulate success
-	if err := m.runUpdate(ctx, distro, timeout); err != nil {
-		return err
-	}
-
-	// Upgrade packages - simulate success
-	return m.runUpgrade(ctx, distro, timeout*2)
-}
-
-func (m *mockTimeoutExecutorSuccess) RebootWithDelay(delay time.Duration) error {
-	// Always succeed to test success path
-	return nil
-}
-
-// Test to trigger the timeout path in RebootWithDelay.
-func TestExecutorTimeout_RebootWithDelay_TimeoutPath(t *testing.T) {
-	executor := &timeoutExecutorForReboot{
-		ExecutorWithTimeout: NewExecutorWithTimeout(5 * time.Second),
-	}
-
-	err := executor.RebootWithDelay(1 * time.Minute)
-
-	if err != nil {
-		if strings.Contains(err.Error(), "timed out") {
-			t.Log("RebootWithDelay timed out as expected")
-		} else {
-			t.Logf("RebootWithDelay failed: %v", err)
-		}
-	}
-}
-
-// timeoutExecutorForReboot forces timeout in RebootWithDelay.
-type timeoutExecutorForReboot struct {
-	*ExecutorWithTimeout
-}
-
-func (t *timeoutExecutorForReboot) RebootWithDelay(delay time.Duration) error {
-	// Use a context that times out immediately
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
-	defer cancel()
-
-	// Simulate timeout immediately
-	<-ctx.Done()
-	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("reboot command timed out")
-	}
-	return fmt.Errorf("reboot scheduling failed: %w", ctx.Err())
-}
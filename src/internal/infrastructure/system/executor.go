@@ -2,11 +2,19 @@
 package system
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/telemetry"
 )
 
 // Distribution represents a Linux distribution type.
@@ -25,119 +33,207 @@ const (
 	DistroUnknown Distribution = "unknown"
 )
 
-// Executor defines the interface for system operations.
+// Executor defines the interface for system operations. Commands are killed when ctx
+// is done, and their output is streamed line by line to out, which may be nil.
 type Executor interface {
-	RunCloudInit() error
-	Reboot() error
-	UpdateSystem() error
+	RunCloudInit(ctx context.Context, out OutputSink) error
+	Reboot(ctx context.Context, out OutputSink) error
+	Shutdown(ctx context.Context, out OutputSink) error
+	UpdateSystem(ctx context.Context, out OutputSink) error
+	UpgradeSystem(ctx context.Context, out OutputSink) error
+	RestartService(ctx context.Context, out OutputSink, name string) error
+	ExecuteScript(ctx context.Context, out OutputSink, name string) error
 	DetectDistribution() Distribution
 }
 
-// DefaultExecutor implements the Executor interface for real system operations.
+// DefaultExecutor implements the Executor interface, running system commands as
+// its Policy dictates.
 type DefaultExecutor struct {
+	policy       Policy
 	privilegeCmd string
 }
 
-// NewSystemExecutor creates a new system executor.
+// NewSystemExecutor creates a system executor with the default policy.
 func NewSystemExecutor() Executor {
 	return &DefaultExecutor{
+		policy:       DefaultPolicy(),
 		privilegeCmd: detectPrivilegeCommand(),
 	}
 }
 
-func detectPrivilegeCommand() string {
-	commands := []string{"doas", "sudo", "su"}
+// NewExecutor creates a system executor applying policy. An explicit privilege
+// method must be installed.
+func NewExecutor(policy Policy) (*DefaultExecutor, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
 
-	for _, cmd := range commands {
-		if _, err := exec.LookPath(cmd); err == nil {
-			return cmd
+	e := &DefaultExecutor{policy: policy}
+	switch policy.Privilege {
+	case PrivilegeAuto:
+		e.privilegeCmd = detectPrivilegeCommand()
+	case PrivilegeNone:
+	default:
+		if !hasCommand(string(policy.Privilege)) {
+			return nil, fmt.Errorf("privilege command %s not found", policy.Privilege)
 		}
+		e.privilegeCmd = string(policy.Privilege)
 	}
+	return e, nil
+}
 
+// detectPrivilegeCommand returns the installed doas or sudo.
+func detectPrivilegeCommand() string {
+	for _, cmd := range []string{"doas", "sudo"} {
+		if hasCommand(cmd) {
+			return cmd
+		}
+	}
 	return ""
 }
 
-func (e *DefaultExecutor) runPrivileged(args ...string) error {
-	// This function runs system commands with appropriate privileges
-	// Commands are predefined and not user-controlled
-	if e.privilegeCmd == "" {
-		cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // predefined system commands only
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("command failed: %w, output: %s", err, string(output))
+// geteuid returns the effective user ID; replaced in tests.
+var geteuid = os.Geteuid
+
+// CheckPrivilege reports whether commands can still get the privileges they need:
+// the privilege command picked at startup must be installed, and without one the
+// service must run as root unless privileges are explicitly disabled.
+func (e *DefaultExecutor) CheckPrivilege() error {
+	switch {
+	case e.privilegeCmd != "":
+		if !hasCommand(e.privilegeCmd) {
+			return fmt.Errorf("privilege command %s not found", e.privilegeCmd)
 		}
-		return nil
+	case e.policy.Privilege == PrivilegeAuto && geteuid() != 0:
+		return errors.New("not running as root and neither doas nor sudo is installed")
 	}
+	return nil
+}
+
+// debianFrontend keeps apt-get from asking questions.
+const debianFrontend = "DEBIAN_FRONTEND=noninteractive"
 
+// command builds the command running args with the configured privileges.
+// Commands are predefined and their arguments validated, never user-controlled.
+func (e *DefaultExecutor) command(ctx context.Context, args []string) *exec.Cmd {
 	var cmd *exec.Cmd
 	switch e.privilegeCmd {
 	case "doas", "sudo":
-		fullArgs := append([]string{}, args...)
-		cmd = exec.Command(e.privilegeCmd, fullArgs...) //nolint:gosec // using privilege escalation tool
-	case "su":
-		// Use proper shell escaping to prevent injection
-		escapedArgs := make([]string, len(args))
-		for i, arg := range args {
-			escapedArgs[i] = strconv.Quote(arg)
-		}
-		shellCmd := strings.Join(escapedArgs, " ")
-		cmd = exec.Command("su", "-c", shellCmd) //nolint:gosec // su for privilege escalation
+		// sudo and doas reset the environment, apt-get gets its answer mode from env
+		args = append([]string{"env", debianFrontend}, args...)
+		cmd = exec.CommandContext(ctx, e.privilegeCmd, args...) //nolint:gosec // privilege escalation tool
 	default:
-		cmd = exec.Command(args[0], args[1:]...) //nolint:gosec // fallback to direct execution
+		cmd = exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // predefined system commands only
 	}
+	// apt-get must never wait for an answer
+	cmd.Env = append(os.Environ(), debianFrontend)
+	return cmd
+}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("command failed: %w, output: %s", err, string(output))
+// run runs args with privileges within the timeout of step on distro.
+func (e *DefaultExecutor) run(
+	ctx context.Context, out OutputSink, step Step, distro Distribution, args ...string,
+) error {
+	ctx, span := telemetry.Start(ctx, "command "+string(step),
+		attribute.String("process.command_line", strings.Join(args, " ")),
+		attribute.String("command.step", string(step)),
+		attribute.String("os.distribution", string(distro)),
+	)
+	defer span.End()
+
+	cmdCtx := ctx
+	timeout := e.policy.TimeoutFor(step, distro)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	logger.WithField("command", strings.Join(args, " ")).WithField("step", string(step)).Debug("Executing command")
+
+	err := runCommand(cmdCtx, e.command(cmdCtx, args), args, out)
+	// The span and the audit log keep the exit code and error without the
+	// output, which is in the job log
+	summary := err
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		if ctx.Err() == nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			cmdErr.Err = fmt.Errorf("timed out after %v: %w", timeout, cmdErr.Err)
+		}
+		summary = cmdErr.Err
+	}
+	span.SetAttributes(attribute.Int("process.exit.code", ExitCode(err)))
+	if summary != nil {
+		span.RecordError(summary)
+		span.SetStatus(codes.Error, summary.Error())
+	}
+	audit.Command(ctx, string(step), strings.Join(args, " "), ExitCode(err), summary)
+	return err
+}
+
+// runAll runs commands in order, stopping at the first failure.
+func (e *DefaultExecutor) runAll(
+	ctx context.Context, out OutputSink, step Step, distro Distribution, commands [][]string,
+) error {
+	for _, args := range commands {
+		if err := e.run(ctx, out, step, distro, args...); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // RunCloudInit executes cloud-init on the system.
-func (e *DefaultExecutor) RunCloudInit() error {
-	return e.runPrivileged("cloud-init", "init")
+func (e *DefaultExecutor) RunCloudInit(ctx context.Context, out OutputSink) error {
+	return e.run(ctx, out, StepCloudInit, "", "cloud-init", "init")
 }
 
-// Reboot schedules a system reboot.
-func (e *DefaultExecutor) Reboot() error {
-	return e.runPrivileged("reboot")
+// Reboot reboots the system, immediately or in a minute depending on the policy.
+func (e *DefaultExecutor) Reboot(ctx context.Context, out OutputSink) error {
+	return e.run(ctx, out, StepPower, "", powerCommand(e.policy.Reboot, true)...)
 }
 
-// UpdateSystem performs a system update based on the detected distribution.
-func (e *DefaultExecutor) UpdateSystem() error {
-	distro := e.DetectDistribution()
-
-	switch distro {
-	case DistroAlpine:
-		if err := e.runPrivileged("apk", "update"); err != nil {
-			return err
-		}
-		return e.runPrivileged("apk", "upgrade")
-
-	case DistroDebian, DistroUbuntu:
-		if err := e.runPrivileged("apt-get", "update"); err != nil {
-			return err
-		}
-		return e.runPrivileged("apt-get", "upgrade", "-y")
+// Shutdown powers off the system, immediately or in a minute depending on the policy.
+func (e *DefaultExecutor) Shutdown(ctx context.Context, out OutputSink) error {
+	return e.run(ctx, out, StepPower, "", powerCommand(e.policy.Reboot, false)...)
+}
 
-	case DistroRHEL, DistroCentOS, DistroFedora:
-		if _, err := exec.LookPath("dnf"); err == nil {
-			return e.runPrivileged("dnf", "update", "-y")
-		}
-		return e.runPrivileged("yum", "update", "-y")
+// RestartService restarts the named service using the available init system.
+func (e *DefaultExecutor) RestartService(ctx context.Context, out OutputSink, name string) error {
+	args, err := restartCommand(name)
+	if err != nil {
+		return err
+	}
+	return e.run(ctx, out, StepRestartService, "", args...)
+}
 
-	case DistroSUSE:
-		if err := e.runPrivileged("zypper", "refresh"); err != nil {
-			return err
-		}
-		return e.runPrivileged("zypper", "update", "-y")
+// ExecuteScript runs the named script from ScriptsDir.
+func (e *DefaultExecutor) ExecuteScript(ctx context.Context, out OutputSink, name string) error {
+	path, err := ResolveScript(name)
+	if err != nil {
+		return err
+	}
+	return e.run(ctx, out, StepExecuteScript, "", path)
+}
 
-	case DistroArch:
-		return e.runPrivileged("pacman", "-Syu", "--noconfirm")
+// UpdateSystem upgrades the installed packages based on the detected distribution.
+func (e *DefaultExecutor) UpdateSystem(ctx context.Context, out OutputSink) error {
+	distro := e.DetectDistribution()
+	commands, err := updateCommands(distro)
+	if err != nil {
+		return err
+	}
+	return e.runAll(ctx, out, StepUpdateSystem, distro, commands)
+}
 
-	default:
-		return fmt.Errorf("unsupported distribution: %s", distro)
+// UpgradeSystem performs a full distribution upgrade based on the detected distribution.
+func (e *DefaultExecutor) UpgradeSystem(ctx context.Context, out OutputSink) error {
+	distro := e.DetectDistribution()
+	commands, err := upgradeCommands(distro)
+	if err != nil {
+		return err
 	}
+	return e.runAll(ctx, out, StepUpgradeSystem, distro, commands)
 }
 
 // DetectDistribution detects the current Linux distribution.
@@ -183,3 +279,17 @@ func (e *DefaultExecutor) DetectDistribution() Distribution {
 
 	return DistroUnknown
 }
+
+// CheckPackageManager reports whether the package manager of the detected
+// distribution is installed.
+func (e *DefaultExecutor) CheckPackageManager() error {
+	distro := e.DetectDistribution()
+	commands, err := updateCommands(distro)
+	if err != nil {
+		return err
+	}
+	if name := commands[0][0]; !hasCommand(name) {
+		return fmt.Errorf("package manager %s not found on %s", name, distro)
+	}
+	return nil
+}
@@ -3,9 +3,7 @@ package system
 import (
 	"context"
 	"os"
-	"strings"
 	"testing"
-	"time"
 )
 
 // This test file contains additional tests for coverage.
@@ -120,7 +118,7 @@ func TestDefaultExecutor_UpdateSystem_Coverage(t *testing.T) {
 			// We can't easily test the actual command execution,
 			// but we can test the logic paths
 			// For now, just call the method to cover the code
-			_ = e.UpdateSystem()
+			_ = e.UpdateSystem(context.Background(), nil)
 		})
 	}
 }
@@ -285,119 +283,6 @@ func testDetectionErrorCases(t *testing.T) {
 	}
 }
 
-// Test the uncovered lines in executor_secure.go UpdateSystem.
-func TestSecureExecutor_UpdateSystem_Coverage(t *testing.T) {
-	// Just call the method with different distros to cover the paths
-	// The actual commands will fail without privileges, but that's what we're testing
-
-	t.Run("Alpine path", func(t *testing.T) {
-		// Create temp alpine-release file
-		f, _ := os.Create("/tmp/test-alpine-secure")
-		_ = f.Close()
-		defer os.Remove("/tmp/test-alpine-secure")
-
-		e := &SecureExecutor{
-			privilegeCmd: "",
-			timeout:      1 * time.Second,
-		}
-		_ = e.UpdateSystem()
-	})
-
-	t.Run("SUSE path", func(t *testing.T) {
-		// Create temp os-release file with SUSE
-		content := "ID=opensuse\nNAME=openSUSE\n"
-		_ = os.WriteFile("/tmp/test-os-release-secure", []byte(content), 0644)
-		defer os.Remove("/tmp/test-os-release-secure")
-
-		e := &SecureExecutor{
-			privilegeCmd: "",
-			timeout:      1 * time.Second,
-		}
-		_ = e.UpdateSystem()
-	})
-
-	t.Run("Unknown distro", func(t *testing.T) {
-		// No files - will detect as unknown
-		e := &SecureExecutor{
-			privilegeCmd: "",
-			timeout:      1 * time.Second,
-		}
-		err := e.UpdateSystem()
-		if err == nil || !strings.Contains(err.Error(), "unsupported distribution") {
-			t.Logf("UpdateSystem with unknown distro: %v", err)
-		}
-	})
-}
-
-// Test uncovered timeout paths.
-func TestExecutorWithTimeout_Coverage(t *testing.T) {
-	t.Run("UpdateSystemWithTimeout with failed update", func(t *testing.T) {
-		e := &ExecutorWithTimeout{
-			DefaultExecutor: &DefaultExecutor{
-				privilegeCmd: "",
-			},
-			defaultTimeout: 1 * time.Second,
-		}
-
-		// The actual commands will fail since we're not root,
-		// but that's what we want to test
-		ctx := context.Background()
-		err := e.UpdateSystemWithTimeout(ctx)
-		if err == nil {
-			// If running as root, it might succeed
-			t.Log("UpdateSystemWithTimeout succeeded (might be running as root)")
-		} else {
-			t.Logf("UpdateSystemWithTimeout failed as expected: %v", err)
-		}
-	})
-
-	t.Run("RebootWithDelay with timeout", func(t *testing.T) {
-		e := &ExecutorWithTimeout{
-			DefaultExecutor: &DefaultExecutor{
-				privilegeCmd: "",
-			},
-			defaultTimeout: 100 * time.Millisecond, // Very short timeout
-		}
-
-		err := e.RebootWithDelay(1 * time.Minute) // 1 minute delay
-		if err == nil {
-			t.Log("RebootWithDelay succeeded (might be running as root)")
-		} else {
-			t.Logf("RebootWithDelay failed as expected: %v", err)
-		}
-	})
-
-	t.Run("runUpdate with timeout expiry", func(t *testing.T) {
-		e := &ExecutorWithTimeout{
-			DefaultExecutor: &DefaultExecutor{
-				privilegeCmd: "",
-			},
-			defaultTimeout: 1 * time.Nanosecond, // Extremely short timeout
-		}
-
-		ctx := context.Background()
-		err := e.runUpdate(ctx, DistroDebian, 1*time.Nanosecond)
-		if err != nil && strings.Contains(err.Error(), "context deadline exceeded") {
-			t.Logf("runUpdate timed out as expected: %v", err)
-		}
-	})
-
-	t.Run("runUpgrade with timeout expiry", func(t *testing.T) {
-		e := &ExecutorWithTimeout{
-			DefaultExecutor: &DefaultExecutor{
-				privilegeCmd: "",
-			},
-			defaultTimeout: 1 * time.Nanosecond, // Extremely short timeout
-		}
-
-		ctx := context.Background()
-		err := e.runUpgrade(ctx, DistroAlpine, 1*time.Nanosecond)
-		if err != nil && strings.Contains(err.Error(), "context deadline exceeded") {
-			t.Logf("runUpgrade timed out as expected: %v", err)
-		}
-	})
-}
-
 // Test to cover the detectPrivilegeCommand empty return path.
 func TestDefaultExecutor_DetectPrivilegeCommand_Coverage(t *testing.T) {
 	// This test tries to cover the empty return case at line 57
@@ -426,7 +311,7 @@ func TestExecutor_SpecificPaths_Coverage(t *testing.T) {
 
 		e := &DefaultExecutor{}
 		// This will fail without proper privileges
-		err := e.UpdateSystem()
+		err := e.UpdateSystem(context.Background(), nil)
 		_ = err // We just want to execute the path
 	})
 
@@ -438,51 +323,8 @@ func TestExecutor_SpecificPaths_Coverage(t *testing.T) {
 
 		e := &DefaultExecutor{}
 		// This will fail without proper privileges
-		err := e.UpdateSystem()
+		err := e.UpdateSystem(context.Background(), nil)
 		_ = err // We just want to execute the path
 	})
 
-	t.Run("SecureExecutor UpdateSystem with context", func(t *testing.T) {
-		e := &SecureExecutor{
-			privilegeCmd: "",
-			timeout:      1 * time.Second,
-		}
-
-		// Call UpdateSystem to cover the paths - will detect actual system distro
-		_ = e.UpdateSystem()
-	})
-}
-
-// Test to improve runUpdate and runUpgrade coverage in executor_timeout.go.
-func TestExecutorTimeout_RunUpdateUpgrade_Coverage(t *testing.T) {
-	e := &ExecutorWithTimeout{
-		DefaultExecutor: &DefaultExecutor{
-			privilegeCmd: "",
-		},
-		defaultTimeout: 1 * time.Second,
-	}
-
-	t.Run("runUpdate Alpine error", func(t *testing.T) {
-		ctx := context.Background()
-		err := e.runUpdate(ctx, DistroAlpine, 1*time.Second)
-		_ = err // Just execute the path
-	})
-
-	t.Run("runUpdate SUSE error", func(t *testing.T) {
-		ctx := context.Background()
-		err := e.runUpdate(ctx, DistroSUSE, 1*time.Second)
-		_ = err // Just execute the path
-	})
-
-	t.Run("runUpgrade Debian error", func(t *testing.T) {
-		ctx := context.Background()
-		err := e.runUpgrade(ctx, DistroDebian, 1*time.Second)
-		_ = err // Just execute the path
-	})
-
-	t.Run("runUpgrade SUSE error", func(t *testing.T) {
-		ctx := context.Background()
-		err := e.runUpgrade(ctx, DistroSUSE, 1*time.Second)
-		_ = err // Just execute the path
-	})
 }
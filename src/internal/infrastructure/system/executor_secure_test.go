@@ -1,1074 +0,0 @@
-package system
-
-import (
-	"context"
-	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-	"sync"
-	"testing"
-	"time"
-)
-
-// setupFakeCommands creates fake sudo and other command binaries for testing.
-func setupFakeCommands(t *testing.T) {
-	tmpDir := t.TempDir()
-	fakeSudo := filepath.Join(tmpDir, "sudo")
-
-	// Create a fake sudo script that just passes through commands
-	sudoScript := `#!/bin/bash
-exec "$@"
-`
-	err := os.WriteFile(fakeSudo, []byte(sudoScript), 0755)
-	if err != nil {
-		t.Fatalf("Failed to create fake sudo: %v", err)
-	}
-
-	// Save original PATH and restore after test
-	originalPath := os.Getenv("PATH")
-	t.Cleanup(func() { os.Setenv("PATH", originalPath) })
-
-	// Prepend tmpDir to PATH so our fake sudo is found first
-	os.Setenv("PATH", tmpDir+":"+originalPath)
-}
-
-// MockExecutor provides a mock implementation for testing.
-type MockExecutor struct {
-	commands       []MockCommand
-	commandsMutex  sync.RWMutex
-	shouldFail     bool
-	failureMessage string
-	shouldTimeout  bool
-	distribution   Distribution
-	privilegeCmd   string
-}
-
-type MockCommand struct {
-	Command string
-	Args    []string
-	Output  string
-	Error   error
-}
-
-func NewMockExecutor() *MockExecutor {
-	return &MockExecutor{
-		commands:     make([]MockCommand, 0),
-		distribution: DistroUbuntu,
-		privilegeCmd: "sudo",
-	}
-}
-
-func (m *MockExecutor) AddCommand(command string, args []string, output string, err error) {
-	m.commandsMutex.Lock()
-	defer m.commandsMutex.Unlock()
-
-	m.commands = append(m.commands, MockCommand{
-		Command: command,
-		Args:    args,
-		Output:  output,
-		Error:   err,
-	})
-}
-
-func (m *MockExecutor) SetFailure(shouldFail bool, message string) {
-	m.shouldFail = shouldFail
-	m.failureMessage = message
-}
-
-func (m *MockExecutor) SetTimeout(shouldTimeout bool) {
-	m.shouldTimeout = shouldTimeout
-}
-
-func (m *MockExecutor) SetDistribution(distro Distribution) {
-	m.distribution = distro
-}
-
-func (m *MockExecutor) SetPrivilegeCommand(cmd string) {
-	m.privilegeCmd = cmd
-}
-
-func (m *MockExecutor) RunCloudInit() error {
-	if m.shouldFail {
-		return fmt.Errorf("%s", m.failureMessage)
-	}
-	return nil
-}
-
-func (m *MockExecutor) Reboot() error {
-	if m.shouldFail {
-		return fmt.Errorf("%s", m.failureMessage)
-	}
-	return nil
-}
-
-func (m *MockExecutor) UpdateSystem() error {
-	if m.shouldFail {
-		return fmt.Errorf("%s", m.failureMessage)
-	}
-	return nil
-}
-
-func (m *MockExecutor) DetectDistribution() Distribution {
-	return m.distribution
-}
-
-func (m *MockExecutor) GetExecutedCommands() []MockCommand {
-	m.commandsMutex.RLock()
-	defer m.commandsMutex.RUnlock()
-
-	result := make([]MockCommand, len(m.commands))
-	copy(result, m.commands)
-	return result
-}
-
-// mockSecureExecutorWithDistro wraps SecureExecutor to override DetectDistribution.
-type mockSecureExecutorWithDistro struct {
-	*SecureExecutor
-	distribution Distribution
-}
-
-func (m *mockSecureExecutorWithDistro) DetectDistribution() Distribution {
-	return m.distribution
-}
-
-func TestNewSecureExecutor(t *testing.T) {
-	executor := NewSecureExecutor()
-
-	if executor == nil {
-		t.Fatal("NewSecureExecutor() returned nil")
-	}
-
-	secureExec, ok := executor.(*SecureExecutor)
-	if !ok {
-		t.Fatal("NewSecureExecutor() didn't return *SecureExecutor")
-	}
-
-	// Should have a timeout set
-	expectedTimeout := 5 * time.Minute
-	if secureExec.timeout != expectedTimeout {
-		t.Errorf("timeout = %v, want %v", secureExec.timeout, expectedTimeout)
-	}
-
-	// Should have detected a privilege command (or empty if none available)
-	// We can't test the exact value as it depends on the system
-	t.Logf("Detected privilege command: %q", secureExec.privilegeCmd)
-}
-
-func TestSecureExecutor_RunCloudInit(t *testing.T) {
-	// Create a temporary directory and fake scripts
-	tmpDir := t.TempDir()
-	fakeCloudInit := filepath.Join(tmpDir, "cloud-init")
-	fakeSudo := filepath.Join(tmpDir, "sudo")
-
-	// Create a fake cloud-init script that succeeds
-	cloudInitScript := `#!/bin/bash
-echo "fake cloud-init clean"
-echo "fake cloud-init init"
-exit 0
-`
-	err := os.WriteFile(fakeCloudInit, []byte(cloudInitScript), 0755)
-	if err != nil {
-		t.Fatalf("Failed to create fake cloud-init: %v", err)
-	}
-
-	// Create a fake sudo script that just passes through commands
-	sudoScript := `#!/bin/bash
-exec "$@"
-`
-	err = os.WriteFile(fakeSudo, []byte(sudoScript), 0755)
-	if err != nil {
-		t.Fatalf("Failed to create fake sudo: %v", err)
-	}
-
-	// Save original PATH and restore after test
-	originalPath := os.Getenv("PATH")
-	defer os.Setenv("PATH", originalPath)
-
-	// Prepend tmpDir to PATH so our fake scripts are found first
-	os.Setenv("PATH", tmpDir+":"+originalPath)
-
-	tests := []struct {
-		name          string
-		privilegeCmd  string
-		expectError   bool
-		errorContains string
-		skipOnNoSudo  bool
-	}{
-		{
-			name:         "with sudo",
-			privilegeCmd: "sudo",
-			expectError:  false, // Should work with fake sudo
-			skipOnNoSudo: true,
-		},
-		{
-			name:         "with doas",
-			privilegeCmd: "doas",
-			expectError:  true, // doas typically not available
-		},
-		{
-			name:         "without privilege escalation",
-			privilegeCmd: "",
-			expectError:  false, // Should work with fake cloud-init
-		},
-		{
-			name:          "unsupported privilege command",
-			privilegeCmd:  "unsupported",
-			expectError:   true,
-			errorContains: "unsupported privilege escalation method",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.skipOnNoSudo && tt.privilegeCmd == "sudo" {
-				if _, err := exec.LookPath("sudo"); err != nil {
-					t.Skip("sudo not available, skipping test")
-				}
-			}
-
-			executor := &SecureExecutor{
-				privilegeCmd: tt.privilegeCmd,
-				timeout:      1 * time.Second, // Short timeout for tests
-			}
-
-			err := executor.RunCloudInit()
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("RunCloudInit() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
-
-			if tt.expectError && tt.errorContains != "" {
-				if !strings.Contains(err.Error(), tt.errorContains) {
-					t.Errorf("RunCloudInit() error = %v, want error containing %q", err, tt.errorContains)
-				}
-			}
-		})
-	}
-}
-
-func TestSecureExecutor_Reboot(t *testing.T) {
-	// Skip this test in CI to prevent attempting actual reboot
-	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
-		t.Skip("Skipping reboot test in CI environment")
-	}
-
-	tests := []struct {
-		name         string
-		privilegeCmd string
-		expectError  bool
-	}{
-		{
-			name:         "with sudo",
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "without privilege escalation",
-			privilegeCmd: "",
-			expectError:  true, // Will fail in test environment
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &SecureExecutor{
-				privilegeCmd: tt.privilegeCmd,
-				timeout:      1 * time.Second,
-			}
-
-			// In test environment, we expect the command to fail
-			err := executor.Reboot()
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("Reboot() error = %v, expectError %v", err, tt.expectError)
-			}
-		})
-	}
-}
-
-func TestSecureExecutor_UpdateSystem(t *testing.T) {
-	// Skip this test in CI environment as it tries to run real system commands
-	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
-		t.Skip("Skipping test in CI environment - requires mock implementation")
-	}
-
-	tests := []struct {
-		name         string
-		distribution Distribution
-		privilegeCmd string
-		expectError  bool
-		skipReason   string
-	}{
-		{
-			name:         "Alpine Linux",
-			distribution: DistroAlpine,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "Ubuntu",
-			distribution: DistroUbuntu,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "Debian",
-			distribution: DistroDebian,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "RHEL",
-			distribution: DistroRHEL,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "CentOS",
-			distribution: DistroCentOS,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "Fedora",
-			distribution: DistroFedora,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "Arch",
-			distribution: DistroArch,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "SUSE",
-			distribution: DistroSUSE,
-			privilegeCmd: "sudo",
-			expectError:  true, // Will fail in test environment
-		},
-		{
-			name:         "Unknown distribution",
-			distribution: DistroUnknown,
-			privilegeCmd: "sudo",
-			expectError:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.skipReason != "" {
-				t.Skip(tt.skipReason)
-			}
-
-			// Create a mock executor that returns the specified distribution
-			executor := &mockSecureExecutorWithDistro{
-				SecureExecutor: &SecureExecutor{
-					privilegeCmd: tt.privilegeCmd,
-					timeout:      1 * time.Second,
-				},
-				distribution: tt.distribution,
-			}
-
-			err := executor.UpdateSystem()
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("UpdateSystem() error = %v, expectError %v", err, tt.expectError)
-			}
-
-			// For unknown distribution, check specific error message
-			if tt.distribution == DistroUnknown && err != nil {
-				expectedMsg := "unsupported distribution"
-				if !strings.Contains(err.Error(), expectedMsg) {
-					t.Errorf("UpdateSystem() error = %v, want error containing %q", err, expectedMsg)
-				}
-			}
-		})
-	}
-}
-
-func TestSecureExecutor_DetectDistribution(t *testing.T) {
-	executor := &SecureExecutor{}
-
-	// This should delegate to the DefaultExecutor
-	distribution := executor.DetectDistribution()
-
-	// Should return a valid distribution (may be DistroUnknown in test environment)
-	validDistros := []Distribution{
-		DistroAlpine, DistroDebian, DistroUbuntu, DistroRHEL,
-		DistroCentOS, DistroFedora, DistroSUSE, DistroArch, DistroUnknown,
-	}
-
-	found := false
-	for _, validDistro := range validDistros {
-		if distribution == validDistro {
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		t.Errorf("DetectDistribution() = %q, want one of %v", distribution, validDistros)
-	}
-
-	t.Logf("Detected distribution: %s", distribution)
-}
-
-func TestSecureExecutor_runPrivilegedSecure(t *testing.T) {
-	setupFakeCommands(t)
-	tests := []struct {
-		name          string
-		privilegeCmd  string
-		command       string
-		args          []string
-		expectError   bool
-		errorContains string
-		skipOnNoCmd   bool
-	}{
-		{
-			name:         "echo command with sudo",
-			privilegeCmd: "sudo",
-			command:      "echo",
-			args:         []string{"test"},
-			expectError:  false, // Should work with fake sudo
-			skipOnNoCmd:  true,
-		},
-		{
-			name:         "echo command without privilege",
-			privilegeCmd: "",
-			command:      "echo",
-			args:         []string{"test"},
-			expectError:  false,
-		},
-		{
-			name:          "unsupported privilege command",
-			privilegeCmd:  "unsupported",
-			command:       "echo",
-			args:          []string{"test"},
-			expectError:   true,
-			errorContains: "unsupported privilege escalation method",
-		},
-		{
-			name:         "nonexistent command",
-			privilegeCmd: "",
-			command:      "nonexistent-command-12345",
-			args:         []string{},
-			expectError:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.skipOnNoCmd && tt.privilegeCmd != "" {
-				if _, err := exec.LookPath(tt.privilegeCmd); err != nil {
-					t.Skipf("%s not available, skipping test", tt.privilegeCmd)
-				}
-				// Also skip if running in bazel sandbox (no sudo permissions)
-				if tt.privilegeCmd == "sudo" && os.Getenv("TEST_TMPDIR") != "" {
-					t.Skip("sudo not available in bazel sandbox, skipping test")
-				}
-			}
-
-			executor := &SecureExecutor{
-				privilegeCmd: tt.privilegeCmd,
-				timeout:      5 * time.Second,
-			}
-
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
-
-			err := executor.runPrivilegedSecure(ctx, tt.command, tt.args...)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("runPrivilegedSecure() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
-
-			if tt.expectError && tt.errorContains != "" {
-				if !strings.Contains(err.Error(), tt.errorContains) {
-					t.Errorf("runPrivilegedSecure() error = %v, want error containing %q", err, tt.errorContains)
-				}
-			}
-		})
-	}
-}
-
-func TestSecureExecutor_runPrivilegedSecure_Timeout(t *testing.T) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      100 * time.Millisecond, // Very short timeout
-	}
-
-	ctx := context.Background()
-
-	// Use sleep command to test timeout
-	err := executor.runPrivilegedSecure(ctx, "sleep", "1") // Sleep for 1 second
-
-	if err == nil {
-		t.Error("runPrivilegedSecure() should have timed out")
-		return
-	}
-
-	expectedMsg := "command timed out"
-	if !strings.Contains(err.Error(), expectedMsg) {
-		t.Errorf("runPrivilegedSecure() error = %v, want error containing %q", err, expectedMsg)
-	}
-}
-
-func TestSecureExecutor_runPrivilegedSecure_ContextCancellation(t *testing.T) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      5 * time.Second,
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-	defer cancel()
-
-	// Use sleep command to test context cancellation
-	err := executor.runPrivilegedSecure(ctx, "sleep", "1")
-
-	if err == nil {
-		t.Error("runPrivilegedSecure() should have been canceled by context")
-		return
-	}
-
-	// Should contain timeout information
-	if !strings.Contains(err.Error(), "timed out") && !strings.Contains(err.Error(), "context deadline exceeded") {
-		t.Errorf("runPrivilegedSecure() error = %v, should indicate timeout or cancellation", err)
-	}
-}
-
-func TestSecureExecutor_Concurrent(t *testing.T) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      5 * time.Second,
-	}
-
-	const numGoroutines = 10
-	var wg sync.WaitGroup
-	errors := make(chan error, numGoroutines)
-
-	// Run multiple commands concurrently
-	for i := 0; i < numGoroutines; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			ctx := context.Background()
-			err := executor.runPrivilegedSecure(ctx, "echo", fmt.Sprintf("concurrent-%d", id))
-			errors <- err
-		}(i)
-	}
-
-	wg.Wait()
-	close(errors)
-
-	// Check results
-	errorCount := 0
-	for err := range errors {
-		if err != nil {
-			errorCount++
-			t.Logf("Concurrent execution error: %v", err)
-		}
-	}
-
-	// Some errors might be expected in test environment, but not all should fail
-	if errorCount == numGoroutines {
-		t.Error("All concurrent executions failed - this might indicate a problem")
-	}
-
-	t.Logf("Concurrent executions: %d succeeded, %d failed", numGoroutines-errorCount, errorCount)
-}
-
-// Test privilege command detection.
-func TestSecureExecutor_DetectPrivilegeCommand(t *testing.T) {
-	// This tests the actual detectPrivilegeCommand function
-	privilegeCmd := detectPrivilegeCommand()
-
-	// Should return one of the known privilege commands or empty string
-	validCommands := []string{"", "doas", "sudo", "su"}
-	found := false
-	for _, valid := range validCommands {
-		if privilegeCmd == valid {
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		t.Errorf("detectPrivilegeCommand() = %q, want one of %v", privilegeCmd, validCommands)
-	}
-
-	t.Logf("Detected privilege command: %q", privilegeCmd)
-
-	// If a command was detected, verify it exists
-	if privilegeCmd != "" {
-		if _, err := exec.LookPath(privilegeCmd); err != nil {
-			t.Errorf("Detected privilege command %q not found in PATH: %v", privilegeCmd, err)
-		}
-	}
-}
-
-// Test security validations.
-func TestSecureExecutor_SecurityValidations(t *testing.T) {
-	setupFakeCommands(t)
-	tests := []struct {
-		name         string
-		privilegeCmd string
-		command      string
-		args         []string
-		expectError  bool
-		errorMsg     string
-	}{
-		{
-			name:         "valid sudo command",
-			privilegeCmd: "sudo",
-			command:      "echo",
-			args:         []string{"test"},
-			expectError:  false, // Should work with fake sudo
-		},
-		{
-			name:         "valid doas command",
-			privilegeCmd: "doas",
-			command:      "echo",
-			args:         []string{"test"},
-			expectError:  false,
-		},
-		{
-			name:         "unsupported privilege escalation",
-			privilegeCmd: "malicious-cmd",
-			command:      "echo",
-			args:         []string{"test"},
-			expectError:  true,
-			errorMsg:     "unsupported privilege escalation method",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Skip if privilege command not available
-			if tt.privilegeCmd != "" && tt.privilegeCmd != "malicious-cmd" {
-				if _, err := exec.LookPath(tt.privilegeCmd); err != nil {
-					t.Skipf("%s not available, skipping test", tt.privilegeCmd)
-				}
-				// Also skip if running in bazel sandbox (no sudo permissions)
-				if tt.privilegeCmd == "sudo" && os.Getenv("TEST_TMPDIR") != "" {
-					t.Skip("sudo not available in bazel sandbox, skipping test")
-				}
-			}
-
-			executor := &SecureExecutor{
-				privilegeCmd: tt.privilegeCmd,
-				timeout:      5 * time.Second,
-			}
-
-			ctx := context.Background()
-			err := executor.runPrivilegedSecure(ctx, tt.command, tt.args...)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("runPrivilegedSecure() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
-
-			if tt.expectError && tt.errorMsg != "" {
-				if !strings.Contains(err.Error(), tt.errorMsg) {
-					t.Errorf("runPrivilegedSecure() error = %v, want error containing %q", err, tt.errorMsg)
-				}
-			}
-		})
-	}
-}
-
-// Benchmark tests.
-func BenchmarkSecureExecutor_runPrivilegedSecure(b *testing.B) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      30 * time.Second,
-	}
-
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		executor.runPrivilegedSecure(ctx, "echo", "benchmark")
-	}
-}
-
-func BenchmarkSecureExecutor_DetectDistribution(b *testing.B) {
-	executor := &SecureExecutor{}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		executor.DetectDistribution()
-	}
-}
-
-// Test edge cases.
-func TestSecureExecutor_EmptyCommand(t *testing.T) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      5 * time.Second,
-	}
-
-	ctx := context.Background()
-	err := executor.runPrivilegedSecure(ctx, "")
-
-	if err == nil {
-		t.Error("runPrivilegedSecure() with empty command should fail")
-	}
-}
-
-func TestSecureExecutor_NilContext(t *testing.T) {
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      5 * time.Second,
-	}
-
-	// This should not panic but may fail
-	err := executor.runPrivilegedSecure(context.TODO(), "echo", "test")
-
-	// The function should handle nil context gracefully
-	t.Logf("runPrivilegedSecure with nil context: error = %v", err)
-}
-
-func TestSecureExecutor_LongRunningCommand(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping long-running test in short mode")
-	}
-
-	executor := &SecureExecutor{
-		privilegeCmd: "",
-		timeout:      2 * time.Second,
-	}
-
-	ctx := context.Background()
-	start := time.Now()
-
-	// Command that should be killed by timeout
-	err := executor.runPrivilegedSecure(ctx, "sleep", "5")
-
-	duration := time.Since(start)
-
-	if err == nil {
-		t.Error("Long running command should have been terminated")
-		return
-	}
-
-	// Should have been terminated around the timeout period
-	if duration > 3*time.Second {
-		t.Errorf("Command took %v, should have been terminated around %v", duration, executor.timeout)
-	}
-
-	if !strings.Contains(err.Error(), "timed out") {
-		t.Errorf("Error should indicate timeout: %v", err)
-	}
-}
-
-// Test interface compliance.
-func TestSecureExecutor_ImplementsExecutorInterface(t *testing.T) {
-	// Skip reboot-related calls in CI to prevent attempting actual reboot
-	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
-		t.Skip("Skipping interface test with reboot in CI environment")
-	}
-
-	var executor Executor = &SecureExecutor{}
-
-	// Should be able to call all Executor interface methods
-	_ = executor.DetectDistribution()
-
-	// These will likely fail in test environment, but should be callable
-	// Note: These may attempt real system operations, so they're expected to fail
-	_ = executor.RunCloudInit()
-	_ = executor.Reboot()
-	_ = executor.UpdateSystem()
-}
-
-// Test specific error paths in UpdateSystem that weren't covered.
-func TestSecureExecutor_UpdateSystem_ErrorPaths(t *testing.T) {
-	tests := []struct {
-		name         string
-		distribution Distribution
-		privilegeCmd string
-		timeout      time.Duration
-		expectError  bool
-	}{
-		{
-			name:         "Alpine with error on update",
-			distribution: DistroAlpine,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond, // Very short to cause timeout
-			expectError:  true,
-		},
-		{
-			name:         "Debian with error on update",
-			distribution: DistroDebian,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond, // Very short to cause timeout
-			expectError:  true,
-		},
-		{
-			name:         "Ubuntu with error on update",
-			distribution: DistroUbuntu,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond, // Very short to cause timeout
-			expectError:  true,
-		},
-		{
-			name:         "RHEL with error",
-			distribution: DistroRHEL,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond,
-			expectError:  true,
-		},
-		{
-			name:         "CentOS with error",
-			distribution: DistroCentOS,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond,
-			expectError:  true,
-		},
-		{
-			name:         "Fedora with error",
-			distribution: DistroFedora,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond,
-			expectError:  true,
-		},
-		{
-			name:         "Arch with error",
-			distribution: DistroArch,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond,
-			expectError:  true,
-		},
-		{
-			name:         "SUSE with error on refresh",
-			distribution: DistroSUSE,
-			privilegeCmd: "",
-			timeout:      1 * time.Millisecond,
-			expectError:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &mockSecureExecutor{
-				privilegeCmd:    tt.privilegeCmd,
-				timeout:         tt.timeout,
-				distribution:    tt.distribution,
-				shouldFailFirst: true, // Fail on first command
-			}
-
-			err := executor.UpdateSystem()
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("UpdateSystem() error = %v, expectError %v", err, tt.expectError)
-			}
-		})
-	}
-}
-
-// mockSecureExecutor for testing error paths.
-type mockSecureExecutor struct {
-	privilegeCmd    string
-	timeout         time.Duration
-	distribution    Distribution
-	shouldFailFirst bool
-	commandCount    int
-}
-
-func (m *mockSecureExecutor) runPrivilegedSecure(ctx context.Context, command string, args ...string) error {
-	m.commandCount++
-	if m.shouldFailFirst && m.commandCount == 1 {
-		return fmt.Errorf("mock error on first command: %s %v", command, args)
-	}
-	return fmt.Errorf("mock error: %s %v", command, args)
-}
-
-func (m *mockSecureExecutor) DetectDistribution() Distribution {
-	return m.distribution
-}
-
-func (m *mockSecureExecutor) UpdateSystem() error {
-	ctx := context.Background()
-	distro := m.DetectDistribution()
-
-	switch distro {
-	case DistroAlpine:
-		if err := m.runPrivilegedSecure(ctx, "apk", "update"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "apk", "upgrade", "--available")
-
-	case DistroDebian, DistroUbuntu:
-		if err := m.runPrivilegedSecure(ctx, "apt-get", "update"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "apt-get", "upgrade", "-y", "--with-new-pkgs",
-			"-o", "Dpkg::Options::=--force-confdef", "-o", "Dpkg::Options::=--force-confold")
-
-	case DistroRHEL, DistroCentOS, DistroFedora:
-		return m.runPrivilegedSecure(ctx, "dnf", "upgrade", "-y", "--refresh")
-
-	case DistroArch:
-		return m.runPrivilegedSecure(ctx, "pacman", "-Syu", "--noconfirm")
-
-	case DistroSUSE:
-		if err := m.runPrivilegedSecure(ctx, "zypper", "refresh"); err != nil {
-			return err
-		}
-		return m.runPrivilegedSecure(ctx, "zypper", "update", "-y")
-
-	default:
-		return fmt.Errorf("unsupported distribution: %s", distro)
-	}
-}
-
-func (m *mockSecureExecutor) RunCloudInit() error {
-	return fmt.Errorf("mock RunCloudInit not implemented")
-}
-
-func (m *mockSecureExecutor) Reboot() error {
-	return fmt.Errorf("mock Reboot not implemented")
-}
-
-// Test to cover missing UpdateSystem error paths in secure executor.
-func TestSecureExecutor_UpdateSystemErrorPaths_Additional(t *testing.T) {
-	tests := []struct {
-		name          string
-		distribution  Distribution
-		privilegeCmd  string
-		failOnCommand string
-		expectError   bool
-	}{
-		{
-			name:         "Alpine - fail on first command",
-			distribution: DistroAlpine,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "Debian - fail on upgrade",
-			distribution: DistroDebian,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "Ubuntu - fail on upgrade",
-			distribution: DistroUbuntu,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "RHEL - single command",
-			distribution: DistroRHEL,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "CentOS - single command",
-			distribution: DistroCentOS,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "Fedora - single command",
-			distribution: DistroFedora,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "Arch - single command",
-			distribution: DistroArch,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-		{
-			name:         "SUSE - fail on refresh",
-			distribution: DistroSUSE,
-			privilegeCmd: "",
-			expectError:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &testSecureExecutor{
-				privilegeCmd: tt.privilegeCmd,
-				timeout:      1 * time.Second,
-				distribution: tt.distribution,
-			}
-
-			err := executor.UpdateSystem()
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("UpdateSystem() error = %v, expectError %v", err, tt.expectError)
-			}
-
-			if err != nil {
-				t.Logf("UpdateSystem() failed as expected: %v", err)
-			}
-		})
-	}
-}
-
-// testSecureExecutor for testing specific distribution paths.
-type testSecureExecutor struct {
-	privilegeCmd string
-	timeout      time.Duration
-	distribution Distribution
-}
-
-func (t *testSecureExecutor) runPrivilegedSecure(ctx context.Context, command string, args ...string) error {
-	// Always fail to test error paths
-	return fmt.Errorf("test error for command: %s %v", command, args)
-}
-
-func (t *testSecureExecutor) DetectDistribution() Distribution {
-	return t.distribution
-}
-
-func (t *testSecureExecutor) UpdateSystem() error {
-	ctx := context.Background()
-	distro := t.DetectDistribution()
-
-	switch distro {
-	case DistroAlpine:
-		if err := t.runPrivilegedSecure(ctx, "apk", "update"); err != nil {
-			return err
-		}
-		return t.runPrivilegedSecure(ctx, "apk", "upgrade", "--available")
-
-	case DistroDebian, DistroUbuntu:
-		if err := t.runPrivilegedSecure(ctx, "apt-get", "update"); err != nil {
-			return err
-		}
-		return t.runPrivilegedSecure(ctx, "apt-get", "upgrade", "-y", "--with-new-pkgs",
-			"-o", "Dpkg::Options::=--force-confdef", "-o", "Dpkg::Options::=--force-confold")
-
-	case DistroRHEL, DistroCentOS, DistroFedora:
-		return t.runPrivilegedSecure(ctx, "dnf", "upgrade", "-y", "--refresh")
-
-	case DistroArch:
-		return t.runPrivilegedSecure(ctx, "pacman", "-Syu", "--noconfirm")
-
-	case DistroSUSE:
-		if err := t.runPrivilegedSecure(ctx, "zypper", "refresh"); err != nil {
-			return err
-		}
-		return t.runPrivilegedSecure(ctx, "zypper", "update", "-y")
-
-	default:
-		return fmt.Errorf("unsupported distribution: %s", distro)
-	}
-}
-
-func (t *testSecureExecutor) RunCloudInit() error {
-	return fmt.Errorf("test RunCloudInit not implemented")
-}
-
-func (t *testSecureExecutor) Reboot() error {
-	return fmt.Errorf("test Reboot not implemented")
-}
@@ -1,12 +1,24 @@
 package system
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/audit"
 )
 
 func TestDetectDistribution(t *testing.T) {
@@ -30,7 +42,6 @@ func TestDetectPrivilegeCommand(t *testing.T) {
 		"":     true, // No privilege command found
 		"doas": true,
 		"sudo": true,
-		"su":   true,
 	}
 
 	if !validCommands[cmd] {
@@ -38,6 +49,60 @@ func TestDetectPrivilegeCommand(t *testing.T) {
 	}
 }
 
+func TestDefaultExecutor_CheckPrivilege(t *testing.T) {
+	originalLookPath, originalGeteuid := lookPath, geteuid
+	defer func() { lookPath, geteuid = originalLookPath, originalGeteuid }()
+
+	tests := []struct {
+		name         string
+		privilege    PrivilegeMethod
+		privilegeCmd string
+		installed    bool
+		euid         int
+		wantErr      bool
+	}{
+		{"sudo installed", PrivilegeAuto, "sudo", true, 1000, false},
+		{"sudo removed", PrivilegeSudo, "sudo", false, 1000, true},
+		{"root without tool", PrivilegeAuto, "", false, 0, false},
+		{"user without tool", PrivilegeAuto, "", false, 1000, true},
+		{"privileges disabled", PrivilegeNone, "", false, 1000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath = func(file string) (string, error) {
+				if tt.installed {
+					return "/usr/bin/" + file, nil
+				}
+				return "", fmt.Errorf("%s not found", file)
+			}
+			geteuid = func() int { return tt.euid }
+
+			executor := &DefaultExecutor{policy: Policy{Privilege: tt.privilege}, privilegeCmd: tt.privilegeCmd}
+			if err := executor.CheckPrivilege(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPrivilege() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultExecutor_CheckPackageManager(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	executor := &DefaultExecutor{}
+
+	lookPath = func(file string) (string, error) { return "", fmt.Errorf("%s not found", file) }
+	if err := executor.CheckPackageManager(); err == nil {
+		t.Error("CheckPackageManager() should fail without package manager")
+	}
+
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	err := executor.CheckPackageManager()
+	if supported := executor.DetectDistribution() != DistroUnknown; (err == nil) != supported {
+		t.Errorf("CheckPackageManager() error = %v on %s", err, executor.DetectDistribution())
+	}
+}
+
 func TestDistributionConstants(t *testing.T) {
 	// Test that all distribution constants are defined
 	distros := []Distribution{
@@ -80,14 +145,14 @@ func TestUpdateSystemError(t *testing.T) {
 	}
 
 	// Test with unknown distribution
-	err := executor.UpdateSystem()
+	err := executor.UpdateSystem(context.Background(), nil)
 	if err == nil {
 		t.Skip("UpdateSystem() should fail for unknown distribution")
 	}
 }
 
-func TestDefaultExecutor_runPrivileged(t *testing.T) {
-	// Create a temporary directory and fake sudo script
+func TestDefaultExecutor_run(t *testing.T) {
+	// Create a temporary directory and a fake sudo script
 	tmpDir := t.TempDir()
 	fakeSudo := filepath.Join(tmpDir, "sudo")
 
@@ -137,12 +202,6 @@ exec "$@"
 			args:         []string{"echo", "test"},
 			expectError:  true, // Will fail in test environment
 		},
-		{
-			name:         "with su",
-			privilegeCmd: "su",
-			args:         []string{"echo", "test"},
-			expectError:  true, // Will fail in test environment
-		},
 		{
 			name:         "with unknown privilege command",
 			privilegeCmd: "unknown-privilege",
@@ -157,15 +216,102 @@ exec "$@"
 				privilegeCmd: tt.privilegeCmd,
 			}
 
-			err := executor.runPrivileged(tt.args...)
+			err := executor.run(context.Background(), nil, StepExecuteScript, "", tt.args...)
 
 			if (err != nil) != tt.expectError {
-				t.Errorf("runPrivileged() error = %v, expectError %v", err, tt.expectError)
+				t.Errorf("run() error = %v, expectError %v", err, tt.expectError)
 			}
 		})
 	}
 }
 
+func TestDefaultExecutor_command_Env(t *testing.T) {
+	for _, privilegeCmd := range []string{"", "sudo"} {
+		executor := &DefaultExecutor{privilegeCmd: privilegeCmd}
+		cmd := executor.command(context.Background(), []string{"apt-get", "update"})
+		if !slices.Contains(cmd.Env, "DEBIAN_FRONTEND=noninteractive") {
+			t.Errorf("command() with %q does not set DEBIAN_FRONTEND: %v", privilegeCmd, cmd.Env)
+		}
+	}
+}
+
+func TestDefaultExecutor_command_Args(t *testing.T) {
+	tests := []struct {
+		privilegeCmd string
+		want         []string
+	}{
+		{"", []string{"apt-get", "update"}},
+		{"sudo", []string{"sudo", "env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "update"}},
+		{"doas", []string{"doas", "env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "update"}},
+	}
+
+	for _, tt := range tests {
+		executor := &DefaultExecutor{privilegeCmd: tt.privilegeCmd}
+		cmd := executor.command(context.Background(), []string{"apt-get", "update"})
+		if !slices.Equal(cmd.Args, tt.want) {
+			t.Errorf("command() with %q runs %v, want %v", tt.privilegeCmd, cmd.Args, tt.want)
+		}
+	}
+}
+
+func TestDefaultExecutor_run_Span(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	executor := &DefaultExecutor{}
+	if err := executor.run(context.Background(), nil, StepExecuteScript, "", "sh", "-c", "exit 3"); err == nil {
+		t.Fatal("Expected the command to fail")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 || ended[0].Name() != "command execute_script" {
+		t.Fatalf("Expected one command span, got %v", ended)
+	}
+	attrs := attribute.NewSet(ended[0].Attributes()...)
+	if v, _ := attrs.Value("process.command_line"); v.AsString() != "sh -c exit 3" {
+		t.Errorf("process.command_line = %q", v.AsString())
+	}
+	if v, _ := attrs.Value("process.exit.code"); v.AsInt64() != 3 {
+		t.Errorf("process.exit.code = %d, want 3", v.AsInt64())
+	}
+	if ended[0].Status().Code != codes.Error {
+		t.Errorf("Expected a failed span, got %+v", ended[0].Status())
+	}
+}
+
+func TestDefaultExecutor_run_Audit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("audit.Open() error = %v", err)
+	}
+	audit.SetDefault(auditLog)
+	defer func() {
+		audit.SetDefault(nil)
+		_ = auditLog.Close()
+	}()
+
+	executor := &DefaultExecutor{}
+	err = executor.run(context.Background(), nil, StepExecuteScript, "", "sh", "-c", "echo captured-output; exit 3")
+	if CommandOutput(err) == "" {
+		t.Fatalf("Expected a failed command with output, got %v", err)
+	}
+
+	// The record keeps the exit code and error, not the output of the command
+	data, err := os.ReadFile(path) //nolint:gosec // Test file
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	var rec audit.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("invalid audit record %s: %v", data, err)
+	}
+	if rec.ExitCode == nil || *rec.ExitCode != 3 || rec.Error != "exit status 3" {
+		t.Errorf("audit record = %s, want exit code 3 and error without output", data)
+	}
+}
+
 func TestDefaultExecutor_RunCloudInit(t *testing.T) {
 	// Create a temporary directory and fake scripts
 	tmpDir := t.TempDir()
@@ -221,7 +367,7 @@ exec "$@"
 				privilegeCmd: tt.privilegeCmd,
 			}
 
-			err := executor.RunCloudInit()
+			err := executor.RunCloudInit(context.Background(), nil)
 
 			if (err != nil) != tt.expectError {
 				t.Errorf("RunCloudInit() error = %v, expectError %v", err, tt.expectError)
@@ -261,7 +407,7 @@ func TestDefaultExecutor_Reboot(t *testing.T) {
 
 			// We expect Reboot() to return an error in test environment
 			// as it will try to execute reboot command without privileges
-			err := executor.Reboot()
+			err := executor.Reboot(context.Background(), nil)
 			if (err != nil) != tt.expectError {
 				t.Errorf("Reboot() error = %v, expectError %v", err, tt.expectError)
 			}
@@ -381,7 +527,7 @@ func TestDefaultExecutor_UpdateSystemAllDistros(t *testing.T) {
 				distro: tt.distro, //nolint:govet // Field is used in DetectDistribution method
 			}
 
-			err := executor.UpdateSystem()
+			err := executor.UpdateSystem(context.Background(), nil)
 
 			if (err != nil) != tt.expectError {
 				t.Errorf("UpdateSystem() for %s error = %v, expectError %v", tt.distro, err, tt.expectError)
@@ -603,7 +749,7 @@ func TestDetectPrivilegeCommandNoCommands(t *testing.T) {
 	t.Logf("detectPrivilegeCommand() returned: %q", cmd)
 
 	// Should return empty string if no commands found, or a valid command
-	validCommands := []string{"", "doas", "sudo", "su"}
+	validCommands := []string{"", "doas", "sudo"}
 	found := false
 	for _, valid := range validCommands {
 		if cmd == valid {
@@ -671,7 +817,7 @@ func TestDefaultExecutor_UpdateSystemAllDistros_CoverMissing(t *testing.T) {
 				distro: tt.distro, //nolint:govet // Field is used in DetectDistribution method
 			}
 
-			err := executor.UpdateSystem()
+			err := executor.UpdateSystem(context.Background(), nil)
 
 			// All should fail in test environment, but they exercise the code paths
 			if err == nil {
@@ -837,7 +983,7 @@ func TestDetectPrivilegeCommand_EmptyReturn(t *testing.T) {
 
 	// The function should return one of the expected values
 	// If it returns "" that means no privilege commands were found
-	expectedValues := []string{"", "doas", "sudo", "su"}
+	expectedValues := []string{"", "doas", "sudo"}
 	found := false
 	for _, expected := range expectedValues {
 		if cmd == expected {
@@ -878,7 +1024,7 @@ func TestDefaultExecutor_UpdateSystem_AllDistros_Real(t *testing.T) {
 				distro:   tt.distro,
 			}
 
-			err := wrapper.UpdateSystem()
+			err := wrapper.UpdateSystem(context.Background(), nil)
 
 			// All will fail in test environment, but this exercises the code paths
 			if err == nil {
@@ -900,37 +1046,37 @@ func (w *distributionWrapper) DetectDistribution() Distribution {
 	return w.distro
 }
 
-func (w *distributionWrapper) UpdateSystem() error {
+func (w *distributionWrapper) UpdateSystem(ctx context.Context, out OutputSink) error {
 	// Copy the UpdateSystem logic but use our overridden DetectDistribution
 	distro := w.DetectDistribution()
 
 	switch distro {
 	case DistroAlpine:
-		if err := w.executor.runPrivileged("apk", "update"); err != nil {
+		if err := w.executor.run(ctx, out, StepUpdateSystem, w.distro, "apk", "update"); err != nil {
 			return err
 		}
-		return w.executor.runPrivileged("apk", "upgrade")
+		return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "apk", "upgrade")
 
 	case DistroDebian, DistroUbuntu:
-		if err := w.executor.runPrivileged("apt-get", "update"); err != nil {
+		if err := w.executor.run(ctx, out, StepUpdateSystem, w.distro, "apt-get", "update"); err != nil {
 			return err
 		}
-		return w.executor.runPrivileged("apt-get", "upgrade", "-y")
+		return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "apt-get", "upgrade", "-y")
 
 	case DistroRHEL, DistroCentOS, DistroFedora:
 		if _, err := exec.LookPath("dnf"); err == nil {
-			return w.executor.runPrivileged("dnf", "update", "-y")
+			return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "dnf", "update", "-y")
 		}
-		return w.executor.runPrivileged("yum", "update", "-y")
+		return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "yum", "update", "-y")
 
 	case DistroSUSE:
-		if err := w.executor.runPrivileged("zypper", "refresh"); err != nil {
+		if err := w.executor.run(ctx, out, StepUpdateSystem, w.distro, "zypper", "refresh"); err != nil {
 			return err
 		}
-		return w.executor.runPrivileged("zypper", "update", "-y")
+		return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "zypper", "update", "-y")
 
 	case DistroArch:
-		return w.executor.runPrivileged("pacman", "-Syu", "--noconfirm")
+		return w.executor.run(ctx, out, StepUpdateSystem, w.distro, "pacman", "-Syu", "--noconfirm")
 
 	default:
 		return fmt.Errorf("unsupported distribution: %s", distro)
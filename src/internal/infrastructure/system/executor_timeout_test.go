@@ -1,933 +0,0 @@
-package system
-
-import (
-	"context"
-	"fmt"
-	"os"
-	"strings"
-	"sync"
-	"testing"
-	"time"
-)
-
-func TestNewExecutorWithTimeout(t *testing.T) {
-	tests := []struct {
-		name            string
-		timeout         time.Duration
-		expectedTimeout time.Duration
-	}{
-		{
-			name:            "positive timeout",
-			timeout:         10 * time.Second,
-			expectedTimeout: 10 * time.Second,
-		},
-		{
-			name:            "zero timeout - should use default",
-			timeout:         0,
-			expectedTimeout: 5 * time.Minute,
-		},
-		{
-			name:            "negative timeout - should use default",
-			timeout:         -1 * time.Second,
-			expectedTimeout: 5 * time.Minute,
-		},
-		{
-			name:            "very small timeout",
-			timeout:         1 * time.Millisecond,
-			expectedTimeout: 1 * time.Millisecond,
-		},
-		{
-			name:            "very large timeout",
-			timeout:         24 * time.Hour,
-			expectedTimeout: 24 * time.Hour,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(tt.timeout)
-
-			if executor == nil {
-				t.Fatal("NewExecutorWithTimeout() returned nil")
-			}
-
-			if executor.defaultTimeout != tt.expectedTimeout {
-				t.Errorf("defaultTimeout = %v, want %v", executor.defaultTimeout, tt.expectedTimeout)
-			}
-
-			if executor.DefaultExecutor == nil {
-				t.Error("DefaultExecutor should not be nil")
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_RunCommandWithTimeout(t *testing.T) {
-	tests := []struct {
-		name          string
-		timeout       time.Duration
-		command       string
-		args          []string
-		expectError   bool
-		expectTimeout bool
-		errorContains string
-	}{
-		{
-			name:        "successful command with timeout",
-			timeout:     5 * time.Second,
-			command:     "echo",
-			args:        []string{"test"},
-			expectError: false,
-		},
-		{
-			name:        "successful command with zero timeout (uses default)",
-			timeout:     0,
-			command:     "echo",
-			args:        []string{"test"},
-			expectError: false,
-		},
-		{
-			name:        "nonexistent command",
-			timeout:     5 * time.Second,
-			command:     "nonexistent-command-12345",
-			args:        []string{},
-			expectError: true,
-		},
-		{
-			name:          "command timeout",
-			timeout:       100 * time.Millisecond,
-			command:       "sleep",
-			args:          []string{"1"}, // Sleep for 1 second
-			expectError:   true,
-			expectTimeout: true,
-			errorContains: "timed out",
-		},
-		{
-			name:        "command with multiple args",
-			timeout:     5 * time.Second,
-			command:     "echo",
-			args:        []string{"arg1", "arg2", "arg3"},
-			expectError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(5 * time.Second)
-			ctx := context.Background()
-
-			start := time.Now()
-			err := executor.RunCommandWithTimeout(ctx, tt.timeout, tt.command, tt.args...)
-			duration := time.Since(start)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("RunCommandWithTimeout() error = %v, expectError %v", err, tt.expectError)
-				return
-			}
-
-			if tt.expectTimeout {
-				// Should timeout around the specified timeout
-				expectedTimeout := tt.timeout
-				if tt.timeout <= 0 {
-					expectedTimeout = executor.defaultTimeout
-				}
-
-				// Allow some leeway for timeout detection
-				if duration > expectedTimeout+time.Second {
-					t.Errorf("Command took %v, should have timed out around %v", duration, expectedTimeout)
-				}
-
-				if err == nil {
-					t.Error("Expected timeout error but got none")
-					return
-				}
-			}
-
-			if tt.expectError && tt.errorContains != "" {
-				if !strings.Contains(err.Error(), tt.errorContains) {
-					t.Errorf("RunCommandWithTimeout() error = %v, want error containing %q", err, tt.errorContains)
-				}
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_RunCommandWithTimeout_ContextCancellation(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-
-	// Create a context that cancels quickly
-	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-	defer cancel()
-
-	start := time.Now()
-	err := executor.RunCommandWithTimeout(ctx, 10*time.Second, "sleep", "1")
-	duration := time.Since(start)
-
-	if err == nil {
-		t.Error("RunCommandWithTimeout() should fail when context is canceled")
-		return
-	}
-
-	// Should be canceled relatively quickly by the context timeout
-	if duration > 200*time.Millisecond {
-		t.Errorf("Command took %v, should have been canceled by context around 50ms", duration)
-	}
-
-	if !strings.Contains(err.Error(), "timed out") {
-		t.Errorf("RunCommandWithTimeout() error = %v, should indicate timeout", err)
-	}
-}
-
-func TestGetTimeoutForDistro(t *testing.T) {
-	tests := []struct {
-		name            string
-		distro          Distribution
-		expectedTimeout time.Duration
-	}{
-		{
-			name:            "Alpine Linux",
-			distro:          DistroAlpine,
-			expectedTimeout: 3 * time.Minute,
-		},
-		{
-			name:            "Debian",
-			distro:          DistroDebian,
-			expectedTimeout: 10 * time.Minute,
-		},
-		{
-			name:            "Ubuntu",
-			distro:          DistroUbuntu,
-			expectedTimeout: 10 * time.Minute,
-		},
-		{
-			name:            "RHEL",
-			distro:          DistroRHEL,
-			expectedTimeout: 10 * time.Minute,
-		},
-		{
-			name:            "CentOS",
-			distro:          DistroCentOS,
-			expectedTimeout: 10 * time.Minute,
-		},
-		{
-			name:            "Fedora",
-			distro:          DistroFedora,
-			expectedTimeout: 10 * time.Minute,
-		},
-		{
-			name:            "SUSE",
-			distro:          DistroSUSE,
-			expectedTimeout: 5 * time.Minute, // Default for unhandled case
-		},
-		{
-			name:            "Arch",
-			distro:          DistroArch,
-			expectedTimeout: 5 * time.Minute, // Default for unhandled case
-		},
-		{
-			name:            "Unknown",
-			distro:          DistroUnknown,
-			expectedTimeout: 5 * time.Minute,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			timeout := getTimeoutForDistro(tt.distro)
-			if timeout != tt.expectedTimeout {
-				t.Errorf("getTimeoutForDistro(%s) = %v, want %v", tt.distro, timeout, tt.expectedTimeout)
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_runUpdate(t *testing.T) {
-	// Skip this test in CI environment as it tries to run real system commands
-	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
-		t.Skip("Skipping test in CI environment - requires system command execution")
-	}
-
-	tests := []struct {
-		name        string
-		distro      Distribution
-		timeout     time.Duration
-		expectError bool
-		skipOnNoCmd bool
-	}{
-		{
-			name:        "Alpine update",
-			distro:      DistroAlpine,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-			skipOnNoCmd: true,
-		},
-		{
-			name:        "Debian update",
-			distro:      DistroDebian,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Ubuntu update",
-			distro:      DistroUbuntu,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "RHEL update",
-			distro:      DistroRHEL,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "CentOS update",
-			distro:      DistroCentOS,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Fedora update",
-			distro:      DistroFedora,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Arch update",
-			distro:      DistroArch,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "unsupported distribution",
-			distro:      DistroUnknown,
-			timeout:     1 * time.Second,
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Skip if required commands not available
-			if tt.skipOnNoCmd {
-				// In CI or test environments, skip tests that would require real commands
-				t.Skipf("Skipping test that requires real commands")
-			}
-
-			executor := NewExecutorWithTimeout(5 * time.Second)
-			ctx := context.Background()
-
-			err := executor.runUpdate(ctx, tt.distro, tt.timeout)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("runUpdate() error = %v, expectError %v", err, tt.expectError)
-			}
-
-			// For unsupported distribution, check specific error message
-			if tt.distro == DistroUnknown && err != nil {
-				expectedMsg := "unsupported distribution"
-				if !strings.Contains(err.Error(), expectedMsg) {
-					t.Errorf("runUpdate() error = %v, want error containing %q", err, expectedMsg)
-				}
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_runUpgrade(t *testing.T) {
-	// Skip this test in CI environment as it tries to run real system commands
-	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
-		t.Skip("Skipping test in CI environment - requires system command execution")
-	}
-
-	tests := []struct {
-		name        string
-		distro      Distribution
-		timeout     time.Duration
-		expectError bool
-	}{
-		{
-			name:        "Alpine upgrade",
-			distro:      DistroAlpine,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Debian upgrade",
-			distro:      DistroDebian,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Ubuntu upgrade",
-			distro:      DistroUbuntu,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "RHEL upgrade",
-			distro:      DistroRHEL,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "CentOS upgrade",
-			distro:      DistroCentOS,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Fedora upgrade",
-			distro:      DistroFedora,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "Arch upgrade",
-			distro:      DistroArch,
-			timeout:     1 * time.Second,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "unsupported distribution",
-			distro:      DistroUnknown,
-			timeout:     1 * time.Second,
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(5 * time.Second)
-			ctx := context.Background()
-
-			err := executor.runUpgrade(ctx, tt.distro, tt.timeout)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("runUpgrade() error = %v, expectError %v", err, tt.expectError)
-			}
-
-			// For unsupported distribution, check specific error message
-			if tt.distro == DistroUnknown && err != nil {
-				expectedMsg := "unsupported distribution"
-				if !strings.Contains(err.Error(), expectedMsg) {
-					t.Errorf("runUpgrade() error = %v, want error containing %q", err, expectedMsg)
-				}
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_UpdateSystemWithTimeout(t *testing.T) {
-	tests := []struct {
-		name        string
-		timeout     time.Duration
-		expectError bool
-	}{
-		{
-			name:        "default timeout",
-			timeout:     5 * time.Second,
-			expectError: true, // Will likely fail in test environment
-		},
-		{
-			name:        "short timeout",
-			timeout:     1 * time.Second,
-			expectError: true, // Will likely fail in test environment
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(tt.timeout)
-			ctx := context.Background()
-
-			err := executor.UpdateSystemWithTimeout(ctx)
-
-			// In test environment, this will likely fail
-			// We just want to make sure it doesn't panic and handles errors
-			t.Logf("UpdateSystemWithTimeout() error: %v", err)
-
-			// The function should always return either success or a reasonable error
-			if err != nil {
-				// Error is expected in test environment
-				t.Logf("Expected error in test environment: %v", err)
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_RebootWithDelay(t *testing.T) {
-	tests := []struct {
-		name        string
-		delay       time.Duration
-		expectError bool
-	}{
-		{
-			name:        "1 minute delay",
-			delay:       1 * time.Minute,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "zero delay",
-			delay:       0,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "negative delay",
-			delay:       -1 * time.Minute,
-			expectError: true, // Will fail in test environment
-		},
-		{
-			name:        "very long delay",
-			delay:       24 * time.Hour,
-			expectError: true, // Will fail in test environment
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(5 * time.Second)
-
-			err := executor.RebootWithDelay(tt.delay)
-
-			if (err != nil) != tt.expectError {
-				t.Errorf("RebootWithDelay() error = %v, expectError %v", err, tt.expectError)
-			}
-
-			// In test environment, should fail but with reasonable error
-			if err != nil {
-				t.Logf("Expected error in test environment: %v", err)
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_Concurrent(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-
-	const numGoroutines = 10
-	var wg sync.WaitGroup
-	results := make(chan error, numGoroutines)
-
-	ctx := context.Background()
-
-	// Run multiple commands concurrently
-	for i := 0; i < numGoroutines; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			err := executor.RunCommandWithTimeout(ctx, 2*time.Second, "echo", fmt.Sprintf("concurrent-%d", id))
-			results <- err
-		}(i)
-	}
-
-	wg.Wait()
-	close(results)
-
-	// Check results
-	successCount := 0
-	errorCount := 0
-	for err := range results {
-		if err != nil {
-			errorCount++
-			t.Logf("Concurrent execution error: %v", err)
-		} else {
-			successCount++
-		}
-	}
-
-	t.Logf("Concurrent executions: %d succeeded, %d failed", successCount, errorCount)
-
-	// At least some should succeed (echo command should work)
-	if successCount == 0 {
-		t.Error("All concurrent executions failed - this might indicate a problem")
-	}
-}
-
-func TestExecutorWithTimeout_runUpdate_Timeout(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping timeout test in short mode")
-	}
-
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	// Use a very short timeout to force timeout
-	timeout := 1 * time.Millisecond
-
-	// This should timeout quickly regardless of distribution
-	start := time.Now()
-	err := executor.runUpdate(ctx, DistroDebian, timeout)
-	duration := time.Since(start)
-
-	if err == nil {
-		t.Skip("Update command not available or completed too quickly")
-		return
-	}
-
-	// Should timeout relatively quickly
-	if duration > 100*time.Millisecond {
-		t.Errorf("runUpdate took %v, should have timed out much faster", duration)
-	}
-
-	if !strings.Contains(err.Error(), "timed out") && !strings.Contains(err.Error(), "executable file not found") {
-		t.Errorf("runUpdate error = %v, should indicate timeout or command not found", err)
-	}
-}
-
-func TestExecutorWithTimeout_runUpgrade_Timeout(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping timeout test in short mode")
-	}
-
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	// Use a very short timeout to force timeout
-	timeout := 1 * time.Millisecond
-
-	start := time.Now()
-	err := executor.runUpgrade(ctx, DistroDebian, timeout)
-	duration := time.Since(start)
-
-	if err == nil {
-		t.Skip("Upgrade command not available or completed too quickly")
-		return
-	}
-
-	// Should timeout relatively quickly
-	if duration > 100*time.Millisecond {
-		t.Errorf("runUpgrade took %v, should have timed out much faster", duration)
-	}
-
-	if !strings.Contains(err.Error(), "timed out") && !strings.Contains(err.Error(), "executable file not found") {
-		t.Errorf("runUpgrade error = %v, should indicate timeout or command not found", err)
-	}
-}
-
-func TestExecutorWithTimeout_YumExitCode100(t *testing.T) {
-	// Test the special handling of yum/dnf exit code 100 (updates available)
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	// We can't easily mock exec.ExitError, so this is more of a structure test
-	// The actual logic is tested through integration
-
-	// Test that the function exists and can be called
-	err := executor.runUpdate(ctx, DistroRHEL, 1*time.Second)
-
-	// Should either succeed, fail with timeout, or fail with command not found
-	// All are acceptable for this test
-	t.Logf("runUpdate for RHEL: %v", err)
-}
-
-// Benchmark tests.
-func BenchmarkExecutorWithTimeout_RunCommandWithTimeout(b *testing.B) {
-	executor := NewExecutorWithTimeout(30 * time.Second)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		executor.RunCommandWithTimeout(ctx, 10*time.Second, "echo", "benchmark")
-	}
-}
-
-func BenchmarkExecutorWithTimeout_RunCommandWithTimeout_Parallel(b *testing.B) {
-	executor := NewExecutorWithTimeout(30 * time.Second)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			executor.RunCommandWithTimeout(ctx, 10*time.Second, "echo", "benchmark")
-		}
-	})
-}
-
-func BenchmarkGetTimeoutForDistro(b *testing.B) {
-	distributions := []Distribution{
-		DistroAlpine, DistroDebian, DistroUbuntu, DistroRHEL,
-		DistroCentOS, DistroFedora, DistroSUSE, DistroArch, DistroUnknown,
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		distro := distributions[i%len(distributions)]
-		getTimeoutForDistro(distro)
-	}
-}
-
-// Test edge cases.
-func TestExecutorWithTimeout_NilContext(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-
-	// Should handle nil context gracefully
-	err := executor.RunCommandWithTimeout(context.TODO(), 1*time.Second, "echo", "test")
-
-	// May succeed or fail, but shouldn't panic
-	t.Logf("RunCommandWithTimeout with nil context: %v", err)
-}
-
-func TestExecutorWithTimeout_EmptyCommand(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	err := executor.RunCommandWithTimeout(ctx, 1*time.Second, "")
-
-	if err == nil {
-		t.Error("RunCommandWithTimeout with empty command should fail")
-	}
-}
-
-func TestExecutorWithTimeout_VeryLongTimeout(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	// Use a very long timeout to test that it doesn't cause issues
-	longTimeout := 365 * 24 * time.Hour // 1 year
-
-	err := executor.RunCommandWithTimeout(ctx, longTimeout, "echo", "test")
-
-	// Should succeed (or fail for other reasons, but not timeout-related)
-	if err != nil && strings.Contains(err.Error(), "timed out") {
-		t.Errorf("Command should not have timed out with 1 year timeout: %v", err)
-	}
-}
-
-func TestExecutorWithTimeout_RebootWithDelay_DelayCalculation(t *testing.T) {
-	tests := []struct {
-		name        string
-		delay       time.Duration
-		expectedArg string
-	}{
-		{
-			name:        "1 minute delay",
-			delay:       1 * time.Minute,
-			expectedArg: "+1",
-		},
-		{
-			name:        "2 minutes delay",
-			delay:       2 * time.Minute,
-			expectedArg: "+2",
-		},
-		{
-			name:        "30 seconds delay (rounds to 0 minutes)",
-			delay:       30 * time.Second,
-			expectedArg: "+0",
-		},
-		{
-			name:        "90 seconds delay (rounds to 1 minute)",
-			delay:       90 * time.Second,
-			expectedArg: "+1",
-		},
-		{
-			name:        "zero delay",
-			delay:       0,
-			expectedArg: "+0",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// We can't easily test the actual command execution,
-			// but we can verify the delay calculation logic
-			seconds := int(tt.delay.Seconds())
-			expectedMinutes := fmt.Sprintf("+%d", seconds/60)
-
-			if expectedMinutes != tt.expectedArg {
-				t.Errorf("Delay calculation: got %s, want %s", expectedMinutes, tt.expectedArg)
-			}
-		})
-	}
-}
-
-// Test specific error paths that weren't covered.
-func TestExecutorWithTimeout_UpdateSystemWithTimeout_Error(t *testing.T) {
-	// Test that UpdateSystemWithTimeout handles errors properly
-	executor := NewExecutorWithTimeout(1 * time.Second)
-	ctx := context.Background()
-
-	// This should fail because we're in a test environment
-	err := executor.UpdateSystemWithTimeout(ctx)
-
-	// Error is expected in test environment
-	if err != nil {
-		t.Logf("Expected error in test environment: %v", err)
-	} else {
-		t.Log("UpdateSystemWithTimeout succeeded (might be running on actual system)")
-	}
-}
-
-// Test the missing error return in runUpdate and runUpgrade.
-func TestExecutorWithTimeout_runUpdate_ErrorReturn(t *testing.T) {
-	executor := NewExecutorWithTimeout(1 * time.Second)
-	ctx := context.Background()
-
-	// Test with a very short timeout to force error
-	err := executor.runUpdate(ctx, DistroDebian, 1*time.Nanosecond)
-
-	if err == nil {
-		t.Log("runUpdate completed successfully or command not found")
-	} else {
-		t.Logf("runUpdate failed as expected: %v", err)
-	}
-}
-
-func TestExecutorWithTimeout_runUpgrade_ErrorReturn(t *testing.T) {
-	executor := NewExecutorWithTimeout(1 * time.Second)
-	ctx := context.Background()
-
-	// Test with a very short timeout to force error
-	err := executor.runUpgrade(ctx, DistroDebian, 1*time.Nanosecond)
-
-	if err == nil {
-		t.Log("runUpgrade completed successfully or command not found")
-	} else {
-		t.Logf("runUpgrade failed as expected: %v", err)
-	}
-}
-
-// Test RebootWithDelay timeout path.
-func TestExecutorWithTimeout_RebootWithDelay_Timeout(t *testing.T) {
-	// Create executor with very short timeout for the reboot command context
-	executor := NewExecutorWithTimeout(5 * time.Second)
-
-	// Test reboot scheduling
-	err := executor.RebootWithDelay(1 * time.Minute)
-
-	// This will fail in test environment but should handle timeout correctly
-	if err != nil {
-		if strings.Contains(err.Error(), "timed out") {
-			t.Log("RebootWithDelay timed out as expected")
-		} else {
-			t.Logf("RebootWithDelay failed (expected in test environment): %v", err)
-		}
-	} else {
-		t.Log("RebootWithDelay succeeded")
-	}
-}
-
-// Test to cover the UpdateSystemWithTimeout error paths that weren't covered.
-func TestExecutorWithTimeout_UpdateSystemWithTimeout_CoverMissing(t *testing.T) {
-	tests := []struct {
-		name    string
-		timeout time.Duration
-	}{
-		{"very short timeout", 1 * time.Nanosecond},
-		{"short timeout", 1 * time.Millisecond},
-		{"normal timeout", 5 * time.Second},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutorWithTimeout(tt.timeout)
-			ctx := context.Background()
-
-			err := executor.UpdateSystemWithTimeout(ctx)
-
-			// In test environment this should fail
-			if err == nil {
-				t.Log("UpdateSystemWithTimeout succeeded (might be on real system)")
-			} else {
-				t.Logf("UpdateSystemWithTimeout failed as expected: %v", err)
-			}
-		})
-	}
-}
-
-// Test specific error conditions in runUpdate and runUpgrade to cover uncovered returns.
-func TestExecutorWithTimeout_runUpdate_SpecificErrors(t *testing.T) {
-	executor := NewExecutorWithTimeout(100 * time.Millisecond)
-	ctx := context.Background()
-
-	// Test all distributions to cover switch branches
-	distributions := []Distribution{
-		DistroAlpine, DistroDebian, DistroUbuntu, DistroRHEL,
-		DistroCentOS, DistroFedora, DistroArch, DistroUnknown,
-	}
-
-	for _, distro := range distributions {
-		t.Run(string(distro), func(t *testing.T) {
-			err := executor.runUpdate(ctx, distro, 1*time.Millisecond)
-
-			if err == nil {
-				t.Logf("runUpdate for %s succeeded", distro)
-			} else {
-				t.Logf("runUpdate for %s failed as expected: %v", distro, err)
-			}
-		})
-	}
-}
-
-func TestExecutorWithTimeout_runUpgrade_SpecificErrors(t *testing.T) {
-	executor := NewExecutorWithTimeout(100 * time.Millisecond)
-	ctx := context.Background()
-
-	// Test all distributions to cover switch branches
-	distributions := []Distribution{
-		DistroAlpine, DistroDebian, DistroUbuntu, DistroRHEL,
-		DistroCentOS, DistroFedora, DistroArch, DistroUnknown,
-	}
-
-	for _, distro := range distributions {
-		t.Run(string(distro), func(t *testing.T) {
-			err := executor.runUpgrade(ctx, distro, 1*time.Millisecond)
-
-			if err == nil {
-				t.Logf("runUpgrade for %s succeeded", distro)
-			} else {
-				t.Logf("runUpgrade for %s failed as expected: %v", distro, err)
-			}
-		})
-	}
-}
-
-// Test the specific exit code 100 handling for yum/dnf in runUpdate.
-func TestExecutorWithTimeout_runUpdate_YumExitCode100_Mock(t *testing.T) {
-	executor := NewExecutorWithTimeout(5 * time.Second)
-	ctx := context.Background()
-
-	// Test RHEL and CentOS specifically for yum behavior
-	err := executor.runUpdate(ctx, DistroRHEL, 1*time.Second)
-	t.Logf("runUpdate for RHEL: %v", err)
-
-	err = executor.runUpdate(ctx, DistroCentOS, 1*time.Second)
-	t.Logf("runUpdate for CentOS: %v", err)
-}
-
-// Test RebootWithDelay with actual timeout context to cover timeout path.
-func TestExecutorWithTimeout_RebootWithDelay_ActualTimeout(t *testing.T) {
-	// Use a mock that always takes longer than context timeout
-	executor := &slowTimeoutExecutor{
-		ExecutorWithTimeout: NewExecutorWithTimeout(5 * time.Second),
-	}
-
-	err := executor.RebootWithDelay(1 * time.Minute)
-
-	if err == nil {
-		t.Log("RebootWithDelay succeeded")
-	} else {
-		if strings.Contains(err.Error(), "timed out") {
-			t.Log("RebootWithDelay timed out as expected")
-		} else {
-			t.Logf("RebootWithDelay failed: %v", err)
-		}
-	}
-}
-
-// slowTimeoutExecutor is a mock that helps test timeout conditions.
-type slowTimeoutExecutor struct {
-	*ExecutorWithTimeout
-}
-
-func (s *slowTimeoutExecutor) RebootWithDelay(delay time.Duration) error {
-	// Override with a very short timeout to force timeout condition
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
-	defer cancel()
-
-	// Simulate timeout immediately
-	<-ctx.Done()
-	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("reboot command timed out")
-	}
-	return fmt.Errorf("reboot scheduling failed: %w", ctx.Err())
-}
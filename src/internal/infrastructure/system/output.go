@@ -0,0 +1,128 @@
+// Package system provides system-level operations and command execution.
+package system
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stream identifies the output stream a command line was written to.
+type Stream string
+
+// Output streams of a command.
+const (
+	Stdout Stream = "stdout"
+	Stderr Stream = "stderr"
+)
+
+// OutputSink receives the output of system commands line by line, as it is written.
+// Calls are serialized, so a sink does not need its own locking.
+type OutputSink func(stream Stream, line string)
+
+const (
+	// maxLineLength bounds a streamed line; longer lines are split.
+	maxLineLength = 64 << 10
+	// maxCapturedOutput bounds the output kept for CommandError, the most recent lines being kept.
+	maxCapturedOutput = 64 << 10
+	// commandWaitDelay is how long a cancelled command may keep its output open once killed.
+	commandWaitDelay = 5 * time.Second
+)
+
+// commandOutput forwards the lines of a command to its sink and keeps the tail of the
+// combined output for error reporting.
+type commandOutput struct {
+	mu   sync.Mutex
+	sink OutputSink
+	tail []byte
+}
+
+func (o *commandOutput) line(stream Stream, data []byte) {
+	line := strings.TrimSuffix(string(data), "\r")
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.tail = append(o.tail, line...)
+	o.tail = append(o.tail, '\n')
+	if excess := len(o.tail) - maxCapturedOutput; excess > 0 {
+		o.tail = append(o.tail[:0], o.tail[excess:]...)
+	}
+	if o.sink != nil {
+		o.sink(stream, line)
+	}
+}
+
+func (o *commandOutput) bytes() []byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.tail
+}
+
+// lineWriter splits what a command writes to one stream into lines.
+type lineWriter struct {
+	stream Stream
+	out    *commandOutput
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	rest := w.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		w.out.line(w.stream, rest[:i])
+		rest = rest[i+1:]
+	}
+	for len(rest) >= maxLineLength {
+		w.out.line(w.stream, rest[:maxLineLength])
+		rest = rest[maxLineLength:]
+	}
+	w.buf = append(w.buf[:0], rest...)
+
+	return len(p), nil
+}
+
+// flush delivers the last line when the output does not end with a newline.
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.out.line(w.stream, w.buf)
+		w.buf = w.buf[:0]
+	}
+}
+
+// runCommand runs cmd, created with exec.CommandContext(ctx, ...), and streams its
+// output to sink. When ctx is done the command and its process group are killed
+// and the returned CommandError wraps ctx.Err().
+func runCommand(ctx context.Context, cmd *exec.Cmd, args []string, sink OutputSink) error {
+	out := &commandOutput{sink: sink}
+	stdout := &lineWriter{stream: Stdout, out: out}
+	stderr := &lineWriter{stream: Stderr, out: out}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = commandWaitDelay
+	kill := setProcessGroup(cmd)
+
+	err := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+	if ctx.Err() != nil {
+		// Leave nothing behind from a cancelled command
+		kill()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	return newCommandError(args, out.bytes(), err)
+}
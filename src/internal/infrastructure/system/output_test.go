@@ -0,0 +1,144 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordedLine struct {
+	stream Stream
+	line   string
+}
+
+func recordLines() (OutputSink, *[]recordedLine) {
+	var lines []recordedLine
+	return func(stream Stream, line string) {
+		lines = append(lines, recordedLine{stream, line})
+	}, &lines
+}
+
+func linesOf(lines []recordedLine, stream Stream) []string {
+	var out []string
+	for _, l := range lines {
+		if l.stream == stream {
+			out = append(out, l.line)
+		}
+	}
+	return out
+}
+
+func TestRunCommand_StreamsLines(t *testing.T) {
+	ctx := context.Background()
+	args := []string{"sh", "-c", "echo one; echo two >&2; printf 'three\\r\\n'; printf four"}
+	sink, lines := recordLines()
+
+	if err := runCommand(ctx, exec.CommandContext(ctx, args[0], args[1:]...), args, sink); err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+
+	if got := strings.Join(linesOf(*lines, Stdout), "|"); got != "one|three|four" {
+		t.Errorf("stdout lines = %q, want %q", got, "one|three|four")
+	}
+	if got := strings.Join(linesOf(*lines, Stderr), "|"); got != "two" {
+		t.Errorf("stderr lines = %q, want %q", got, "two")
+	}
+}
+
+func TestRunCommand_FailureKeepsOutput(t *testing.T) {
+	ctx := context.Background()
+	args := []string{"sh", "-c", "echo boom; exit 3"}
+
+	err := runCommand(ctx, exec.CommandContext(ctx, args[0], args[1:]...), args, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if code := ExitCode(err); code != 3 {
+		t.Errorf("ExitCode() = %d, want 3", code)
+	}
+	if out := CommandOutput(err); out != "boom\n" {
+		t.Errorf("CommandOutput() = %q, want %q", out, "boom\n")
+	}
+}
+
+func TestRunCommand_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	args := []string{"sh", "-c", "echo started; exec sleep 30"}
+	started := make(chan struct{})
+	sink := func(_ Stream, line string) {
+		if line == "started" {
+			close(started)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runCommand(ctx, exec.CommandContext(ctx, args[0], args[1:]...), args, sink)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command output was not streamed")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("runCommand() error = %v, want context.Canceled", err)
+		}
+		if out := CommandOutput(err); out != "started\n" {
+			t.Errorf("CommandOutput() = %q, want %q", out, "started\n")
+		}
+	case <-time.After(commandWaitDelay + 5*time.Second):
+		t.Fatal("command was not killed on cancellation")
+	}
+}
+
+func TestLineWriter_SplitsLongLines(t *testing.T) {
+	sink, lines := recordLines()
+	w := &lineWriter{stream: Stdout, out: &commandOutput{sink: sink}}
+
+	long := strings.Repeat("x", maxLineLength+10)
+	if _, err := w.Write([]byte(long)); err != nil {
+		t.Fatal(err)
+	}
+	w.flush()
+
+	got := linesOf(*lines, Stdout)
+	if len(got) != 2 || len(got[0]) != maxLineLength || len(got[1]) != 10 {
+		t.Errorf("got %d lines, want a %d-byte line and a 10-byte line", len(got), maxLineLength)
+	}
+}
+
+func TestCommandOutput_KeepsTail(t *testing.T) {
+	out := &commandOutput{}
+	line := []byte(strings.Repeat("y", 1023))
+	for i := 0; i < 2*maxCapturedOutput/1024; i++ {
+		out.line(Stdout, line)
+	}
+	out.line(Stderr, []byte("last"))
+
+	tail := string(out.bytes())
+	if len(tail) > maxCapturedOutput {
+		t.Errorf("captured %d bytes, want at most %d", len(tail), maxCapturedOutput)
+	}
+	if !strings.HasSuffix(tail, "last\n") {
+		t.Errorf("captured output does not end with the last line")
+	}
+}
+
+func TestDefaultExecutor_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executor := &DefaultExecutor{}
+	err := executor.run(ctx, nil, StepExecuteScript, "", "sleep", "30")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("run() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package system provides system-level operations and command execution.
+package system
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PrivilegeMethod selects how commands gain root privileges.
+type PrivilegeMethod string
+
+// Privilege escalation methods.
+const (
+	PrivilegeAuto PrivilegeMethod = "auto" // doas or sudo when installed, else none
+	PrivilegeNone PrivilegeMethod = "none" // The service already runs as root
+	PrivilegeDoas PrivilegeMethod = "doas"
+	PrivilegeSudo PrivilegeMethod = "sudo"
+)
+
+// RebootStrategy selects how reboots and shutdowns are performed.
+type RebootStrategy string
+
+// Reboot strategies.
+const (
+	RebootImmediate RebootStrategy = "immediate" // reboot / poweroff
+	RebootScheduled RebootStrategy = "scheduled" // shutdown +1, announced to logged-in users
+)
+
+// Step identifies an Executor operation, to give it its own timeout.
+type Step string
+
+// Executor operations. Their names match the job steps recording them.
+const (
+	StepCloudInit      Step = "cloud_init"
+	StepUpdateSystem   Step = "update_system"
+	StepUpgradeSystem  Step = "upgrade_system"
+	StepRestartService Step = "restart_service"
+	StepExecuteScript  Step = "execute_script"
+	StepPower          Step = "power"
+)
+
+// Steps lists the operations whose timeout can be configured.
+var Steps = []Step{
+	StepCloudInit, StepUpdateSystem, StepUpgradeSystem, StepRestartService, StepExecuteScript, StepPower,
+}
+
+// Distributions lists the supported distributions.
+var Distributions = []Distribution{
+	DistroAlpine, DistroDebian, DistroUbuntu, DistroRHEL, DistroCentOS, DistroFedora, DistroSUSE, DistroArch,
+}
+
+// DefaultCommandTimeout bounds each command unless the policy says otherwise.
+const DefaultCommandTimeout = 5 * time.Minute
+
+// Policy configures how the executor runs system commands.
+type Policy struct {
+	Privilege PrivilegeMethod
+	// Timeout bounds each command; zero disables it
+	Timeout time.Duration
+	// StepTimeouts override Timeout for the commands of an operation
+	StepTimeouts map[Step]time.Duration
+	// DistroTimeouts override the timeout of package manager commands per distribution
+	DistroTimeouts map[Distribution]time.Duration
+	Reboot         RebootStrategy
+}
+
+// DefaultPolicy returns the policy used when none is configured.
+func DefaultPolicy() Policy {
+	return Policy{
+		Privilege: PrivilegeAuto,
+		Timeout:   DefaultCommandTimeout,
+		Reboot:    RebootImmediate,
+	}
+}
+
+// Validate checks the policy settings.
+func (p Policy) Validate() error {
+	switch p.Privilege {
+	case PrivilegeAuto, PrivilegeNone, PrivilegeDoas, PrivilegeSudo:
+	default:
+		return fmt.Errorf("unknown privilege method %q", p.Privilege)
+	}
+	switch p.Reboot {
+	case RebootImmediate, RebootScheduled:
+	default:
+		return fmt.Errorf("unknown reboot strategy %q", p.Reboot)
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("negative timeout %v", p.Timeout)
+	}
+	for step, timeout := range p.StepTimeouts {
+		if !isStep(step) {
+			return fmt.Errorf("unknown step %q, expected one of %s", step, joinNames(Steps))
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout of %s must be positive", step)
+		}
+	}
+	for distro, timeout := range p.DistroTimeouts {
+		if !isDistribution(distro) {
+			return fmt.Errorf("unknown distribution %q, expected one of %s", distro, joinNames(Distributions))
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout of %s must be positive", distro)
+		}
+	}
+	return nil
+}
+
+// TimeoutFor returns the timeout of a command of step on distro. Package manager
+// commands use the distribution timeout, then the step timeout, then Timeout.
+func (p Policy) TimeoutFor(step Step, distro Distribution) time.Duration {
+	if step == StepUpdateSystem || step == StepUpgradeSystem {
+		if timeout, ok := p.DistroTimeouts[distro]; ok {
+			return timeout
+		}
+	}
+	if timeout, ok := p.StepTimeouts[step]; ok {
+		return timeout
+	}
+	return p.Timeout
+}
+
+func isStep(step Step) bool {
+	for _, s := range Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func isDistribution(distro Distribution) bool {
+	for _, d := range Distributions {
+		if d == distro {
+			return true
+		}
+	}
+	return false
+}
+
+func joinNames[T ~string](names []T) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = string(name)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,152 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *Policy)
+		wantErr string
+	}{
+		{"default", func(p *Policy) {}, ""},
+		{"no timeout", func(p *Policy) { p.Timeout = 0 }, ""},
+		{"step and distro timeouts", func(p *Policy) {
+			p.StepTimeouts = map[Step]time.Duration{StepUpgradeSystem: time.Hour}
+			p.DistroTimeouts = map[Distribution]time.Duration{DistroAlpine: time.Minute}
+		}, ""},
+		{"unknown privilege", func(p *Policy) { p.Privilege = "pkexec" }, "unknown privilege method"},
+		{"su", func(p *Policy) { p.Privilege = "su" }, "unknown privilege method"},
+		{"unknown reboot", func(p *Policy) { p.Reboot = "kexec" }, "unknown reboot strategy"},
+		{"negative timeout", func(p *Policy) { p.Timeout = -time.Second }, "negative timeout"},
+		{"unknown step", func(p *Policy) {
+			p.StepTimeouts = map[Step]time.Duration{"reinstall": time.Minute}
+		}, "unknown step"},
+		{"zero step timeout", func(p *Policy) {
+			p.StepTimeouts = map[Step]time.Duration{StepCloudInit: 0}
+		}, "must be positive"},
+		{"unknown distribution", func(p *Policy) {
+			p.DistroTimeouts = map[Distribution]time.Duration{"gentoo": time.Minute}
+		}, "unknown distribution"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultPolicy()
+			tt.modify(&policy)
+
+			err := policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_TimeoutFor(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.StepTimeouts = map[Step]time.Duration{StepUpgradeSystem: time.Hour, StepCloudInit: 10 * time.Minute}
+	policy.DistroTimeouts = map[Distribution]time.Duration{DistroAlpine: 3 * time.Minute}
+
+	tests := []struct {
+		step   Step
+		distro Distribution
+		want   time.Duration
+	}{
+		{StepCloudInit, "", 10 * time.Minute},
+		{StepRestartService, "", DefaultCommandTimeout},
+		{StepUpgradeSystem, DistroDebian, time.Hour},
+		{StepUpgradeSystem, DistroAlpine, 3 * time.Minute},
+		{StepUpdateSystem, DistroAlpine, 3 * time.Minute},
+		{StepUpdateSystem, DistroDebian, DefaultCommandTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := policy.TimeoutFor(tt.step, tt.distro); got != tt.want {
+			t.Errorf("TimeoutFor(%s, %s) = %v, want %v", tt.step, tt.distro, got, tt.want)
+		}
+	}
+}
+
+func TestNewExecutor(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	installed := map[string]bool{"sudo": true}
+	lookPath = func(file string) (string, error) {
+		if installed[file] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		privilege PrivilegeMethod
+		want      string
+		wantErr   bool
+	}{
+		{PrivilegeAuto, "sudo", false},
+		{PrivilegeNone, "", false},
+		{PrivilegeSudo, "sudo", false},
+		{PrivilegeDoas, "", true},
+		{"pkexec", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.privilege), func(t *testing.T) {
+			policy := DefaultPolicy()
+			policy.Privilege = tt.privilege
+
+			executor, err := NewExecutor(policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewExecutor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && executor.privilegeCmd != tt.want {
+				t.Errorf("privilege command = %q, want %q", executor.privilegeCmd, tt.want)
+			}
+		})
+	}
+
+	// Without doas or sudo, commands run directly
+	installed = map[string]bool{}
+	if executor, err := NewExecutor(DefaultPolicy()); err != nil || executor.privilegeCmd != "" {
+		t.Errorf("auto privilege = %q (%v), want none", executor.privilegeCmd, err)
+	}
+}
+
+func TestDefaultExecutor_StepTimeout(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Privilege = PrivilegeNone
+	policy.StepTimeouts = map[Step]time.Duration{StepExecuteScript: 100 * time.Millisecond}
+	executor, err := NewExecutor(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = executor.run(context.Background(), nil, StepExecuteScript, "", "sleep", "30")
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("run() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command ran for %v despite its timeout", elapsed)
+	}
+
+	// The caller's own deadline is not reported as a policy timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = executor.run(ctx, nil, StepCloudInit, "", "sleep", "30")
+	if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "timed out after") {
+		t.Errorf("run() error = %v, want the caller deadline", err)
+	}
+}
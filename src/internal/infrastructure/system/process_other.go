@@ -0,0 +1,11 @@
+//go:build !unix
+
+package system
+
+import "os/exec"
+
+// setProcessGroup is a no-op without process groups; cancelling a command only
+// kills the command itself.
+func setProcessGroup(_ *exec.Cmd) func() {
+	return func() {}
+}
@@ -0,0 +1,80 @@
+package system
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// process identifies a process. Its start time tells it apart from a later
+// process reusing its PID.
+type process struct {
+	pid   int
+	start string
+}
+
+// processDescendants returns the processes started by pid, directly or not.
+func processDescendants(pid int) []process {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	children := make(map[int][]process)
+	for _, entry := range entries {
+		id, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if stat, ok := readProcessStat(id); ok {
+			children[stat.ppid] = append(children[stat.ppid], process{pid: id, start: stat.start})
+		}
+	}
+
+	var found []process
+	for queue := []int{pid}; len(queue) > 0; queue = queue[1:] {
+		for _, child := range children[queue[0]] {
+			found = append(found, child)
+			queue = append(queue, child.pid)
+		}
+	}
+	return found
+}
+
+// running reports whether the process is still alive: not a zombie, nor another
+// process reusing its PID.
+func (p process) running() bool {
+	stat, ok := readProcessStat(p.pid)
+	return ok && stat.start == p.start && stat.state != "Z"
+}
+
+type processStat struct {
+	state string
+	ppid  int
+	start string
+}
+
+// readProcessStat parses /proc/<pid>/stat. The command name before the other
+// fields is in parentheses and may contain spaces.
+func readProcessStat(pid int) (processStat, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return processStat{}, false
+	}
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return processStat{}, false
+	}
+
+	// Fields from the state (3rd) on; the start time is the 22nd
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 20 {
+		return processStat{}, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return processStat{}, false
+	}
+	return processStat{state: fields[0], ppid: ppid, start: fields[19]}, true
+}
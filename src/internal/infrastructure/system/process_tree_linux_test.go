@@ -0,0 +1,93 @@
+package system
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProcessDescendants(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	var found []process
+	for deadline := time.Now().Add(5 * time.Second); len(found) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the sleep child was not found")
+		}
+		found = processDescendants(cmd.Process.Pid)
+		time.Sleep(10 * time.Millisecond)
+	}
+	child := found[0]
+	defer func() { _ = syscall.Kill(child.pid, syscall.SIGKILL) }()
+	if !child.running() {
+		t.Error("running() = false for a live process")
+	}
+
+	// Another process reusing the PID is not the same process
+	if (process{pid: child.pid, start: "0"}).running() {
+		t.Error("running() = true for a different start time")
+	}
+}
+
+func TestRunCommand_CancelKillsPrivilegedSession(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not installed")
+	}
+
+	// Like sudo with use_pty, the fake sudo runs the command in a session of its own,
+	// but does not relay signals to it
+	dir := t.TempDir()
+	sudo := filepath.Join(dir, "sudo")
+	if err := os.WriteFile(sudo, []byte("#!/bin/sh\nexec setsid -w \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	args := []string{sudo, "sh", "-c", "sleep 30 & echo $!; wait"}
+	pids := make(chan int, 1)
+	sink := func(_ Stream, line string) {
+		if pid, err := strconv.Atoi(line); err == nil {
+			pids <- pid
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runCommand(ctx, exec.CommandContext(ctx, args[0], args[1:]...), args, sink)
+	}()
+
+	var child int
+	select {
+	case child = <-pids:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not report its child")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(commandWaitDelay + 5*time.Second):
+		t.Fatal("command was not stopped on cancellation")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !processGone(child) {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(child, syscall.SIGKILL)
+			t.Fatalf("child process %d survived the cancellation", child)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix && !linux
+
+package system
+
+// process identifies a process.
+type process struct {
+	pid int
+}
+
+// processDescendants is not available without /proc; only the process group of
+// a cancelled command is killed.
+func processDescendants(_ int) []process {
+	return nil
+}
+
+func (process) running() bool {
+	return false
+}
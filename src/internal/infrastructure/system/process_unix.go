@@ -0,0 +1,79 @@
+//go:build unix
+
+package system
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// setProcessGroup runs cmd in its own process group, so that cancelling it stops
+// the processes it started too. The group receives SIGTERM first, which sudo and
+// doas relay to the privileged command. The returned function kills what is left
+// of a cancelled command once it returned.
+//
+// sudo with use_pty runs the command in a session of its own, out of reach of the
+// group. Its processes are therefore looked up when the command is cancelled and
+// killed through the privilege command, which needs to be allowed to run kill.
+// This lookup is only available on Linux.
+func setProcessGroup(cmd *exec.Cmd) func() {
+	var tracked []process
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// The command is not reaped yet, its PID still names it
+		tracked = processDescendants(cmd.Process.Pid)
+		return signalProcessGroup(cmd, syscall.SIGTERM)
+	}
+	return func() {
+		_ = signalProcessGroup(cmd, syscall.SIGKILL) //nolint:errcheck // The group may already be gone
+		killProcesses(cmd, tracked)
+	}
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return os.ErrProcessDone
+	}
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// killProcesses kills the processes still running, through the privilege command
+// when cmd ran with one, as they then belong to root.
+func killProcesses(cmd *exec.Cmd, processes []process) {
+	var pids []int
+	for _, p := range processes {
+		if p.running() {
+			pids = append(pids, p.pid)
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	switch filepath.Base(cmd.Args[0]) {
+	case "doas", "sudo":
+		args := []string{"kill", "-KILL"}
+		for _, pid := range pids {
+			args = append(args, strconv.Itoa(pid))
+		}
+		if err := exec.Command(cmd.Path, args...).Run(); err != nil { //nolint:gosec // privilege escalation tool
+			logger.WithField("pids", pids).
+				WithField("error", err).
+				Warn("Failed to kill the processes left by a cancelled command")
+		}
+	default:
+		for _, pid := range pids {
+			_ = syscall.Kill(pid, syscall.SIGKILL) //nolint:errcheck // The process may already be gone
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build unix
+
+package system
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processGone reports whether pid has exited, zombies included.
+func processGone(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return true
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) > 2 && fields[2] == "Z"
+}
+
+func TestRunCommand_CancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	args := []string{"sh", "-c", "sleep 30 & echo $!; wait"}
+	pids := make(chan int, 1)
+	sink := func(_ Stream, line string) {
+		if pid, err := strconv.Atoi(line); err == nil {
+			pids <- pid
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runCommand(ctx, exec.CommandContext(ctx, args[0], args[1:]...), args, sink)
+	}()
+
+	var child int
+	select {
+	case child = <-pids:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not report its child")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(commandWaitDelay + 5*time.Second):
+		t.Fatal("command was not stopped on cancellation")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !processGone(child) {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(child, syscall.SIGKILL)
+			t.Fatalf("child process %d survived the cancellation", child)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+// Package telemetry sets up OpenTelemetry distributed tracing: spans for HTTP
+// handling, authentication, jobs and system commands are exported to an
+// OTLP/HTTP collector, with W3C trace context propagation.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
+)
+
+// DefaultEndpoint is the OTLP/HTTP collector address used when none is configured.
+const DefaultEndpoint = "localhost:4318"
+
+// tracerName is the instrumentation scope of the spans started with Start.
+const tracerName = "github.com/kodflow/cloud-update"
+
+// Config holds telemetry configuration.
+type Config struct {
+	Enabled      bool   // Whether telemetry is enabled
+	ServiceName  string // Service name for traces
+	OTLPEndpoint string // OTLP/HTTP collector, host:port (plain HTTP) or http(s) URL
+	Environment  string // Environment (dev, staging, prod)
+	Version      string // Service version
+}
+
+// TracesURL returns the URL spans are posted to.
+func (c Config) TracesURL() (string, error) {
+	endpoint := c.OTLPEndpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if !strings.Contains(endpoint, "://") {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return "", fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+		}
+		endpoint = "http://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q, expected host:port or an http(s) URL", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	return u.String(), nil
+}
+
+// Telemetry owns the tracer provider spans are exported with.
+type Telemetry struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Initialize installs a global tracer provider exporting the spans to the
+// collector, and the W3C trace context propagator. It returns a disabled
+// Telemetry, leaving spans unrecorded, when cfg is not enabled.
+func Initialize(cfg Config) (*Telemetry, error) {
+	if !cfg.Enabled {
+		return &Telemetry{}, nil
+	}
+
+	tracesURL, err := cfg.TracesURL()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "cloud-update"
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(tracesURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.namespace", "cloud-update"),
+			attribute.String("service.version", cfg.Version),
+			attribute.String("deployment.environment", cfg.Environment),
+		)),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.WithField("error", err).Warn("OpenTelemetry error")
+	}))
+
+	logger.WithField("endpoint", tracesURL).Info("OpenTelemetry tracing initialized")
+	return &Telemetry{provider: provider}, nil
+}
+
+// Shutdown stops recording spans and sends the ones not exported yet.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	return t.provider.Shutdown(ctx)
+}
+
+// Start starts an internal span, child of the span or remote trace context in
+// ctx. The span records nothing until Initialize installed a tracer provider.
+// It must be ended with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,198 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is an OTLP/HTTP collector stub recording the requests it receives.
+type collector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	paths    []string
+}
+
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err != nil || r.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(body, req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		c.requests = append(c.requests, req)
+		c.paths = append(c.paths, r.URL.Path)
+		c.mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// spans returns the spans received, by name.
+func (c *collector) spans() map[string]*tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]*tracepb.Span)
+	for _, req := range c.requests {
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				for _, s := range ss.GetSpans() {
+					spans[s.GetName()] = s
+				}
+			}
+		}
+	}
+	return spans
+}
+
+// start enables telemetry exporting to a new collector until the test ends.
+func start(t *testing.T) (*collector, *Telemetry) {
+	t.Helper()
+	c := newCollector(t)
+	tel, err := Initialize(Config{
+		Enabled:      true,
+		ServiceName:  "cloud-update-test",
+		OTLPEndpoint: strings.TrimPrefix(c.URL, "http://"),
+		Environment:  "test",
+		Version:      "1.2.3",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+	return c, tel
+}
+
+func findAttribute(attrs []*commonpb.KeyValue, key string) *commonpb.AnyValue {
+	for _, a := range attrs {
+		if a.GetKey() == key {
+			return a.GetValue()
+		}
+	}
+	return nil
+}
+
+func TestInitialize_Disabled(t *testing.T) {
+	tel, err := Initialize(Config{})
+	require.NoError(t, err)
+
+	_, span := Start(context.Background(), "noop")
+	assert.False(t, span.IsRecording())
+	span.End()
+	assert.NoError(t, tel.Shutdown(context.Background()))
+}
+
+func TestInitialize_InvalidEndpoint(t *testing.T) {
+	_, err := Initialize(Config{Enabled: true, OTLPEndpoint: "no-port"})
+	assert.Error(t, err)
+	_, err = Initialize(Config{Enabled: true, OTLPEndpoint: "ftp://collector:4318"})
+	assert.Error(t, err)
+}
+
+func TestConfig_TracesURL(t *testing.T) {
+	tests := map[string]string{
+		"":                             "http://localhost:4318/v1/traces",
+		"collector:4318":               "http://collector:4318/v1/traces",
+		"https://collector.example":    "https://collector.example/v1/traces",
+		"http://collector:4318/custom": "http://collector:4318/custom",
+	}
+	for endpoint, want := range tests {
+		got, err := Config{OTLPEndpoint: endpoint}.TracesURL()
+		require.NoError(t, err, endpoint)
+		assert.Equal(t, want, got, endpoint)
+	}
+}
+
+func TestShutdown_ExportsSpans(t *testing.T) {
+	c, tel := start(t)
+
+	ctx, parent := Start(context.Background(), "parent", attribute.String("route", "/webhook"))
+	_, child := Start(ctx, "child", attribute.Int("exit", 3))
+	child.SetStatus(codes.Error, "exit status 3")
+	child.End()
+	parent.End()
+
+	require.NoError(t, tel.Shutdown(context.Background()))
+	_, after := Start(context.Background(), "after shutdown")
+	assert.False(t, after.IsRecording())
+
+	spans := c.spans()
+	require.Len(t, spans, 2)
+	p, ch := spans["parent"], spans["child"]
+	assert.Equal(t, parent.SpanContext().TraceID().String(), hex.EncodeToString(p.GetTraceId()))
+	assert.Equal(t, p.GetTraceId(), ch.GetTraceId())
+	assert.Equal(t, p.GetSpanId(), ch.GetParentSpanId())
+	assert.Equal(t, tracepb.Span_SPAN_KIND_INTERNAL, p.GetKind())
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, ch.GetStatus().GetCode())
+	assert.Equal(t, "exit status 3", ch.GetStatus().GetMessage())
+	assert.Equal(t, "/webhook", findAttribute(p.GetAttributes(), "route").GetStringValue())
+	assert.Equal(t, int64(3), findAttribute(ch.GetAttributes(), "exit").GetIntValue())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Equal(t, "/v1/traces", c.paths[0])
+	resource := c.requests[0].GetResourceSpans()[0].GetResource().GetAttributes()
+	assert.Equal(t, "cloud-update-test", findAttribute(resource, "service.name").GetStringValue())
+	assert.Equal(t, "1.2.3", findAttribute(resource, "service.version").GetStringValue())
+	assert.Equal(t, "test", findAttribute(resource, "deployment.environment").GetStringValue())
+}
+
+func TestShutdown_CollectorDown(t *testing.T) {
+	c, tel := start(t)
+	c.Close()
+
+	_, span := Start(context.Background(), "lost")
+	span.End()
+	// Export failures are reported to the error handler, not returned
+	_ = tel.Shutdown(context.Background())
+}
+
+func TestStart_RemoteParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		exported    bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"unsampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, tel := start(t)
+
+			ctx := otel.GetTextMapPropagator().Extract(context.Background(),
+				propagation.MapCarrier{"traceparent": tt.traceparent})
+			_, span := Start(ctx, "job")
+			span.End()
+			require.NoError(t, tel.Shutdown(context.Background()))
+
+			got, ok := c.spans()["job"]
+			require.Equal(t, tt.exported, ok)
+			if ok {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(got.GetTraceId()))
+				assert.Equal(t, "00f067aa0ba902b7", hex.EncodeToString(got.GetParentSpanId()))
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/logger"
@@ -14,20 +15,32 @@ import (
 // Task represents a unit of work.
 type Task func(context.Context)
 
+// DefaultTaskTimeout is how long a task may run before its context is cancelled.
+const DefaultTaskTimeout = 5 * time.Minute
+
 // Pool manages a pool of workers.
 type Pool struct {
-	workers    int
-	tasks      chan Task
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	maxBacklog int
-	shutdown   bool
-	mu         sync.RWMutex
+	workers     int
+	taskTimeout time.Duration
+	tasks       chan Task
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	maxBacklog  int
+	shutdown    bool
+	mu          sync.RWMutex // Held for reading while sending to tasks, which Shutdown closes
+	stopping    chan struct{}
+	stopOnce    sync.Once
+	busy        atomic.Int64 // Workers running a task
 }
 
 // NewPool creates a new worker pool.
 func NewPool(workers int, maxBacklog int) *Pool {
+	return NewPoolWithTimeout(workers, maxBacklog, DefaultTaskTimeout)
+}
+
+// NewPoolWithTimeout creates a new worker pool whose tasks run for at most taskTimeout.
+func NewPoolWithTimeout(workers int, maxBacklog int, taskTimeout time.Duration) *Pool {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	if workers <= 0 {
@@ -36,13 +49,18 @@ func NewPool(workers int, maxBacklog int) *Pool {
 	if maxBacklog <= 0 {
 		maxBacklog = 100 // Default to 100 task backlog (minimum 1)
 	}
+	if taskTimeout <= 0 {
+		taskTimeout = DefaultTaskTimeout
+	}
 
 	p := &Pool{
-		workers:    workers,
-		tasks:      make(chan Task, maxBacklog),
-		ctx:        ctx,
-		cancel:     cancel,
-		maxBacklog: maxBacklog,
+		workers:     workers,
+		taskTimeout: taskTimeout,
+		tasks:       make(chan Task, maxBacklog),
+		ctx:         ctx,
+		cancel:      cancel,
+		maxBacklog:  maxBacklog,
+		stopping:    make(chan struct{}),
 	}
 
 	// Start workers
@@ -69,6 +87,8 @@ func (p *Pool) worker(id int) {
 
 			// Execute task with timeout and panic recovery
 			func() {
+				p.busy.Add(1)
+				defer p.busy.Add(-1)
 				defer func() {
 					if r := recover(); r != nil {
 						logger.WithFields(map[string]interface{}{
@@ -79,7 +99,7 @@ func (p *Pool) worker(id int) {
 					}
 				}()
 
-				taskCtx, cancel := context.WithTimeout(p.ctx, 5*time.Minute)
+				taskCtx, cancel := context.WithTimeout(p.ctx, p.taskTimeout)
 				defer cancel()
 				task(taskCtx)
 			}()
@@ -90,11 +110,10 @@ func (p *Pool) worker(id int) {
 // Submit adds a task to the pool.
 func (p *Pool) Submit(task Task) error {
 	p.mu.RLock()
+	defer p.mu.RUnlock()
 	if p.shutdown {
-		p.mu.RUnlock()
 		return fmt.Errorf("pool is shutdown")
 	}
-	p.mu.RUnlock()
 
 	select {
 	case p.tasks <- task:
@@ -107,11 +126,10 @@ func (p *Pool) Submit(task Task) error {
 // SubmitWait adds a task to the pool and waits for space if full.
 func (p *Pool) SubmitWait(task Task, timeout time.Duration) error {
 	p.mu.RLock()
+	defer p.mu.RUnlock()
 	if p.shutdown {
-		p.mu.RUnlock()
 		return fmt.Errorf("pool is shutdown")
 	}
-	p.mu.RUnlock()
 
 	ctx, cancel := context.WithTimeout(p.ctx, timeout)
 	defer cancel()
@@ -119,6 +137,8 @@ func (p *Pool) SubmitWait(task Task, timeout time.Duration) error {
 	select {
 	case p.tasks <- task:
 		return nil
+	case <-p.stopping:
+		return fmt.Errorf("pool is shutdown")
 	case <-ctx.Done():
 		return ErrTimeout
 	}
@@ -126,17 +146,19 @@ func (p *Pool) SubmitWait(task Task, timeout time.Duration) error {
 
 // Shutdown gracefully shuts down the pool.
 func (p *Pool) Shutdown(timeout time.Duration) error {
-	// Mark as shutdown
+	// Wake up the submitters waiting for room, then mark as shutdown once no
+	// submitter is sending anymore
+	p.stopOnce.Do(func() { close(p.stopping) })
 	p.mu.Lock()
 	if p.shutdown {
 		p.mu.Unlock()
 		return nil
 	}
 	p.shutdown = true
-	p.mu.Unlock()
 
 	// Stop accepting new tasks
 	close(p.tasks)
+	p.mu.Unlock()
 
 	// Wait for workers to finish or timeout
 	done := make(chan struct{})
@@ -164,6 +186,35 @@ func (p *Pool) Capacity() int {
 	return p.maxBacklog
 }
 
+// Workers returns the number of workers.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Busy returns the number of workers running a task.
+func (p *Pool) Busy() int {
+	return int(p.busy.Load())
+}
+
+// IsShutdown reports whether the pool was shut down and accepts no more tasks.
+func (p *Pool) IsShutdown() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.shutdown
+}
+
+// Check reports whether the pool accepts new tasks: it must not be shut down and
+// its backlog must have room left.
+func (p *Pool) Check() error {
+	if p.IsShutdown() {
+		return fmt.Errorf("pool is shutdown")
+	}
+	if p.Size() >= p.maxBacklog {
+		return fmt.Errorf("%w: %d/%d workers busy, %d tasks waiting", ErrPoolFull, p.Busy(), p.workers, p.Size())
+	}
+	return nil
+}
+
 // Errors.
 var (
 	ErrPoolFull        = fmt.Errorf("worker pool is full")
@@ -72,6 +72,32 @@ func TestWorkerPool_QueueFull(t *testing.T) {
 	}
 }
 
+func TestWorkerPool_Check(t *testing.T) {
+	pool := NewPool(1, 1) // 1 worker, 1 task backlog
+	if err := pool.Check(); err != nil {
+		t.Errorf("Check() on an idle pool error = %v", err)
+	}
+
+	// Block the worker, then fill the backlog
+	release := make(chan struct{})
+	started := make(chan struct{})
+	_ = pool.Submit(func(_ context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	_ = pool.Submit(func(_ context.Context) {})
+	if err := pool.Check(); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("Check() on a saturated pool error = %v, want ErrPoolFull", err)
+	}
+
+	close(release)
+	_ = pool.Shutdown(5 * time.Second)
+	if err := pool.Check(); err == nil {
+		t.Error("Check() after Shutdown() should fail")
+	}
+}
+
 func TestWorkerPool_Shutdown(t *testing.T) {
 	pool := NewPool(2, 10)
 
@@ -255,6 +281,60 @@ func TestWorkerPool_SubmitWaitAfterShutdown(t *testing.T) {
 	}
 }
 
+// TestWorkerPool_SubmitDuringShutdown submits tasks from running tasks while the
+// pool shuts down: they are run or refused, never sent on the closed queue.
+func TestWorkerPool_SubmitDuringShutdown(t *testing.T) {
+	pool := NewPool(4, 8)
+	for i := 0; i < 4; i++ {
+		_ = pool.Submit(func(_ context.Context) {
+			for j := 0; j < 50; j++ {
+				time.Sleep(100 * time.Microsecond)
+				_ = pool.Submit(func(_ context.Context) {})
+			}
+		})
+	}
+	time.Sleep(time.Millisecond)
+
+	if err := pool.Shutdown(5 * time.Second); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !pool.IsShutdown() {
+		t.Error("IsShutdown() = false after Shutdown()")
+	}
+	if err := pool.Submit(func(_ context.Context) {}); err == nil {
+		t.Error("Submit() after Shutdown() should fail")
+	}
+}
+
+// TestWorkerPool_SubmitWaitWokenByShutdown tests that Shutdown does not wait for
+// the timeout of a SubmitWait blocked on a full queue.
+func TestWorkerPool_SubmitWaitWokenByShutdown(t *testing.T) {
+	pool := NewPool(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	_ = pool.Submit(func(_ context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	_ = pool.Submit(func(_ context.Context) {})
+
+	result := make(chan error, 1)
+	go func() { result <- pool.SubmitWait(func(_ context.Context) {}, time.Minute) }()
+	time.Sleep(20 * time.Millisecond)
+
+	go func() { _ = pool.Shutdown(time.Second) }()
+	select {
+	case err := <-result:
+		if err == nil || errors.Is(err, ErrTimeout) {
+			t.Errorf("SubmitWait() = %v, want the shutdown error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SubmitWait() still blocked after Shutdown()")
+	}
+	close(release)
+}
+
 // TestWorkerPool_SizeAndCapacity tests Size and Capacity functions.
 func TestWorkerPool_SizeAndCapacity(t *testing.T) {
 	pool := NewPool(2, 5) // 2 workers, 5 task backlog
@@ -292,6 +372,9 @@ func TestWorkerPool_SizeAndCapacity(t *testing.T) {
 	if pool.Size() != 3 {
 		t.Errorf("Expected size 3 after queuing tasks, got %d", pool.Size())
 	}
+	if pool.Workers() != 2 || pool.Busy() != 2 {
+		t.Errorf("Expected 2 busy workers out of 2, got %d out of %d", pool.Busy(), pool.Workers())
+	}
 
 	// Capacity should still be 5
 	if pool.Capacity() != 5 {
@@ -347,6 +430,38 @@ func TestWorkerPool_NewPoolDefaults(t *testing.T) {
 	}
 }
 
+func TestWorkerPool_TaskTimeout(t *testing.T) {
+	pool := NewPoolWithTimeout(1, 1, 50*time.Millisecond)
+	defer func() {
+		_ = pool.Shutdown(5 * time.Second)
+	}()
+
+	done := make(chan error, 1)
+	err := pool.Submit(func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+			done <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			done <- nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("task context error = %v, want deadline exceeded", err)
+	}
+
+	defaults := NewPoolWithTimeout(1, 1, 0)
+	defer func() {
+		_ = defaults.Shutdown(5 * time.Second)
+	}()
+	if defaults.taskTimeout != DefaultTaskTimeout {
+		t.Errorf("taskTimeout = %v, want %v", defaults.taskTimeout, DefaultTaskTimeout)
+	}
+}
+
 // TestWorkerPool_PanicRecovery tests panic recovery in worker.
 func TestWorkerPool_PanicRecovery(t *testing.T) {
 	pool := NewPool(1, 5)
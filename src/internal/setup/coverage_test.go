@@ -78,7 +78,7 @@ func TestServiceInstaller_Setup_FullSuccess(t *testing.T) {
 	}
 
 	// Verify config was created
-	configPath := ConfigDir + "/config.env"
+	configPath := ConfigDir + "/config.yaml"
 	if !fs.FileExists(configPath) {
 		t.Error("Config file was not created")
 	}
@@ -160,7 +160,7 @@ func TestServiceInstaller_createConfig_SecretGenerationFallback(t *testing.T) {
 	}
 
 	// Verify config was created
-	configPath := ConfigDir + "/config.env"
+	configPath := ConfigDir + "/config.yaml"
 	if !fs.FileExists(configPath) {
 		t.Error("Config file was not created")
 	}
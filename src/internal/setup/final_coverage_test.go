@@ -36,7 +36,7 @@ func TestServiceInstaller_Setup_FailurePaths(t *testing.T) {
 				osIface.SetExecutable("/test/cloud-update", nil)
 				fs.WriteFile("/test/cloud-update", []byte("binary content"), 0755)
 				// Make config creation fail
-				configPath := ConfigDir + "/config.env"
+				configPath := ConfigDir + "/config.yaml"
 				fs.SetShouldFail("WriteFile", configPath, fmt.Errorf("config write failed"))
 			},
 			expectError:   true,
@@ -106,25 +106,120 @@ func GetServiceCommand(initSystem InitSystem, action string) (string, []string,
 	}
 }
 
-// BuildConfigContent generates the configuration file content.
-func BuildConfigContent(secret string) string {
+// BuildConfigContent generates the configuration file content, with HTTPS enabled
+// when tlsEnabled is set.
+func BuildConfigContent(secret string, tlsEnabled bool) string {
 	return fmt.Sprintf(`# Cloud Update Configuration
 # Generated during installation
+# Every setting can be overridden by its CLOUD_UPDATE_* environment variable.
 
 # Server configuration
 server:
   host: "0.0.0.0"
   port: 9999
-  
+
 # Security
 security:
+  # Webhook authentication: hmac (shared secret), public_key (Ed25519 / ECDSA P-256)
+  # or mtls (TLS client certificates only)
+  auth: "hmac"
   webhook_secret: "%s"
-  
-# Logging
+  # public_keys:
+  #   - id: "deploy"
+  #     file: "/etc/cloud-update/keys/deploy.pub"
+  # Reject requests without a unique "nonce" field (replay protection)
+  require_nonce: true
+
+# Logging (level: debug, info, warn, error)
 logging:
   level: "info"
-  file: "/var/log/cloud-update.log"
-`, secret)
+  file: "/var/log/cloud-update/cloud-update.log"
+
+# HTTPS
+tls:
+  enabled: %t
+  cert_file: "/etc/cloud-update/tls/cert.pem"
+  key_file: "/etc/cloud-update/tls/key.pem"
+  # Automatic certificates from Let's Encrypt instead of cert_file/key_file:
+  # auto: true
+  # domain: "update.example.com"
+  # acme:
+  #   email: "ops@example.com"
+  #   challenge: "tls-alpn-01"   # or http-01 (listens on http_addr, default ":80")
+  # Client certificates (mTLS): none, optional or required
+  client_auth: "none"
+  # client_ca: "/etc/cloud-update/tls/client-ca.pem"
+  # client_allowed: ["deploy-*"]
+
+# Webhook rate limiting per client IP
+rate_limit:
+  requests_per_second: 10
+  burst: 20
+  ttl: "15m"
+
+# Worker pool processing actions
+workers:
+  count: 10
+  queue_size: 100
+  job_timeout: "5m"
+
+# System commands
+executor:
+  # Privilege escalation: auto (doas or sudo when installed), none, doas or sudo
+  privilege: "auto"
+  timeout: "5m"
+  # step_timeouts:      # cloud_init, update_system, upgrade_system, restart_service, execute_script, power
+  #   upgrade_system: "30m"
+  # distro_timeouts:    # update_system and upgrade_system per distribution
+  #   alpine: "3m"
+  # Reboots and shutdowns: immediate, or scheduled one minute later with shutdown
+  reboot: "immediate"
+
+# Actions accepted by the webhook ("all" enables every action)
+actions:
+  allowed:
+    - update
+
+# Job history and command output ("memory" disables persistence)
+jobs:
+  store: "/var/lib/cloud-update/jobs.log"
+  logs: "/var/lib/cloud-update/logs"
+  queue: 0   # jobs waiting for the running job (0 rejects them with 409)
+  retention: "168h"
+
+# Hash-chained audit log of requests, commands and job outcomes ("none" disables it)
+audit:
+  file: "/var/log/cloud-update/audit.log"
+
+# Maintenance windows ("[days] HH:MM-HH:MM") the restricted actions wait for
+maintenance:
+  # windows: ["sat,sun 02:00-05:00"]
+  timezone: "UTC"
+  actions: [reboot, shutdown, upgrade]
+
+# Prometheus metrics on /metrics, or on their own address with listen
+metrics:
+  enabled: false
+  # listen: "127.0.0.1:9100"
+
+# OpenTelemetry traces exported to an OTLP/HTTP collector
+telemetry:
+  enabled: false
+  endpoint: "localhost:4318"
+  service_name: "cloud-update"
+  environment: "production"
+
+# POST finished jobs to the callback_url of their request, or to url, signed with
+# secret (default: webhook_secret) and retried with exponential backoff.
+# Loopback, private and link-local addresses are refused unless listed in
+# allowed_networks.
+callbacks:
+  # url: "https://ci.example.com/hooks/cloud-update"
+  max_attempts: 5
+  timeout: "10s"
+  retry_delay: "10s"
+  # allowed_networks: ["10.0.0.0/8"]
+`, secret, tlsEnabled)
 }
 
 // GetBinaryPath returns the installation path for the binary.
@@ -132,6 +227,12 @@ func GetBinaryPath() string {
 	return filepath.Join(InstallDir, BinaryName)
 }
 
+// GetCertPaths returns the paths of the HTTPS certificate and private key.
+func GetCertPaths() (certPath, keyPath string) {
+	tlsDir := filepath.Join(ConfigDir, "tls")
+	return filepath.Join(tlsDir, "cert.pem"), filepath.Join(tlsDir, "key.pem")
+}
+
 // GetConfigPath returns the path for the configuration file.
 func GetConfigPath() string {
 	return filepath.Join(ConfigDir, "config.yaml")
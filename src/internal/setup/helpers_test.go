@@ -278,7 +278,7 @@ func TestGetServiceCommand(t *testing.T) {
 
 func TestBuildConfigContent(t *testing.T) {
 	secret := "test-secret-123"
-	content := BuildConfigContent(secret)
+	content := BuildConfigContent(secret, false)
 
 	// Check that content contains expected elements
 	expectedStrings := []string{
@@ -287,7 +287,8 @@ func TestBuildConfigContent(t *testing.T) {
 		"host: \"0.0.0.0\"",
 		"port: 9999",
 		"level: \"info\"",
-		"/var/log/cloud-update.log",
+		"/var/log/cloud-update/cloud-update.log",
+		"enabled: false",
 	}
 
 	for _, expected := range expectedStrings {
@@ -295,6 +296,10 @@ func TestBuildConfigContent(t *testing.T) {
 			t.Errorf("Config content missing %q", expected)
 		}
 	}
+
+	if content := BuildConfigContent(secret, true); !strings.Contains(content, "enabled: true") {
+		t.Error("Config content should enable TLS")
+	}
 }
 
 func TestGetBinaryPath(t *testing.T) {
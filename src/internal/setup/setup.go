@@ -7,7 +7,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/kodflow/cloud-update/src/internal/infrastructure/config"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/console"
 	"github.com/kodflow/cloud-update/src/internal/infrastructure/system"
 )
@@ -16,6 +18,7 @@ import (
 const (
 	InstallDir = "/opt/cloud-update"
 	ConfigDir  = "/etc/cloud-update"
+	DataDir    = "/var/lib/cloud-update"
 	BinaryName = "cloud-update"
 )
 
@@ -26,6 +29,13 @@ type ServiceInstaller struct {
 	fs         FileSystem
 	cmd        CommandRunner
 	os         OSInterface
+	cert       *certOptions // Self-signed certificate to generate, nil to skip
+}
+
+// certOptions describes the self-signed certificate generated during setup.
+type certOptions struct {
+	hosts    []string
+	validity time.Duration
 }
 
 // InitSystem represents the type of init system.
@@ -62,6 +72,13 @@ func NewServiceInstallerWithDeps(fs FileSystem, cmd CommandRunner, osIface OSInt
 	}
 }
 
+// WithSelfSignedCert makes Setup generate a self-signed certificate for hosts and
+// enable HTTPS in the configuration it creates.
+func (s *ServiceInstaller) WithSelfSignedCert(hosts []string, validity time.Duration) *ServiceInstaller {
+	s.cert = &certOptions{hosts: hosts, validity: validity}
+	return s
+}
+
 // Setup installs the service on the system.
 func (s *ServiceInstaller) Setup() error {
 	console.Println("🚀 Cloud Update Service Setup")
@@ -87,6 +104,13 @@ func (s *ServiceInstaller) Setup() error {
 		return fmt.Errorf("failed to install service: %w", err)
 	}
 
+	// Generate a self-signed certificate if requested
+	if s.cert != nil {
+		if err := s.createCertificate(); err != nil {
+			return fmt.Errorf("failed to create certificate: %w", err)
+		}
+	}
+
 	// Create config file
 	if err := s.createConfig(); err != nil {
 		return fmt.Errorf("failed to create config: %w", err)
@@ -112,6 +136,8 @@ func (s *ServiceInstaller) createDirectories() error {
 	}{
 		{InstallDir, 0o755},
 		{ConfigDir, 0o700},
+		{DataDir, 0o700},
+		{filepath.Join(ConfigDir, "scripts"), 0o700},
 	}
 
 	for _, dir := range dirs {
@@ -216,7 +242,7 @@ func (s *ServiceInstaller) installSysVInitService() error {
 func (s *ServiceInstaller) createConfig() error {
 	console.Println("⚙️  Creating configuration...")
 
-	configPath := filepath.Join(ConfigDir, "config.env")
+	configPath := GetConfigPath()
 
 	// Check if config already exists
 	if _, err := s.fs.Stat(configPath); err == nil {
@@ -230,18 +256,7 @@ func (s *ServiceInstaller) createConfig() error {
 		return fmt.Errorf("failed to generate secret: %w", err)
 	}
 
-	config := fmt.Sprintf(`# Cloud Update Configuration
-# Generated by setup command
-
-# Port on which the HTTP server will listen
-CLOUD_UPDATE_PORT=9999
-
-# Secret key for webhook signature validation (HMAC SHA256)
-CLOUD_UPDATE_SECRET=%s
-
-# Log level (debug, info, warn, error)
-CLOUD_UPDATE_LOG_LEVEL=info
-`, secret)
+	config := BuildConfigContent(secret, s.cert != nil)
 
 	if err := s.fs.WriteFile(configPath, []byte(config), 0o600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
@@ -259,6 +274,34 @@ CLOUD_UPDATE_LOG_LEVEL=info
 	return nil
 }
 
+func (s *ServiceInstaller) createCertificate() error {
+	console.Println("🔐 Generating self-signed certificate...")
+
+	certPath, keyPath := GetCertPaths()
+	if _, err := s.fs.Stat(certPath); err == nil {
+		fmt.Printf("  ⚠️  Certificate already exists at %s\n", certPath)
+		return nil
+	}
+
+	certPEM, keyPEM, err := config.NewSelfSignedCert(s.cert.hosts, s.cert.validity)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(certPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(certPath), err)
+	}
+	if err := s.fs.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := s.fs.WriteFile(certPath, certPEM, 0o644); err != nil { //nolint:gosec // certificates are public
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+
+	fmt.Printf("  ✓ Created certificate at %s for %s\n", certPath, strings.Join(s.cert.hosts, ", "))
+	fmt.Printf("     Clients must trust it, e.g. curl --cacert %s\n", certPath)
+	return nil
+}
+
 func (s *ServiceInstaller) enableService() error {
 	console.Println("🔌 Enabling service...")
 
@@ -291,7 +334,7 @@ func (s *ServiceInstaller) enableService() error {
 
 func (s *ServiceInstaller) printNextSteps() {
 	console.Println("\n📋 Next steps:")
-	fmt.Printf("1. Review configuration: %s\n", filepath.Join(ConfigDir, "config.env"))
+	fmt.Printf("1. Review configuration: %s\n", GetConfigPath())
 
 	switch s.initSystem {
 	case InitSystemd:
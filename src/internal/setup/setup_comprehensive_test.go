@@ -1,6 +1,7 @@
 package setup
 
 import (
+	"crypto/tls"
 	"errors"
 	"path/filepath"
 	"runtime"
@@ -104,7 +105,7 @@ func TestServiceInstaller_Setup_Comprehensive(t *testing.T) {
 				osIface.SetExecutable("/test/cloud-update", nil)
 				// Add the executable file to the mock filesystem
 				fs.AddFile("/test/cloud-update", []byte("mock binary content"))
-				configPath := filepath.Join(ConfigDir, "config.env")
+				configPath := filepath.Join(ConfigDir, "config.yaml")
 				fs.SetShouldFail("WriteFile", configPath, errors.New("config write error"))
 			},
 			expectError:   true,
@@ -453,7 +454,7 @@ func TestServiceInstaller_createConfig_Comprehensive(t *testing.T) {
 		{
 			name: "config already exists",
 			setupMocks: func(fs *MockFileSystem, cmd *MockCommandRunner) {
-				configPath := filepath.Join(ConfigDir, "config.env")
+				configPath := filepath.Join(ConfigDir, "config.yaml")
 				fs.WriteFile(configPath, []byte("existing config"), 0600)
 			},
 			expectError: false, // Should not error, just skip
@@ -470,7 +471,7 @@ func TestServiceInstaller_createConfig_Comprehensive(t *testing.T) {
 			name: "write config fails",
 			setupMocks: func(fs *MockFileSystem, cmd *MockCommandRunner) {
 				cmd.SetOutput("openssl", []byte("deadbeef1234567890abcdef"))
-				configPath := filepath.Join(ConfigDir, "config.env")
+				configPath := filepath.Join(ConfigDir, "config.yaml")
 				fs.SetShouldFail("WriteFile", configPath, errors.New("write error"))
 			},
 			expectError:   true,
@@ -480,7 +481,7 @@ func TestServiceInstaller_createConfig_Comprehensive(t *testing.T) {
 			name: "chown fails",
 			setupMocks: func(fs *MockFileSystem, cmd *MockCommandRunner) {
 				cmd.SetOutput("openssl", []byte("deadbeef1234567890abcdef"))
-				configPath := filepath.Join(ConfigDir, "config.env")
+				configPath := filepath.Join(ConfigDir, "config.yaml")
 				fs.SetShouldFail("Chown", configPath, errors.New("chown error"))
 			},
 			expectError:   true,
@@ -519,6 +520,92 @@ func TestServiceInstaller_createConfig_Comprehensive(t *testing.T) {
 	}
 }
 
+// Test createCertificate method.
+func TestServiceInstaller_createCertificate_Comprehensive(t *testing.T) {
+	certPath, keyPath := GetCertPaths()
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockFileSystem)
+		wantWritten   bool
+		errorContains string
+	}{
+		{
+			name:        "successful certificate creation",
+			setupMocks:  func(fs *MockFileSystem) {},
+			wantWritten: true,
+		},
+		{
+			name: "certificate already exists",
+			setupMocks: func(fs *MockFileSystem) {
+				fs.AddFile(certPath, []byte("existing certificate"))
+			},
+		},
+		{
+			name: "write key fails",
+			setupMocks: func(fs *MockFileSystem) {
+				fs.SetShouldFail("WriteFile", keyPath, errors.New("write error"))
+			},
+			errorContains: "failed to write key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewMockFileSystem()
+			tt.setupMocks(fs)
+
+			installer := NewServiceInstallerWithDeps(fs, NewMockCommandRunner(), NewMockOSInterface()).
+				WithSelfSignedCert([]string{"vm.example.com", "192.0.2.10"}, 0)
+			err := installer.createCertificate()
+
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if got := fs.FileExists(keyPath); got != tt.wantWritten {
+				t.Errorf("key written = %v, want %v", got, tt.wantWritten)
+			}
+			if tt.wantWritten {
+				certPEM, _ := fs.ReadFile(certPath)
+				keyPEM, _ := fs.ReadFile(keyPath)
+				if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+					t.Errorf("generated key pair is invalid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Test that Setup enables HTTPS when it generates a certificate.
+func TestServiceInstaller_Setup_SelfSignedCert(t *testing.T) {
+	fs := NewMockFileSystem()
+	cmd := NewMockCommandRunner()
+	osIface := NewMockOSInterface()
+	osIface.SetEuid(0)
+	osIface.SetExecutable("/test/cloud-update", nil)
+	fs.AddFile("/test/cloud-update", []byte("mock binary content"))
+
+	installer := NewServiceInstallerWithDeps(fs, cmd, osIface).WithSelfSignedCert([]string{"localhost"}, 0)
+	installer.initSystem = InitSystemd
+	if err := installer.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	certPath, _ := GetCertPaths()
+	if !fs.FileExists(certPath) {
+		t.Errorf("certificate not written to %s", certPath)
+	}
+	content, _ := fs.ReadFile(GetConfigPath())
+	if !strings.Contains(string(content), "enabled: true") {
+		t.Error("configuration should enable TLS")
+	}
+}
+
 // Test enableService method.
 func TestServiceInstaller_enableService_Comprehensive(t *testing.T) {
 	tests := []struct {
@@ -3,6 +3,7 @@ package e2e
 import (
 	"bytes"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -52,10 +53,18 @@ func (c *TestClient) generateSignature(body []byte) string {
 	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
+// newNonce returns a random request nonce, required by the server by default.
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func (c *TestClient) sendWebhook(action string) (*http.Response, error) {
 	payload := map[string]interface{}{
 		"action":    action,
 		"timestamp": time.Now().Unix(),
+		"nonce":     newNonce(),
 	}
 
 	body, err := json.Marshal(payload)
@@ -135,6 +144,7 @@ func TestWebhookAuthentication(t *testing.T) {
 			payload := map[string]interface{}{
 				"action":    "update",
 				"timestamp": time.Now().Unix(),
+				"nonce":     newNonce(),
 			}
 
 			body, _ := json.Marshal(payload)
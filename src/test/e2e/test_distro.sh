@@ -71,9 +71,10 @@ test_health_endpoint() {
 test_webhook_endpoint() {
     echo -e "${YELLOW}Testing webhook endpoint...${NC}"
     
-    # Include timestamp in payload
+    # Include timestamp and nonce in payload
     TIMESTAMP=$(date +%s)
-    PAYLOAD='{"action":"update","timestamp":'$TIMESTAMP'}'
+    NONCE="e2e-$TIMESTAMP-$RANDOM"
+    PAYLOAD='{"action":"update","timestamp":'$TIMESTAMP',"nonce":"'$NONCE'"}'
     SIGNATURE=$(echo -n "$PAYLOAD" | openssl dgst -sha256 -hmac "${E2E_SECRET:-test-secret-key-for-e2e-testing-purposes-only}" | cut -d' ' -f2)
     
     RESPONSE=$(curl -sf -X POST \